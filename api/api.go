@@ -0,0 +1,9 @@
+// Package api holds the OpenAPI document describing the HTTP API.
+package api
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI 3.1 document of every route the service registers.
+//
+//go:embed openapi.json
+var OpenAPI []byte
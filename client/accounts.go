@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type CreateAccountInput struct {
+	CustomerID *uuid.UUID      `json:"customer_id,omitempty"`
+	Balance    decimal.Decimal `json:"balance"`
+	Currency   string          `json:"currency"`
+}
+
+// UpdateAccountInput changes the fields that are set.
+type UpdateAccountInput struct {
+	CustomerID *uuid.UUID       `json:"customer_id,omitempty"`
+	Balance    *decimal.Decimal `json:"balance,omitempty"`
+	Currency   *string          `json:"currency,omitempty"`
+}
+
+func (c *Client) CreateAccount(ctx context.Context, input CreateAccountInput) (*Account, error) {
+	var out struct {
+		Account Account `json:"account"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/accounts", nil, input, &out); err != nil {
+		return nil, err
+	}
+	return &out.Account, nil
+}
+
+func (c *Client) GetAccount(ctx context.Context, id uuid.UUID) (*Account, error) {
+	var out struct {
+		Account Account `json:"account"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/accounts/"+id.String(), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out.Account, nil
+}
+
+// UpdateAccount changes the account if it is still at version, the Version
+// it was read with. Otherwise it fails with ErrPreconditionFailed, and the
+// account should be read again before retrying.
+func (c *Client) UpdateAccount(ctx context.Context, id uuid.UUID, version int, input UpdateAccountInput) (*Account, error) {
+	var out struct {
+		Account Account `json:"account"`
+	}
+	if err := c.doWithHeader(ctx, http.MethodPatch, "/accounts/"+id.String(), nil, ifMatch(version), input, &out); err != nil {
+		return nil, err
+	}
+	return &out.Account, nil
+}
+
+// DeleteAccount deletes the account if it is still at version, like
+// UpdateAccount.
+func (c *Client) DeleteAccount(ctx context.Context, id uuid.UUID, version int) error {
+	return c.doWithHeader(ctx, http.MethodDelete, "/accounts/"+id.String(), nil, ifMatch(version), nil, nil)
+}
+
+// ifMatch asks for a change to be made only to the given version of an
+// account, whose ETag is the quoted version.
+func ifMatch(version int) http.Header {
+	return http.Header{"If-Match": {strconv.Quote(strconv.Itoa(version))}}
+}
+
+// ListAccounts iterates over all accounts, ordered by id.
+func (c *Client) ListAccounts(ctx context.Context, opts *ListOptions) *Iterator[Account] {
+	return newIterator(ctx, opts, func(ctx context.Context, query url.Values) ([]Account, metadata, error) {
+		var out struct {
+			Accounts []Account `json:"accounts"`
+			Metadata metadata  `json:"metadata"`
+		}
+		err := c.do(ctx, http.MethodGet, "/accounts", query, nil, &out)
+		return out.Accounts, out.Metadata, err
+	})
+}
+
+// ListPockets returns the pockets of an account, oldest first.
+func (c *Client) ListPockets(ctx context.Context, accountID uuid.UUID) ([]Pocket, error) {
+	var out struct {
+		Pockets []Pocket `json:"pockets"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/accounts/"+accountID.String()+"/pockets", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Pockets, nil
+}
+
+// OpenPocket adds an empty pocket in the currency to an account.
+func (c *Client) OpenPocket(ctx context.Context, accountID uuid.UUID, currency string) (*Pocket, error) {
+	input := struct {
+		Currency string `json:"currency"`
+	}{currency}
+
+	var out struct {
+		Pocket Pocket `json:"pocket"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/accounts/"+accountID.String()+"/pockets", nil, input, &out); err != nil {
+		return nil, err
+	}
+	return &out.Pocket, nil
+}
+
+type ConvertInput struct {
+	FromCurrency string          `json:"from_currency"`
+	ToCurrency   string          `json:"to_currency"`
+	Amount       decimal.Decimal `json:"amount"`
+}
+
+// Convert moves money between two pockets of an account at the current
+// exchange rate.
+func (c *Client) Convert(ctx context.Context, accountID uuid.UUID, input ConvertInput) (*Conversion, error) {
+	var out struct {
+		Conversion Conversion `json:"conversion"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/accounts/"+accountID.String()+"/convert", nil, input, &out); err != nil {
+		return nil, err
+	}
+	return &out.Conversion, nil
+}
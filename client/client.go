@@ -0,0 +1,248 @@
+// Package client is a Go client for the Agile Transfer API.
+//
+//	c, err := client.New("http://localhost:8080", client.WithAPIKey(key))
+//	if err != nil {
+//		return err
+//	}
+//	result, err := c.CreateTransfer(ctx, client.TransferInput{
+//		SourceAccountID: source,
+//		TargetAccountID: target,
+//		Amount:          decimal.RequireFromString("150.00"),
+//	})
+//	if errors.Is(err, client.ErrInsufficientBalance) {
+//		...
+//	}
+//
+// Requests that fail on the way, or that the server is too busy or
+// unavailable to answer, are retried. GET, PUT and DELETE requests are safe to
+// repeat; POST requests are sent with an Idempotency-Key header, the same on
+// every attempt, so that the server processes them at most once. PATCH
+// requests are not retried.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// apiPrefix is the version of the API the client speaks.
+const apiPrefix = "/v1"
+
+type Client struct {
+	baseURL       *url.URL
+	apiKey        string
+	token         string
+	signingSecret string
+	httpClient    *http.Client
+	maxRetries    int
+	backoff       time.Duration
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sends the requests with hc instead of http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithAPIKey authenticates the requests with key, as issued by the API's
+// /api-keys endpoint.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithBearerToken authenticates the requests with a token from the identity
+// provider, for apps acting on behalf of a customer or back-office user.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithRetries retries a request up to max times, waiting backoff before the
+// first retry and twice as long before each one after it. The default is 3
+// retries starting at 100ms; max 0 turns retries off.
+func WithRetries(max int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = max
+		c.backoff = backoff
+	}
+}
+
+// New returns a client for the API served at baseURL, e.g.
+// "http://localhost:8080".
+func New(baseURL string, options ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("client: base URL %q is not absolute", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		maxRetries: 3,
+		backoff:    100 * time.Millisecond,
+	}
+	for _, option := range options {
+		option(c)
+	}
+
+	return c, nil
+}
+
+// do sends a request with the JSON encoding of body, if any, and decodes the
+// JSON response into out, retrying when it is safe to. Problems reported by
+// the API are returned as *Error.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	return c.doWithHeader(ctx, method, path, query, nil, body, out)
+}
+
+// doWithHeader is do with extra request headers.
+func (c *Client) doWithHeader(ctx context.Context, method, path string, query url.Values, header http.Header, body, out any) error {
+	data, err := c.send(ctx, method, path, query, header, body)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("client: decoding response to %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// send is doWithHeader without decoding the response body.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, header http.Header, body any) ([]byte, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	u := *c.baseURL
+	u.Path += apiPrefix + path
+	u.RawQuery = query.Encode()
+
+	retries := c.maxRetries
+	var key string
+	switch method {
+	case http.MethodPost:
+		key = uuid.NewString()
+	case http.MethodPatch:
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		// Ask for RFC 3339 timestamps everywhere, including the accounts
+		// version 1 gives human-readable creation times by default.
+		req.Header.Set("Accept", "application/json; timestamps=rfc3339")
+		if c.apiKey != "" {
+			req.Header.Set("X-API-Key", c.apiKey)
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		if c.signingSecret != "" {
+			Sign(req, payload, c.signingSecret, time.Now())
+		}
+
+		data, retryAfter, err := c.roundTrip(req)
+		if err == nil || attempt >= retries || !retryable(ctx, err) {
+			return data, err
+		}
+
+		wait := c.backoff << attempt
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// roundTrip sends req and reads the response, returning the delay the
+// server asked for in a Retry-After header.
+func (c *Client) roundTrip(req *http.Request) ([]byte, time.Duration, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if resp.StatusCode == http.StatusAccepted {
+		return nil, 0, pendingApproval(resp)
+	}
+	if resp.StatusCode < 400 {
+		return data, 0, nil
+	}
+
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return nil, retryAfter, newError(resp, data)
+}
+
+// retryable reports whether a request that failed with err may succeed if
+// sent again.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		// The request did not get an answer.
+		return true
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return apiErr.Code == "idempotency_key_in_use"
+}
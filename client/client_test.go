@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, WithRetries(3, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func writeProblem(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"type": "about:blank", "title": %q, "status": %d, "code": %q, "detail": "details"}`, http.StatusText(status), status, code)
+}
+
+func Test_New(t *testing.T) {
+	if _, err := New("localhost:8080"); err == nil {
+		t.Error("expected an error for a base URL without a scheme")
+	}
+
+	c, err := New("http://localhost:8080/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.baseURL.String() != "http://localhost:8080" {
+		t.Errorf("expected the trailing slash to be dropped but got %s", c.baseURL)
+	}
+}
+
+func Test_RetryPOST(t *testing.T) {
+	var keys []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) < 3 {
+			writeProblem(w, http.StatusServiceUnavailable, "")
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"account": {"id": "4f5a7a4c-25a0-4b0b-9c4b-8f1e6c9d2a10", "customer_id": null, "balance": "10", "currency": "EUR", "created_at": "2023-06-01T10:00:00Z"}}`)
+	})
+
+	account, err := c.CreateAccount(context.Background(), CreateAccountInput{Balance: decimal.NewFromInt(10), Currency: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Currency != "EUR" || !account.Balance.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("unexpected account %+v", account)
+	}
+
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 attempts but got %d", len(keys))
+	}
+	if keys[0] == "" || keys[0] != keys[1] || keys[1] != keys[2] {
+		t.Errorf("expected the same idempotency key on every attempt but got %q", keys)
+	}
+}
+
+func Test_RetryStops(t *testing.T) {
+	var tests = []struct {
+		name     string
+		status   int
+		code     string
+		call     func(c *Client) error
+		attempts int
+	}{
+		{"client errors", http.StatusNotFound, "not_found", func(c *Client) error {
+			_, err := c.GetAccount(context.Background(), uuid.New())
+			return err
+		}, 1},
+		{"server errors", http.StatusInternalServerError, "internal_error", func(c *Client) error {
+			_, err := c.GetAccount(context.Background(), uuid.New())
+			return err
+		}, 1},
+		{"PATCH", http.StatusServiceUnavailable, "", func(c *Client) error {
+			_, err := c.UpdateAccount(context.Background(), uuid.New(), 1, UpdateAccountInput{})
+			return err
+		}, 1},
+		{"after the last retry", http.StatusBadGateway, "", func(c *Client) error {
+			return c.DeleteAccount(context.Background(), uuid.New(), 1)
+		}, 4},
+		{"cancelled context", http.StatusServiceUnavailable, "", func(c *Client) error {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return c.DeleteAccount(ctx, uuid.New(), 1)
+		}, 0},
+	}
+
+	for _, e := range tests {
+		attempts := 0
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			writeProblem(w, e.status, e.code)
+		})
+
+		if err := e.call(c); err == nil {
+			t.Errorf("%s: expected an error", e.name)
+		}
+		if attempts != e.attempts {
+			t.Errorf("%s: expected %d attempts but got %d", e.name, e.attempts, attempts)
+		}
+	}
+}
+
+func Test_Error(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/transfer":
+			writeProblem(w, http.StatusUnprocessableEntity, "insufficient_balance")
+		case "/v1/customers":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"type": "about:blank", "title": "Unprocessable Entity", "status": 422, "code": "validation_failed", "errors": {"email": ["must be a valid email address"]}}`)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, "<html>bad gateway</html>\n")
+		}
+	})
+	c.maxRetries = 0
+
+	_, err := c.CreateTransfer(context.Background(), TransferInput{})
+	if !errors.Is(err, ErrInsufficientBalance) || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrInsufficientBalance but got %v", err)
+	}
+
+	_, err = c.CreateCustomer(context.Background(), CreateCustomerInput{Email: "jane"})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected a validation error but got %v", err)
+	}
+	if len(apiErr.Fields["email"]) != 1 {
+		t.Errorf("expected a problem with the email but got %v", apiErr.Fields)
+	}
+
+	_, err = c.ListPockets(context.Background(), uuid.New())
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway || apiErr.Detail != "<html>bad gateway</html>" {
+		t.Errorf("expected the body of a 502 as the detail but got %#v", err)
+	}
+	if errors.Is(err, &Error{}) {
+		t.Error("an error without a code should not match an empty code")
+	}
+}
+
+func Test_Iterator(t *testing.T) {
+	const total = 5
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+		if size != 2 {
+			t.Errorf("expected a page size of 2 but got %d", size)
+		}
+
+		fmt.Fprint(w, `{"customers": [`)
+		for i := (page-1)*size + 1; i <= page*size && i <= total; i++ {
+			if i > (page-1)*size+1 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"name": "customer %d"}`, i)
+		}
+		fmt.Fprintf(w, `], "metadata": {"current_page": %d, "page_size": 2, "first_page": 1, "last_page": 3, "total_records": %d}}`, page, total)
+	})
+
+	customers, err := c.ListCustomers(context.Background(), &ListOptions{PageSize: 2}).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(customers) != total {
+		t.Fatalf("expected %d customers but got %d", total, len(customers))
+	}
+	for i, customer := range customers {
+		if expected := fmt.Sprintf("customer %d", i+1); customer.Name != expected {
+			t.Errorf("expected %s but got %s", expected, customer.Name)
+		}
+	}
+}
+
+func Test_Timestamps(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept"); accept != "application/json; timestamps=rfc3339" {
+			t.Errorf("unexpected Accept header %q", accept)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"account": {"id": "4f5a7a4c-25a0-4b0b-9c4b-8f1e6c9d2a10", "balance": "1.50", "currency": "EUR", "created_at": "2023-06-01T10:30:00Z", "pockets": []}}`)
+	})
+
+	account, err := c.GetAccount(context.Background(), uuid.MustParse("4f5a7a4c-25a0-4b0b-9c4b-8f1e6c9d2a10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !account.CreatedAt.Equal(time.Date(2023, 6, 1, 10, 30, 0, 0, time.UTC)) || account.Balance.String() != "1.5" {
+		t.Errorf("unexpected account %+v", account)
+	}
+}
+
+// The vector is the one the server is tested with.
+func Test_Signature(t *testing.T) {
+	body := `{"source_account_id":"5b1f2a52-2f8e-4a0c-9d47-3c1a6a7a2f10","target_account_id":"0e2d6a1c-7f0b-4b8e-8c55-0f4b7d9e1a23","amount":"150.00"}`
+	signature := Signature("ags_pMW0rLAnWHE3Xaj1xvWvrqvMN3G8d1CHPuYx0jTSVnM", "POST", "/v1/transfer", 1792400000, "4f1c2a9e-6b3d-4c8a-9e7f-2d5b8a1c0e3f", []byte(body))
+	if signature != "ff1a01863bd103463b01d60f9879b1a24074d805c77df159f5808db58c50324f" {
+		t.Errorf("unexpected signature %s", signature)
+	}
+}
+
+func Test_SigningSecret(t *testing.T) {
+	const secret = "ags_secret"
+
+	var nonces []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get("X-Signature-Timestamp"), 10, 64)
+		nonce := r.Header.Get("X-Signature-Nonce")
+		nonces = append(nonces, nonce)
+
+		if r.Header.Get("X-Signature") != "v1="+Signature(secret, r.Method, r.URL.RequestURI(), timestamp, nonce, body) {
+			writeProblem(w, http.StatusUnauthorized, "invalid_signature")
+			return
+		}
+		if len(nonces) < 2 {
+			writeProblem(w, http.StatusServiceUnavailable, "")
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"account": {"id": "4f5a7a4c-25a0-4b0b-9c4b-8f1e6c9d2a10", "customer_id": null, "balance": "10", "currency": "EUR", "created_at": "2023-06-01T10:00:00Z"}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, WithRetries(3, time.Millisecond), WithSigningSecret(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.CreateAccount(context.Background(), CreateAccountInput{Balance: decimal.NewFromInt(10), Currency: "EUR"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(nonces) != 2 || nonces[0] == "" || nonces[0] == nonces[1] {
+		t.Errorf("expected every attempt to be signed with a new nonce but got %q", nonces)
+	}
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// CreateCustomerInput has a Type of individual or business. When
+// RestrictTransfers is set, the accounts of the customer can only send money
+// to each other or to its beneficiaries. Subject links the customer to its
+// user at the identity provider.
+type CreateCustomerInput struct {
+	Name              string  `json:"name"`
+	Email             string  `json:"email"`
+	Type              string  `json:"type"`
+	RestrictTransfers bool    `json:"restrict_transfers,omitempty"`
+	Subject           *string `json:"subject,omitempty"`
+}
+
+// UpdateCustomerInput changes the fields that are set. An empty Subject
+// unlinks the customer from its user.
+type UpdateCustomerInput struct {
+	Name              *string `json:"name,omitempty"`
+	Email             *string `json:"email,omitempty"`
+	Type              *string `json:"type,omitempty"`
+	Status            *string `json:"status,omitempty"`
+	RestrictTransfers *bool   `json:"restrict_transfers,omitempty"`
+	Subject           *string `json:"subject,omitempty"`
+}
+
+func (c *Client) CreateCustomer(ctx context.Context, input CreateCustomerInput) (*Customer, error) {
+	var out struct {
+		Customer Customer `json:"customer"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/customers", nil, input, &out); err != nil {
+		return nil, err
+	}
+	return &out.Customer, nil
+}
+
+func (c *Client) GetCustomer(ctx context.Context, id uuid.UUID) (*Customer, error) {
+	var out struct {
+		Customer Customer `json:"customer"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/customers/"+id.String(), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out.Customer, nil
+}
+
+func (c *Client) UpdateCustomer(ctx context.Context, id uuid.UUID, input UpdateCustomerInput) (*Customer, error) {
+	var out struct {
+		Customer Customer `json:"customer"`
+	}
+	if err := c.do(ctx, http.MethodPatch, "/customers/"+id.String(), nil, input, &out); err != nil {
+		return nil, err
+	}
+	return &out.Customer, nil
+}
+
+func (c *Client) DeleteCustomer(ctx context.Context, id uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, "/customers/"+id.String(), nil, nil, nil)
+}
+
+// ListCustomers iterates over all customers, oldest first.
+func (c *Client) ListCustomers(ctx context.Context, opts *ListOptions) *Iterator[Customer] {
+	return newIterator(ctx, opts, func(ctx context.Context, query url.Values) ([]Customer, metadata, error) {
+		var out struct {
+			Customers []Customer `json:"customers"`
+			Metadata  metadata   `json:"metadata"`
+		}
+		err := c.do(ctx, http.MethodGet, "/customers", query, nil, &out)
+		return out.Customers, out.Metadata, err
+	})
+}
+
+// ListCustomerAccounts returns the accounts of a customer along with the
+// total it holds in each currency.
+func (c *Client) ListCustomerAccounts(ctx context.Context, id uuid.UUID) ([]Account, []CurrencyBalance, error) {
+	var out struct {
+		Accounts []Account         `json:"accounts"`
+		Balances []CurrencyBalance `json:"balances"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/customers/"+id.String()+"/accounts", nil, nil, &out); err != nil {
+		return nil, nil, err
+	}
+	return out.Accounts, out.Balances, nil
+}
+
+func (c *Client) ListBeneficiaries(ctx context.Context, customerID uuid.UUID) ([]Beneficiary, error) {
+	var out struct {
+		Beneficiaries []Beneficiary `json:"beneficiaries"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/customers/"+customerID.String()+"/beneficiaries", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Beneficiaries, nil
+}
+
+// AddBeneficiary allows the accounts of a customer to send money to the
+// account.
+func (c *Client) AddBeneficiary(ctx context.Context, customerID, accountID uuid.UUID) (*Beneficiary, error) {
+	input := struct {
+		AccountID uuid.UUID `json:"account_id"`
+	}{accountID}
+
+	var out struct {
+		Beneficiary Beneficiary `json:"beneficiary"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/customers/"+customerID.String()+"/beneficiaries", nil, input, &out); err != nil {
+		return nil, err
+	}
+	return &out.Beneficiary, nil
+}
+
+func (c *Client) RemoveBeneficiary(ctx context.Context, customerID, accountID uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, "/customers/"+customerID.String()+"/beneficiaries/"+accountID.String(), nil, nil, nil)
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error is a problem reported by the API. Compare it with the errors below
+// using errors.Is, which matches on the code:
+//
+//	if errors.Is(err, client.ErrNotFound) {
+//		...
+//	}
+type Error struct {
+	StatusCode int
+	// Code is the stable name of the problem, see docs/errors.md.
+	Code     string
+	Title    string
+	Detail   string
+	Instance string
+	// Fields lists the problems per field when Code is validation_failed.
+	Fields map[string][]string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("agile-transfer: %d %s", e.StatusCode, e.Detail)
+	}
+	return fmt.Sprintf("agile-transfer: %s: %s", e.Code, e.Detail)
+}
+
+// Is reports whether target is an *Error with the same code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// The problems the API reports, by code.
+var (
+	ErrEmptyBody             = &Error{Code: "empty_body"}
+	ErrMalformedBody         = &Error{Code: "malformed_body"}
+	ErrBodyTooLarge          = &Error{Code: "body_too_large"}
+	ErrInvalidParameter      = &Error{Code: "invalid_parameter"}
+	ErrInvalidPeriod         = &Error{Code: "invalid_period"}
+	ErrInvalidIdempotencyKey = &Error{Code: "invalid_idempotency_key"}
+	ErrValidation            = &Error{Code: "validation_failed"}
+	ErrUnauthenticated       = &Error{Code: "unauthenticated"}
+	ErrSignatureRequired     = &Error{Code: "signature_required"}
+	ErrInvalidSignature      = &Error{Code: "invalid_signature"}
+	ErrStaleSignature        = &Error{Code: "stale_signature"}
+	ErrReplayedSignature     = &Error{Code: "replayed_signature"}
+	ErrCertificateMismatch   = &Error{Code: "certificate_mismatch"}
+	ErrInsufficientScope     = &Error{Code: "insufficient_scope"}
+	ErrPermissionDenied      = &Error{Code: "permission_denied"}
+	ErrAccountNotOwned       = &Error{Code: "account_not_owned"}
+	ErrNotFound              = &Error{Code: "not_found"}
+	ErrMethodNotAllowed      = &Error{Code: "method_not_allowed"}
+	ErrUnknownAccount        = &Error{Code: "unknown_account"}
+	ErrUnknownCustomer       = &Error{Code: "unknown_customer"}
+	ErrPocketExists          = &Error{Code: "pocket_exists"}
+	ErrCurrencyInUse         = &Error{Code: "currency_in_use"}
+	ErrDuplicateEmail        = &Error{Code: "duplicate_email"}
+	ErrDuplicateSubject      = &Error{Code: "duplicate_subject"}
+	ErrDuplicateRole         = &Error{Code: "duplicate_role"}
+	ErrCustomerHasAccounts   = &Error{Code: "customer_has_accounts"}
+	ErrIdempotencyKeyInUse   = &Error{Code: "idempotency_key_in_use"}
+	ErrIdempotencyKeyReused  = &Error{Code: "idempotency_key_reused"}
+	ErrPreconditionRequired  = &Error{Code: "precondition_required"}
+	ErrPreconditionFailed    = &Error{Code: "precondition_failed"}
+	ErrRateLimited           = &Error{Code: "rate_limited"}
+	ErrInsufficientBalance   = &Error{Code: "insufficient_balance"}
+	ErrIdenticalAccount      = &Error{Code: "identical_account"}
+	ErrInvalidAmount         = &Error{Code: "invalid_amount"}
+	ErrSameCurrency          = &Error{Code: "same_currency"}
+	ErrSelfApproval          = &Error{Code: "self_approval"}
+	ErrApprovalNotPending    = &Error{Code: "approval_not_pending"}
+	ErrTransferReversed      = &Error{Code: "transfer_reversed"}
+	ErrPocketNotFound        = &Error{Code: "pocket_not_found"}
+	ErrConversionFailed      = &Error{Code: "conversion_failed"}
+	ErrCustomerNotActive     = &Error{Code: "customer_not_active"}
+	ErrAccountFrozen         = &Error{Code: "account_frozen"}
+	ErrTargetNotAllowed      = &Error{Code: "target_not_allowed"}
+	ErrInvalidCustomer       = &Error{Code: "invalid_customer"}
+	ErrInvalidInterestConfig = &Error{Code: "invalid_interest_config"}
+	ErrCurrencyMismatch      = &Error{Code: "currency_mismatch"}
+	ErrUnknownCurrency       = &Error{Code: "unknown_currency"}
+	ErrCurrencyDisabled      = &Error{Code: "currency_disabled"}
+	ErrInvalidPrecision      = &Error{Code: "invalid_precision"}
+	ErrRateUnavailable       = &Error{Code: "currency_conversion_failed"}
+	ErrInternal              = &Error{Code: "internal_error"}
+)
+
+// ErrApprovalPending is returned, with a 202 status code, when the API holds
+// a transfer, balance adjustment or account closure until a second person
+// approves it. Instance is the path of the approval.
+var ErrApprovalPending = &Error{Code: "approval_pending"}
+
+func pendingApproval(resp *http.Response) *Error {
+	return &Error{
+		StatusCode: resp.StatusCode,
+		Code:       ErrApprovalPending.Code,
+		Title:      http.StatusText(resp.StatusCode),
+		Detail:     "the action is waiting for approval",
+		Instance:   resp.Header.Get("Location"),
+	}
+}
+
+// newError reads the problem in an error response. Responses that are not
+// problems, such as those of a proxy in front of the API, keep their body as
+// the detail.
+func newError(resp *http.Response, body []byte) *Error {
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		Title:      http.StatusText(resp.StatusCode),
+	}
+
+	var problem struct {
+		Title    string              `json:"title"`
+		Detail   string              `json:"detail"`
+		Instance string              `json:"instance"`
+		Code     string              `json:"code"`
+		Errors   map[string][]string `json:"errors"`
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/problem+json") && json.Unmarshal(body, &problem) == nil {
+		e.Code = problem.Code
+		e.Title = problem.Title
+		e.Detail = problem.Detail
+		e.Instance = problem.Instance
+		e.Fields = problem.Errors
+		return e
+	}
+
+	e.Detail = strings.TrimSpace(string(body))
+	return e
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// InterestConfigInput sets how an account earns interest. The conventions
+// that are left empty keep their defaults: ACT/365, simple compounding and
+// monthly posting.
+type InterestConfigInput struct {
+	AnnualRate       decimal.Decimal `json:"annual_rate"`
+	DayCount         string          `json:"day_count,omitempty"`
+	Compounding      string          `json:"compounding,omitempty"`
+	PostingFrequency string          `json:"posting_frequency,omitempty"`
+	Enabled          *bool           `json:"enabled,omitempty"`
+}
+
+func (c *Client) GetInterestConfig(ctx context.Context, accountID uuid.UUID) (*InterestConfig, error) {
+	var out struct {
+		Interest InterestConfig `json:"interest"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/accounts/"+accountID.String()+"/interest", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out.Interest, nil
+}
+
+func (c *Client) SetInterestConfig(ctx context.Context, accountID uuid.UUID, input InterestConfigInput) (*InterestConfig, error) {
+	var out struct {
+		Interest InterestConfig `json:"interest"`
+	}
+	if err := c.do(ctx, http.MethodPut, "/accounts/"+accountID.String()+"/interest", nil, input, &out); err != nil {
+		return nil, err
+	}
+	return &out.Interest, nil
+}
+
+// ListInterestAccruals returns the interest accrued on an account, oldest
+// first.
+func (c *Client) ListInterestAccruals(ctx context.Context, accountID uuid.UUID) ([]InterestAccrual, error) {
+	var out struct {
+		Accruals []InterestAccrual `json:"accruals"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/accounts/"+accountID.String()+"/interest/accruals", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Accruals, nil
+}
+
+// RunInterest accrues and posts the interest for date, or for yesterday when
+// date is zero. Running it again for the same day does nothing.
+func (c *Client) RunInterest(ctx context.Context, date time.Time) (*InterestRun, error) {
+	query := url.Values{}
+	if !date.IsZero() {
+		query.Set("date", date.Format(time.DateOnly))
+	}
+
+	var out struct {
+		Run InterestRun `json:"run"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/interest/run", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out.Run, nil
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// ListOptions sets how many items a list fetches per request. Zero uses the
+// largest page the API allows.
+type ListOptions struct {
+	PageSize int
+}
+
+// metadata describes the page of a list the API returned.
+type metadata struct {
+	CurrentPage int `json:"current_page"`
+	LastPage    int `json:"last_page"`
+}
+
+// Iterator walks a list one item at a time, fetching the next page when the
+// current one runs out:
+//
+//	it := c.ListAccounts(ctx, nil)
+//	for it.Next() {
+//		account := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, query url.Values) ([]T, metadata, error)
+	size  int
+
+	page  int
+	last  bool
+	items []T
+	value T
+	err   error
+}
+
+func newIterator[T any](ctx context.Context, opts *ListOptions, fetch func(context.Context, url.Values) ([]T, metadata, error)) *Iterator[T] {
+	it := &Iterator[T]{ctx: ctx, fetch: fetch}
+	if opts != nil {
+		it.size = opts.PageSize
+	}
+	return it
+}
+
+// Next moves to the next item, reporting false at the end of the list or
+// when a page could not be fetched.
+func (it *Iterator[T]) Next() bool {
+	for len(it.items) == 0 {
+		if it.last || it.err != nil {
+			return false
+		}
+
+		it.page++
+		query := url.Values{"page": {strconv.Itoa(it.page)}}
+		if it.size > 0 {
+			query.Set("page_size", strconv.Itoa(it.size))
+		}
+
+		var meta metadata
+		it.items, meta, it.err = it.fetch(it.ctx, query)
+		if it.err != nil {
+			return false
+		}
+		it.last = len(it.items) == 0 || meta.CurrentPage >= meta.LastPage
+	}
+
+	it.value, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Value is the item Next moved to.
+func (it *Iterator[T]) Value() T {
+	return it.value
+}
+
+// Err is the error that stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All reads the rest of the list.
+func (it *Iterator[T]) All() ([]T, error) {
+	var items []T
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+	return items, it.Err()
+}
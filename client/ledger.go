@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatementOptions selects the days, both inclusive, and the pocket of a
+// statement. The API defaults to the previous calendar month and the base
+// pocket.
+type StatementOptions struct {
+	From     time.Time
+	To       time.Time
+	Currency string
+}
+
+func (o *StatementOptions) query() url.Values {
+	query := url.Values{}
+	if o == nil {
+		return query
+	}
+	if !o.From.IsZero() {
+		query.Set("from", o.From.Format(time.DateOnly))
+	}
+	if !o.To.IsZero() {
+		query.Set("to", o.To.Format(time.DateOnly))
+	}
+	if o.Currency != "" {
+		query.Set("currency", o.Currency)
+	}
+	return query
+}
+
+func (c *Client) GetStatement(ctx context.Context, accountID uuid.UUID, opts *StatementOptions) (*Statement, error) {
+	var out struct {
+		Statement Statement `json:"statement"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/accounts/"+accountID.String()+"/statements", opts.query(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out.Statement, nil
+}
+
+// GetStatementDocument returns a statement as a CSV file or as printable
+// text, for a format of csv or text.
+func (c *Client) GetStatementDocument(ctx context.Context, accountID uuid.UUID, format string, opts *StatementOptions) ([]byte, error) {
+	query := opts.query()
+	query.Set("format", format)
+
+	return c.send(ctx, http.MethodGet, "/accounts/"+accountID.String()+"/statements", query, nil, nil)
+}
+
+// GetBalance returns the balance of an account pocket at asOf, or now when
+// asOf is zero. An empty currency selects the base pocket.
+func (c *Client) GetBalance(ctx context.Context, accountID uuid.UUID, currency string, asOf time.Time) (*Balance, error) {
+	query := url.Values{}
+	if currency != "" {
+		query.Set("currency", currency)
+	}
+	if !asOf.IsZero() {
+		query.Set("as_of", asOf.Format(time.RFC3339Nano))
+	}
+
+	var out struct {
+		Balance Balance `json:"balance"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/accounts/"+accountID.String()+"/balance", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out.Balance, nil
+}
+
+// GetBalances returns the balance of every account pocket at asOf, or now
+// when asOf is zero.
+func (c *Client) GetBalances(ctx context.Context, asOf time.Time) ([]Balance, error) {
+	query := url.Values{}
+	if !asOf.IsZero() {
+		query.Set("as_of", asOf.Format(time.RFC3339Nano))
+	}
+
+	var out struct {
+		Balances []Balance `json:"balances"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/balances", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Balances, nil
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// TransferInput moves Amount out of the SourceCurrency pocket of the source
+// account and credits its value to the TargetCurrency pocket of the target
+// account. The currencies default to the base pockets.
+type TransferInput struct {
+	SourceAccountID uuid.UUID       `json:"source_account_id"`
+	TargetAccountID uuid.UUID       `json:"target_account_id"`
+	Amount          decimal.Decimal `json:"amount"`
+	SourceCurrency  string          `json:"source_currency,omitempty"`
+	TargetCurrency  string          `json:"target_currency,omitempty"`
+}
+
+// CreateTransfer makes a transfer. It is retried with the same idempotency
+// key, so money moves at most once however many attempts it takes.
+func (c *Client) CreateTransfer(ctx context.Context, input TransferInput) (*TransferResult, error) {
+	var out struct {
+		Transaction TransferResult `json:"transaction"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/transfer", nil, input, &out); err != nil {
+		return nil, err
+	}
+	return &out.Transaction, nil
+}
+
+// ListTransfers iterates over all transfers, ordered by id.
+func (c *Client) ListTransfers(ctx context.Context, opts *ListOptions) *Iterator[Transfer] {
+	return newIterator(ctx, opts, func(ctx context.Context, query url.Values) ([]Transfer, metadata, error) {
+		var out struct {
+			Transfers []Transfer `json:"transfers"`
+			Metadata  metadata   `json:"metadata"`
+		}
+		err := c.do(ctx, http.MethodGet, "/transactions", query, nil, &out)
+		return out.Transfers, out.Metadata, err
+	})
+}
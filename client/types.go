@@ -0,0 +1,149 @@
+package client
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Money is an amount in a currency.
+type Money struct {
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// Pocket holds the money of an account in one currency.
+type Pocket struct {
+	Currency  string          `json:"currency"`
+	Balance   decimal.Decimal `json:"balance"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Account is a wallet of pockets. Balance and Currency are those of its base
+// pocket, the one it was created with.
+type Account struct {
+	ID         uuid.UUID       `json:"id"`
+	CustomerID *uuid.UUID      `json:"customer_id"`
+	Balance    decimal.Decimal `json:"balance"`
+	Currency   string          `json:"currency"`
+	// Frozen accounts neither send nor receive transfers.
+	Frozen    bool      `json:"frozen"`
+	CreatedAt time.Time `json:"created_at"`
+	Pockets   []Pocket  `json:"pockets"`
+	// Version goes up whenever the account changes. Updates and deletes
+	// only succeed at the version they name.
+	Version int `json:"version"`
+}
+
+type Transfer struct {
+	ID              uuid.UUID `json:"id"`
+	SourceAccountID uuid.UUID `json:"source_account_id"`
+	TargetAccountID uuid.UUID `json:"target_account_id"`
+	// Amount is what left the source pocket and Credited what reached the
+	// target pocket.
+	Amount    Money     `json:"amount"`
+	Credited  Money     `json:"credited"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TransferResult is a transfer along with both accounts after it.
+type TransferResult struct {
+	Transfer      Transfer `json:"transfer"`
+	SourceAccount Account  `json:"source_account"`
+	TargetAccount Account  `json:"target_account"`
+}
+
+// Conversion moves money between two pockets of an account.
+type Conversion struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Amount    Money     `json:"amount"`
+	Converted Money     `json:"converted"`
+	From      Pocket    `json:"from"`
+	To        Pocket    `json:"to"`
+}
+
+type Customer struct {
+	ID                uuid.UUID `json:"id"`
+	Name              string    `json:"name"`
+	Email             string    `json:"email"`
+	Type              string    `json:"type"`
+	Status            string    `json:"status"`
+	RestrictTransfers bool      `json:"restrict_transfers"`
+	Subject           *string   `json:"subject"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// CurrencyBalance is the total a customer holds in a currency.
+type CurrencyBalance struct {
+	Currency string          `json:"currency"`
+	Balance  decimal.Decimal `json:"balance"`
+	Accounts int             `json:"accounts"`
+}
+
+type Beneficiary struct {
+	CustomerID uuid.UUID `json:"customer_id"`
+	AccountID  uuid.UUID `json:"account_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type InterestConfig struct {
+	AccountID        uuid.UUID       `json:"account_id"`
+	AnnualRate       decimal.Decimal `json:"annual_rate"`
+	DayCount         string          `json:"day_count"`
+	Compounding      string          `json:"compounding"`
+	PostingFrequency string          `json:"posting_frequency"`
+	Enabled          bool            `json:"enabled"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+type InterestAccrual struct {
+	ID          uuid.UUID       `json:"id"`
+	AccountID   uuid.UUID       `json:"account_id"`
+	AccrualDate time.Time       `json:"accrual_date"`
+	Balance     decimal.Decimal `json:"balance"`
+	AnnualRate  decimal.Decimal `json:"annual_rate"`
+	Amount      decimal.Decimal `json:"amount"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// InterestRun is the outcome of running interest for a day.
+type InterestRun struct {
+	Date     time.Time `json:"date"`
+	Accrued  int       `json:"accrued"`
+	Posted   int       `json:"posted"`
+	Failures []string  `json:"failures"`
+}
+
+// Statement lists the changes to an account pocket between From and To.
+type Statement struct {
+	Account        Account         `json:"account"`
+	Currency       string          `json:"currency"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance decimal.Decimal `json:"opening_balance"`
+	ClosingBalance decimal.Decimal `json:"closing_balance"`
+	Lines          []StatementLine `json:"lines"`
+}
+
+type StatementLine struct {
+	ID             uuid.UUID       `json:"id"`
+	AccountID      uuid.UUID       `json:"account_id"`
+	Currency       string          `json:"currency"`
+	TransferID     *uuid.UUID      `json:"transfer_id"`
+	CounterpartyID *uuid.UUID      `json:"counterparty_id"`
+	Kind           string          `json:"kind"`
+	Amount         decimal.Decimal `json:"amount"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Balance        decimal.Decimal `json:"balance"`
+}
+
+// Balance is the balance of an account pocket at a point in time.
+type Balance struct {
+	AccountID uuid.UUID       `json:"account_id"`
+	Currency  string          `json:"currency"`
+	Balance   decimal.Decimal `json:"balance"`
+	AsOf      time.Time       `json:"as_of"`
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/client"
+	"github.com/shopspring/decimal"
+)
+
+// newClient serves handler and returns a client authenticated with adminKey,
+// unless the options give it a bearer token, which the server prefers.
+func newClient(t *testing.T, handler http.Handler, options ...client.Option) *client.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	options = append([]client.Option{client.WithAPIKey(adminKey), client.WithRetries(3, time.Millisecond)}, options...)
+	c, err := client.New(server.URL, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestClient(t *testing.T) {
+	useMemoryStore()
+	c := newClient(t, Routes())
+	ctx := context.Background()
+
+	subject := "jane"
+	customer, err := c.CreateCustomer(ctx, client.CreateCustomerInput{Name: "Jane Doe", Email: "jane@example.com", Type: "individual", Subject: &subject})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateCustomer(ctx, client.CreateCustomerInput{Name: "Jane Doe", Email: "jane@example.com", Type: "individual"}); !errors.Is(err, client.ErrDuplicateEmail) {
+		t.Errorf("expected ErrDuplicateEmail but got %v", err)
+	}
+
+	source, err := c.CreateAccount(ctx, client.CreateAccountInput{CustomerID: &customer.ID, Balance: decimal.NewFromInt(100), Currency: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var targets []uuid.UUID
+	for i := 0; i < 3; i++ {
+		target, err := c.CreateAccount(ctx, client.CreateAccountInput{Balance: decimal.Zero, Currency: "EUR"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, target.ID)
+	}
+
+	_, err = c.CreateAccount(ctx, client.CreateAccountInput{Balance: decimal.NewFromInt(-1), Currency: "EUR"})
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || !errors.Is(err, client.ErrValidation) || len(apiErr.Fields["balance"]) == 0 {
+		t.Errorf("expected a validation error for the balance but got %v", err)
+	}
+
+	got, err := c.GetAccount(ctx, source.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != source.ID || *got.CustomerID != customer.ID || !got.Balance.Equal(decimal.NewFromInt(100)) || got.CreatedAt.IsZero() {
+		t.Errorf("expected account %+v but got %+v", source, got)
+	}
+	if _, err := c.GetAccount(ctx, uuid.New()); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("expected ErrNotFound but got %v", err)
+	}
+
+	usd := "USD"
+	if _, err := c.UpdateAccount(ctx, source.ID, got.Version, client.UpdateAccountInput{Currency: &usd}); !errors.Is(err, client.ErrCurrencyInUse) {
+		t.Errorf("expected ErrCurrencyInUse changing the currency of a funded account but got %v", err)
+	}
+	empty, err := c.CreateAccount(ctx, client.CreateAccountInput{Balance: decimal.Zero, Currency: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := c.UpdateAccount(ctx, empty.ID, empty.Version, client.UpdateAccountInput{Currency: &usd})
+	if err != nil || changed.Currency != usd {
+		t.Fatalf("expected the currency of an unused account to change but got %+v (%v)", changed, err)
+	}
+	if err := c.DeleteAccount(ctx, empty.ID, changed.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range targets {
+		result, err := c.CreateTransfer(ctx, client.TransferInput{SourceAccountID: source.ID, TargetAccountID: target, Amount: decimal.NewFromInt(10)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.TargetAccount.Balance.Equal(decimal.NewFromInt(10)) || result.Transfer.Credited.Currency != "EUR" {
+			t.Errorf("unexpected transfer result %+v", result)
+		}
+	}
+	_, err = c.CreateTransfer(ctx, client.TransferInput{SourceAccountID: source.ID, TargetAccountID: targets[0], Amount: decimal.NewFromInt(1000)})
+	if !errors.Is(err, client.ErrInsufficientBalance) {
+		t.Errorf("expected ErrInsufficientBalance but got %v", err)
+	}
+
+	accounts, err := c.ListAccounts(ctx, &client.ListOptions{PageSize: 1}).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 4 {
+		t.Errorf("expected 4 accounts but got %d", len(accounts))
+	}
+
+	jane := newClient(t, Routes(), client.WithBearerToken(signToken(t, subject)))
+	if owned, err := jane.ListAccounts(ctx, nil).All(); err != nil || len(owned) != 1 || owned[0].ID != source.ID {
+		t.Errorf("expected jane to only see account %s but got %v (%v)", source.ID, owned, err)
+	}
+	if _, err := jane.GetAccount(ctx, targets[0]); !errors.Is(err, client.ErrAccountNotOwned) {
+		t.Errorf("expected ErrAccountNotOwned but got %v", err)
+	}
+	transfers, err := c.ListTransfers(ctx, &client.ListOptions{PageSize: 2}).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transfers) != 3 {
+		t.Errorf("expected 3 transfers but got %d", len(transfers))
+	}
+
+	balance, err := c.GetBalance(ctx, source.ID, "", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !balance.Balance.Equal(decimal.NewFromInt(70)) {
+		t.Errorf("expected a balance of 70 but got %s", balance.Balance)
+	}
+	today := time.Now().UTC()
+	statement, err := c.GetStatement(ctx, source.ID, &client.StatementOptions{From: today, To: today})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statement.Lines) != 4 || !statement.ClosingBalance.Equal(decimal.NewFromInt(70)) {
+		t.Errorf("unexpected statement %+v", statement)
+	}
+
+	if err := c.DeleteCustomer(ctx, customer.ID); !errors.Is(err, client.ErrCustomerHasAccounts) {
+		t.Errorf("expected ErrCustomerHasAccounts but got %v", err)
+	}
+	// The transfers have changed the account since it was read.
+	if err := c.DeleteAccount(ctx, source.ID, got.Version); !errors.Is(err, client.ErrPreconditionFailed) {
+		t.Errorf("expected ErrPreconditionFailed but got %v", err)
+	}
+	if got, err = c.GetAccount(ctx, source.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteAccount(ctx, source.ID, got.Version); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteCustomer(ctx, customer.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestClientRetriesTransfer loses the response to the first attempt at a
+// transfer after the server has made it. The client tries again with the same
+// idempotency key and must get the same transfer back instead of a second one.
+func TestClientRetriesTransfer(t *testing.T) {
+	useMemoryStore()
+	routes := Routes()
+
+	var lost atomic.Bool
+	c := newClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/transfer" && !lost.Load() {
+			lost.Store(true)
+			routes.ServeHTTP(httptest.NewRecorder(), r)
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		routes.ServeHTTP(w, r)
+	}))
+	ctx := context.Background()
+
+	source, err := c.CreateAccount(ctx, client.CreateAccountInput{Balance: decimal.NewFromInt(100), Currency: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := c.CreateAccount(ctx, client.CreateAccountInput{Balance: decimal.Zero, Currency: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := c.CreateTransfer(ctx, client.TransferInput{SourceAccountID: source.ID, TargetAccountID: target.ID, Amount: decimal.NewFromInt(25)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lost.Load() {
+		t.Fatal("the first attempt was not intercepted")
+	}
+	if !result.SourceAccount.Balance.Equal(decimal.NewFromInt(75)) {
+		t.Errorf("expected a source balance of 75 but got %s", result.SourceAccount.Balance)
+	}
+
+	transfers, err := c.ListTransfers(ctx, nil).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transfers) != 1 || transfers[0].ID != result.Transfer.ID {
+		t.Errorf("expected the transfer to be made once but got %+v", transfers)
+	}
+}
@@ -0,0 +1,43 @@
+// Command verify-audit checks the hash chain of the audit log in the
+// database the service uses. It prints the outcome and exits with status 1
+// when an entry has been changed or removed.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/config"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+)
+
+func main() {
+	logger := log.New(os.Stdout, "", log.Ldate|log.Ltime)
+
+	cfg, err := config.Load(config.FlagSet(os.Args[0]), os.Args[1:], os.Getenv)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	store, err := repository.NewPostgressRepository(cfg.Database)
+	if err != nil {
+		logger.Fatalf("cannot connect to the database: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	result, err := services.NewAuditService(store.AuditRepository).Verify(ctx)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	if !result.Intact() {
+		logger.Printf("audit log broken at entry %d: %s (%d entries checked)", *result.BrokenAt, result.Reason, result.Entries)
+		os.Exit(1)
+	}
+	logger.Printf("audit log intact: %d entries, %d recorded before chaining", result.Entries, result.Unsealed)
+}
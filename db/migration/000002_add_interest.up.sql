@@ -0,0 +1,43 @@
+CREATE TABLE "interest_configs" (
+  "account_id" uuid NOT NULL,
+  "annual_rate" decimal NOT NULL,
+  "day_count" varchar NOT NULL DEFAULT 'ACT/365',
+  "compounding" varchar NOT NULL DEFAULT 'simple',
+  "posting_frequency" varchar NOT NULL DEFAULT 'monthly',
+  "enabled" boolean NOT NULL DEFAULT true,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "updated_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id")
+);
+
+CREATE TABLE "interest_accruals" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "account_id" uuid NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" decimal NOT NULL,
+  "annual_rate" decimal NOT NULL,
+  "amount" decimal NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("id"),
+  UNIQUE ("account_id", "accrual_date")
+);
+
+CREATE TABLE "interest_postings" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "account_id" uuid NOT NULL,
+  "posting_date" date NOT NULL,
+  "accrued_amount" decimal NOT NULL,
+  "posted_amount" decimal NOT NULL,
+  "transfer_id" uuid,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("id"),
+  UNIQUE ("account_id", "posting_date")
+);
+
+ALTER TABLE "interest_configs" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
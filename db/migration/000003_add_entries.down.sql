@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS entries;
+ALTER TABLE transfers DROP COLUMN IF EXISTS created_at;
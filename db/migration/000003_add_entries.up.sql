@@ -0,0 +1,21 @@
+ALTER TABLE "transfers" ADD COLUMN "created_at" timestamp NOT NULL DEFAULT (now());
+
+CREATE TABLE "entries" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "seq" bigserial NOT NULL,
+  "account_id" uuid NOT NULL,
+  "transfer_id" uuid,
+  "kind" varchar NOT NULL,
+  "amount" decimal NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX ON "entries" ("account_id", "created_at", "seq");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+INSERT INTO "entries" ("account_id", "kind", "amount")
+SELECT "id", 'opening', "balance" FROM "accounts";
@@ -0,0 +1,9 @@
+CREATE TABLE "balance_snapshots" (
+  "account_id" uuid NOT NULL,
+  "taken_at" timestamp NOT NULL,
+  "balance" decimal NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "taken_at")
+);
+
+ALTER TABLE "balance_snapshots" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
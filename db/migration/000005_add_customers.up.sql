@@ -0,0 +1,28 @@
+CREATE TABLE "customers" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "name" varchar NOT NULL,
+  "email" varchar NOT NULL UNIQUE,
+  "type" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "restrict_transfers" boolean NOT NULL DEFAULT false,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "updated_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("id")
+);
+
+CREATE TABLE "beneficiaries" (
+  "customer_id" uuid NOT NULL,
+  "account_id" uuid NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("customer_id", "account_id")
+);
+
+ALTER TABLE "accounts" ADD COLUMN "customer_id" uuid;
+
+CREATE INDEX ON "accounts" ("customer_id");
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("customer_id") REFERENCES "customers" ("id");
+
+ALTER TABLE "beneficiaries" ADD FOREIGN KEY ("customer_id") REFERENCES "customers" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "beneficiaries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
@@ -0,0 +1,7 @@
+DELETE FROM balance_snapshots s USING accounts a WHERE a.id = s.account_id AND a.currency <> s.currency;
+ALTER TABLE balance_snapshots DROP CONSTRAINT balance_snapshots_pkey;
+ALTER TABLE balance_snapshots ADD PRIMARY KEY (account_id, taken_at);
+ALTER TABLE balance_snapshots DROP COLUMN IF EXISTS currency;
+DELETE FROM entries e USING accounts a WHERE a.id = e.account_id AND a.currency <> e.currency;
+ALTER TABLE entries DROP COLUMN IF EXISTS currency;
+DROP TABLE IF EXISTS pockets;
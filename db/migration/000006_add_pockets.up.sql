@@ -0,0 +1,30 @@
+CREATE TABLE "pockets" (
+  "account_id" uuid NOT NULL,
+  "currency" varchar NOT NULL,
+  "balance" decimal NOT NULL DEFAULT 0,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "currency")
+);
+
+ALTER TABLE "pockets" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+INSERT INTO "pockets" ("account_id", "currency", "balance", "created_at")
+SELECT "id", "currency", "balance", "created_at" FROM "accounts";
+
+ALTER TABLE "entries" ADD COLUMN "currency" varchar;
+
+UPDATE "entries" SET "currency" = "accounts"."currency"
+FROM "accounts" WHERE "accounts"."id" = "entries"."account_id";
+
+ALTER TABLE "entries" ALTER COLUMN "currency" SET NOT NULL;
+
+ALTER TABLE "balance_snapshots" ADD COLUMN "currency" varchar;
+
+UPDATE "balance_snapshots" SET "currency" = "accounts"."currency"
+FROM "accounts" WHERE "accounts"."id" = "balance_snapshots"."account_id";
+
+ALTER TABLE "balance_snapshots" ALTER COLUMN "currency" SET NOT NULL;
+
+ALTER TABLE "balance_snapshots" DROP CONSTRAINT "balance_snapshots_pkey";
+
+ALTER TABLE "balance_snapshots" ADD PRIMARY KEY ("account_id", "currency", "taken_at");
@@ -0,0 +1 @@
+-- Currency codes stay upper case, there is nothing to undo.
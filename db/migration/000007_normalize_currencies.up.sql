@@ -0,0 +1,9 @@
+UPDATE "accounts" SET "currency" = upper(trim("currency")) WHERE "currency" <> upper(trim("currency"));
+
+UPDATE "pockets" SET "currency" = upper(trim("currency")) WHERE "currency" <> upper(trim("currency"));
+
+UPDATE "entries" SET "currency" = upper(trim("currency")) WHERE "currency" <> upper(trim("currency"));
+
+UPDATE "balance_snapshots" SET "currency" = upper(trim("currency")) WHERE "currency" <> upper(trim("currency"));
+
+UPDATE "transfers" SET "currency" = upper(trim("currency")) WHERE "currency" <> upper(trim("currency"));
@@ -0,0 +1,3 @@
+UPDATE transfers SET amount = credited_amount, currency = credited_currency;
+ALTER TABLE transfers DROP COLUMN IF EXISTS credited_currency;
+ALTER TABLE transfers DROP COLUMN IF EXISTS credited_amount;
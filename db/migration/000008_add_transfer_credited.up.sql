@@ -0,0 +1,15 @@
+ALTER TABLE "transfers" ADD COLUMN "credited_amount" decimal;
+
+ALTER TABLE "transfers" ADD COLUMN "credited_currency" varchar;
+
+UPDATE "transfers" SET "credited_amount" = "amount", "credited_currency" = "currency";
+
+UPDATE "transfers" SET "amount" = -"entries"."amount", "currency" = "entries"."currency"
+FROM "entries"
+WHERE "entries"."transfer_id" = "transfers"."id"
+  AND "entries"."account_id" = "transfers"."source_account_id"
+  AND "entries"."amount" < 0;
+
+ALTER TABLE "transfers" ALTER COLUMN "credited_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ALTER COLUMN "credited_currency" SET NOT NULL;
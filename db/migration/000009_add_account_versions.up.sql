@@ -0,0 +1 @@
+ALTER TABLE "accounts" ADD COLUMN "version" integer NOT NULL DEFAULT 1;
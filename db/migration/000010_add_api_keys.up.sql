@@ -0,0 +1,12 @@
+CREATE TABLE "api_keys" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "client" varchar NOT NULL,
+  "prefix" varchar NOT NULL UNIQUE,
+  "hash" bytea NOT NULL,
+  "scopes" varchar[] NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "expires_at" timestamp,
+  "revoked_at" timestamp,
+  "last_used_at" timestamp,
+  PRIMARY KEY ("id")
+);
@@ -0,0 +1 @@
+ALTER TABLE "customers" ADD COLUMN "subject" varchar UNIQUE;
@@ -0,0 +1,20 @@
+CREATE TABLE "roles" (
+  "name" varchar PRIMARY KEY,
+  "description" varchar NOT NULL DEFAULT '',
+  "permissions" varchar[] NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "updated_at" timestamp NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "audit_log" (
+  "id" bigserial PRIMARY KEY,
+  "occurred_at" timestamp NOT NULL DEFAULT (now()),
+  "actor" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "resource" varchar NOT NULL,
+  "request_id" varchar NOT NULL DEFAULT '',
+  "client_ip" varchar NOT NULL DEFAULT '',
+  "detail" varchar NOT NULL DEFAULT ''
+);
+
+CREATE INDEX ON "audit_log" ("occurred_at");
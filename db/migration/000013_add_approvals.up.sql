@@ -0,0 +1,17 @@
+CREATE TABLE "approvals" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "action" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "account_id" uuid NOT NULL,
+  "payload" jsonb NOT NULL,
+  "requested_by" varchar NOT NULL,
+  "decided_by" varchar,
+  "reason" varchar NOT NULL DEFAULT '',
+  "transfer_id" uuid REFERENCES "transfers" ("id"),
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "expires_at" timestamp NOT NULL,
+  "decided_at" timestamp,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX ON "approvals" ("status", "expires_at");
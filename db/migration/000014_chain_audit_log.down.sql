@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only;
+DROP INDEX IF EXISTS audit_log_actor_idx;
+DROP INDEX IF EXISTS audit_log_resource_idx;
+ALTER TABLE audit_log
+  DROP COLUMN IF EXISTS hash,
+  DROP COLUMN IF EXISTS prev_hash,
+  DROP COLUMN IF EXISTS after,
+  DROP COLUMN IF EXISTS before;
@@ -0,0 +1,18 @@
+ALTER TABLE "audit_log"
+  ADD COLUMN "before" json,
+  ADD COLUMN "after" json,
+  ADD COLUMN "prev_hash" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "hash" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "audit_log" ("actor");
+CREATE INDEX ON "audit_log" ("resource");
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+  BEFORE UPDATE OR DELETE OR TRUNCATE ON "audit_log"
+  FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
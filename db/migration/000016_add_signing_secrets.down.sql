@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS signature_nonces;
+DROP TABLE IF EXISTS signing_secrets;
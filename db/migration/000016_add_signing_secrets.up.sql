@@ -0,0 +1,20 @@
+CREATE TABLE "signing_secrets" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "client" varchar NOT NULL,
+  "secret" varchar NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "expires_at" timestamp,
+  "revoked_at" timestamp,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX ON "signing_secrets" ("client");
+
+CREATE TABLE "signature_nonces" (
+  "client" varchar NOT NULL,
+  "nonce" varchar NOT NULL,
+  "expires_at" timestamp NOT NULL,
+  PRIMARY KEY ("client", "nonce")
+);
+
+CREATE INDEX ON "signature_nonces" ("expires_at");
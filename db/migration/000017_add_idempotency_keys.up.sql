@@ -0,0 +1,11 @@
+CREATE TABLE "idempotency_keys" (
+  "key" varchar PRIMARY KEY,
+  "fingerprint" bytea NOT NULL,
+  "done" boolean NOT NULL DEFAULT false,
+  "status" integer,
+  "header" jsonb,
+  "body" bytea,
+  "expires_at" timestamp NOT NULL
+);
+
+CREATE INDEX ON "idempotency_keys" ("expires_at");
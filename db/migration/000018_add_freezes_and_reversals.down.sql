@@ -0,0 +1,4 @@
+UPDATE "roles"
+SET "permissions" = array_remove(array_remove("permissions", 'accounts:freeze'), 'transfers:reverse');
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS frozen;
@@ -0,0 +1,5 @@
+ALTER TABLE "accounts" ADD COLUMN "frozen" boolean NOT NULL DEFAULT false;
+
+UPDATE "roles"
+SET "permissions" = "permissions" || ARRAY['accounts:freeze', 'transfers:reverse']::varchar[], "updated_at" = now()
+WHERE "name" = 'admin' AND NOT 'accounts:freeze' = ANY("permissions");
@@ -2,146 +2,208 @@ package handlers
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/google/uuid"
-	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/config"
 	"github.com/petrostrak/agile-transfer/internal/core/domain"
 	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/validator"
 	"github.com/petrostrak/agile-transfer/utils"
 	"github.com/shopspring/decimal"
 )
 
 type AccountHandler struct {
-	service services.AccountService
+	service   services.AccountService
+	approvals services.ApprovalService
+	timeouts  config.Handlers
 }
 
-func NewAccountHandler(accountService services.AccountService) *AccountHandler {
+func NewAccountHandler(accountService services.AccountService, approvalService services.ApprovalService, timeouts config.Handlers) *AccountHandler {
 	return &AccountHandler{
 		accountService,
+		approvalService,
+		timeouts,
 	}
 }
 
 func (a *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Balance  decimal.Decimal `json:"balance"`
-		Currency string          `json:"currency"`
+		CustomerID *uuid.UUID      `json:"customer_id"`
+		Balance    decimal.Decimal `json:"balance"`
+		Currency   string          `json:"currency"`
 	}
 
 	err := utils.ReadJSON(w, r, &input)
 	if err != nil {
-		utils.BadRequestResponse(w, r, err)
+		errorResponse(w, r, err)
+		return
 	}
 
 	account := &domain.Account{
-		Balance:  input.Balance,
-		Currency: input.Currency,
+		CustomerID: input.CustomerID,
+		Balance:    input.Balance,
+		Currency:   input.Currency,
+	}
+
+	v := validator.New()
+	if domain.ValidateAccount(v, account); !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
 	}
 
 	err = a.service.Insert(account)
 	if err != nil {
-		utils.ServerErrorResponse(w, r, err)
+		errorResponse(w, r, err)
 		return
 	}
+	auditChange(r, domain.AuditAccountCreated, "accounts/"+account.ID.String(), nil, snapshot(account))
 
 	headers := make(http.Header)
-	headers.Set("Location", fmt.Sprintf("/accounts/%d", account.ID))
+	headers.Set("Location", fmt.Sprintf("%s/accounts/%s", APIVersion(r).Prefix(), account.ID))
+	headers.Set("ETag", etag(account.Version))
 
-	err = utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"account": account}, headers)
+	err = utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"account": responseFormat(r).account(account)}, headers)
 	if err != nil {
 		utils.ServerErrorResponse(w, r, err)
 	}
 }
 
 func (a *AccountHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
-	id := utils.ReadIDParam(r)
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
 
 	account, err := a.service.Get(id)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrRecordNotFound):
-			utils.NotFoundResponse(w, r)
-		default:
-			utils.ServerErrorResponse(w, r, err)
-		}
+		errorResponse(w, r, err)
 		return
 	}
-
-	var acc struct {
-		ID        uuid.UUID       `json:"id"`
-		Balance   decimal.Decimal `json:"balance"`
-		Currency  string          `json:"currency"`
-		CreatedAt string          `json:"created_at"`
+	if err = authorizeAccount(r, account); err != nil {
+		errorResponse(w, r, err)
+		return
 	}
-	acc.ID = account.ID
-	acc.Balance = account.Balance
-	acc.Currency = account.Currency
-	acc.CreatedAt = utils.HumanDate(account.CreatedAt)
 
-	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"account": acc}, nil)
+	headers := make(http.Header)
+	headers.Set("ETag", etag(account.Version))
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"account": accountReadFormat(r).account(account)}, headers)
 	if err != nil {
 		utils.ServerErrorResponse(w, r, err)
 	}
 }
 
+// UpdateAccount changes the account if it is still at the version named by
+// the If-Match header. Changes to the balance or currency are held for
+// approval when the approval policy says so.
 func (a *AccountHandler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
-	id := utils.ReadIDParam(r)
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
 
 	account, err := a.service.Get(id)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrRecordNotFound):
-			utils.NotFoundResponse(w, r)
-		default:
-			utils.ServerErrorResponse(w, r, err)
-		}
+		errorResponse(w, r, err)
+		return
+	}
+	if err = checkIfMatch(r, account.Version); err != nil {
+		errorResponse(w, r, err)
 		return
 	}
 
 	var input struct {
-		Balance  *decimal.Decimal `json:"balance"`
-		Currency *string          `json:"currency"`
+		CustomerID *uuid.UUID       `json:"customer_id"`
+		Balance    *decimal.Decimal `json:"balance"`
+		Currency   *string          `json:"currency"`
 	}
 
 	err = utils.ReadJSON(w, r, &input)
 	if err != nil {
-		utils.BadRequestResponse(w, r, err)
+		errorResponse(w, r, err)
+		return
 	}
+	before, original := *account, snapshot(account)
 	if input.Balance != nil {
 		account.Balance = *input.Balance
 	}
 	if input.Currency != nil {
 		account.Currency = *input.Currency
 	}
+	if input.CustomerID != nil {
+		account.CustomerID = input.CustomerID
+	}
+
+	v := validator.New()
+	if domain.ValidateAccount(v, account); !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if change := accountChange(account); a.approvals.Policy().ChangeNeedsApproval(&before, change) {
+		requestApproval(w, r, a.approvals, &domain.Approval{
+			Action:    domain.ApprovalBalanceAdjustment,
+			AccountID: account.ID,
+			Change:    change,
+		})
+		return
+	}
 
 	err = a.service.Update(account)
 	if err != nil {
-		utils.ServerErrorResponse(w, r, err)
+		errorResponse(w, r, err)
 		return
 	}
+	auditChange(r, domain.AuditAccountUpdated, "accounts/"+account.ID.String(), original, snapshot(account))
 
-	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"account": account}, nil)
+	headers := make(http.Header)
+	headers.Set("ETag", etag(account.Version))
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"account": responseFormat(r).account(account)}, headers)
 	if err != nil {
 		utils.ServerErrorResponse(w, r, err)
 	}
 }
 
+// DeleteAccount removes the account if it is still at the version named by
+// the If-Match header, or holds its removal for approval when the approval
+// policy says so.
 func (a *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
-	id := utils.ReadIDParam(r)
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
 
-	err := a.service.Delete(id)
+	account, err := a.service.Get(id)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrRecordNotFound):
-			utils.NotFoundResponse(w, r)
-		default:
-			utils.ServerErrorResponse(w, r, err)
-		}
+		errorResponse(w, r, err)
+		return
+	}
+	if err = checkIfMatch(r, account.Version); err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	if a.approvals.Policy().Closures {
+		requestApproval(w, r, a.approvals, &domain.Approval{
+			Action:    domain.ApprovalAccountClosure,
+			AccountID: account.ID,
+			Change:    accountChange(account),
+		})
+		return
+	}
+
+	err = a.service.Delete(id, account.Version)
+	if err != nil {
+		errorResponse(w, r, err)
 		return
 	}
+	auditChange(r, domain.AuditAccountDeleted, "accounts/"+account.ID.String(), snapshot(account), nil)
 
 	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"message": "account successfully deleted"}, nil)
 	if err != nil {
@@ -149,37 +211,90 @@ func (a *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// FreezeAccount stops the transfers from and to the account.
+func (a *AccountHandler) FreezeAccount(w http.ResponseWriter, r *http.Request) {
+	a.setFrozen(w, r, true)
+}
+
+// UnfreezeAccount lets the account send and receive transfers again.
+func (a *AccountHandler) UnfreezeAccount(w http.ResponseWriter, r *http.Request) {
+	a.setFrozen(w, r, false)
+}
+
+func (a *AccountHandler) setFrozen(w http.ResponseWriter, r *http.Request, frozen bool) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	original, err := a.service.Get(id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	account, err := a.service.SetFrozen(id, frozen)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	action := domain.AuditAccountFrozen
+	if !frozen {
+		action = domain.AuditAccountUnfrozen
+	}
+	auditChange(r, action, "accounts/"+account.ID.String(), snapshot(original), snapshot(account))
+
+	headers := make(http.Header)
+	headers.Set("ETag", etag(account.Version))
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"account": responseFormat(r).account(account)}, headers)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// accountChange is the change bringing an account at its version to the
+// state of account.
+func accountChange(account *domain.Account) *domain.AccountChange {
+	return &domain.AccountChange{
+		Version:    account.Version,
+		Balance:    account.Balance,
+		Currency:   account.Currency,
+		CustomerID: account.CustomerID,
+	}
+}
+
+// GetAllAccounts returns one page of the accounts, ordered by id. Customers
+// only get their own.
 func (a *AccountHandler) GetAllAccounts(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), a.timeouts.ListTimeout)
 	defer cancel()
 
+	v := validator.New()
+	page := utils.ReadPage(r, v)
+	if !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
 	accounts, err := a.service.GetAll(ctx)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrRecordNotFound):
-			utils.NotFoundResponse(w, r)
-		default:
-			utils.ServerErrorResponse(w, r, err)
-		}
+		errorResponse(w, r, err)
 		return
 	}
-
-	var accs []any
-	for _, account := range accounts {
-		var acc struct {
-			ID        uuid.UUID       `json:"id"`
-			Balance   decimal.Decimal `json:"balance"`
-			Currency  string          `json:"currency"`
-			CreatedAt string          `json:"created_at"`
+	if user := CurrentUser(r); user != nil && !user.Admin() {
+		owned := make([]domain.Account, 0, len(accounts))
+		for i := range accounts {
+			if user.Owns(&accounts[i]) {
+				owned = append(owned, accounts[i])
+			}
 		}
-		acc.ID = account.ID
-		acc.Balance = account.Balance
-		acc.Currency = account.Currency
-		acc.CreatedAt = utils.HumanDate(account.CreatedAt)
-		accs = append(accs, acc)
+		accounts = owned
 	}
+	accounts, metadata := utils.Paginate(accounts, page)
 
-	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"accounts": accs}, nil)
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"accounts": accountReadFormat(r).accounts(accounts), "metadata": metadata}, nil)
 	if err != nil {
 		utils.ServerErrorResponse(w, r, err)
 	}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+// defaultOverlap is how long a rotated key keeps working when the request
+// does not say.
+const defaultOverlap = 24 * time.Hour
+
+type APIKeyHandler struct {
+	service services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService,
+	}
+}
+
+// IssueKey creates a key. The response is the only time the key is shown.
+func (a *APIKeyHandler) IssueKey(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Client string         `json:"client"`
+		Scopes []domain.Scope `json:"scopes"`
+	}
+
+	err := utils.ReadJSON(w, r, &input)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	key := &domain.APIKey{
+		Client: input.Client,
+		Scopes: input.Scopes,
+	}
+
+	v := validator.New()
+	if domain.ValidateAPIKey(v, key); !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	plain, err := a.service.Issue(r.Context(), key)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditAPIKeyIssued, "api-keys/"+key.ID.String(), nil, snapshot(key))
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("%s/api-keys/%s", APIVersion(r).Prefix(), key.ID))
+	headers.Set("Cache-Control", "no-store")
+
+	err = utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"api_key": responseFormat(r).apiKey(key), "key": plain}, headers)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (a *APIKeyHandler) GetKey(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	key, err := a.service.Get(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"api_key": responseFormat(r).apiKey(key)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (a *APIKeyHandler) GetAllKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := a.service.GetAll(r.Context())
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"api_keys": responseFormat(r).apiKeys(keys)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// RotateKey issues a key with the client and scopes of an existing one,
+// which keeps working for the overlap given, e.g. "1h", so that the client
+// can switch keys without downtime.
+func (a *APIKeyHandler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		Overlap *string `json:"overlap"`
+	}
+
+	// The body is optional.
+	err = utils.ReadJSON(w, r, &input)
+	if err != nil && !errors.Is(err, utils.ErrEmptyBody) {
+		errorResponse(w, r, err)
+		return
+	}
+
+	overlap := defaultOverlap
+	v := validator.New()
+	if input.Overlap != nil {
+		overlap, err = time.ParseDuration(*input.Overlap)
+		v.Check(err == nil, "overlap", "must be a duration such as 30m or 24h")
+		v.Check(err != nil || (overlap >= 0 && overlap <= 30*24*time.Hour), "overlap", "must be between 0s and 720h")
+	}
+	if !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	key, plain, err := a.service.Rotate(r.Context(), id, overlap)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	// The snapshot is of the new key; the old one is named by the resource.
+	auditChange(r, domain.AuditAPIKeyRotated, "api-keys/"+id.String(), nil, snapshot(key))
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("%s/api-keys/%s", APIVersion(r).Prefix(), key.ID))
+	headers.Set("Cache-Control", "no-store")
+
+	err = utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"api_key": responseFormat(r).apiKey(key), "key": plain}, headers)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// RevokeKey stops a key from working at once.
+func (a *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = a.service.Revoke(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditAPIKeyRevoked, "api-keys/"+id.String(), nil, nil)
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"message": "api key successfully revoked"}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
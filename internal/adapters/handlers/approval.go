@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+type ApprovalHandler struct {
+	service services.ApprovalService
+	access  services.AccessService
+}
+
+func NewApprovalHandler(approvalService services.ApprovalService, accessService services.AccessService) *ApprovalHandler {
+	return &ApprovalHandler{
+		approvalService,
+		accessService,
+	}
+}
+
+// requestApproval holds the action until a second person approves it and
+// answers 202 Accepted with the approval.
+func requestApproval(w http.ResponseWriter, r *http.Request, approvals services.ApprovalService, approval *domain.Approval) {
+	approval.RequestedBy = caller(r)
+
+	err := approvals.Request(r.Context(), approval)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditApprovalRequested, "approvals/"+approval.ID.String(), nil, snapshot(approval))
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("%s/approvals/%s", APIVersion(r).Prefix(), approval.ID))
+
+	err = utils.WriteJSON(w, http.StatusAccepted, utils.Envelope{"approval": responseFormat(r).approval(approval)}, headers)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (ap *ApprovalHandler) GetApproval(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	approval, err := ap.service.Get(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"approval": responseFormat(r).approval(approval)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// GetAllApprovals returns the approvals, newest first, only those with the
+// status given in the status parameter when there is one.
+func (ap *ApprovalHandler) GetAllApprovals(w http.ResponseWriter, r *http.Request) {
+	status := domain.ApprovalStatus(r.URL.Query().Get("status"))
+
+	v := validator.New()
+	v.Check(status == "" || validator.In(status, domain.ApprovalPending, domain.ApprovalApproved, domain.ApprovalRejected, domain.ApprovalExpired, domain.ApprovalFailed), "status", "must be pending, approved, rejected, expired or failed")
+	if !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	approvals, err := ap.service.GetAll(r.Context(), status)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"approvals": responseFormat(r).approvals(approvals)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// ApproveApproval carries out the action of a pending approval. The caller
+// must be someone other than who asked for it, and be allowed to carry the
+// action out themselves.
+func (ap *ApprovalHandler) ApproveApproval(w http.ResponseWriter, r *http.Request) {
+	approval, ok := ap.authorizedApproval(w, r)
+	if !ok {
+		return
+	}
+
+	pending := snapshot(approval)
+	approval, err := ap.service.Approve(r.Context(), approval.ID, caller(r))
+	if approval != nil {
+		// Approvals whose action failed are decided too.
+		auditChange(r, domain.AuditApprovalApproved, "approvals/"+approval.ID.String(), pending, snapshot(approval))
+	}
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"approval": responseFormat(r).approval(approval)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// RejectApproval drops the action of a pending approval, for the reason
+// given, if any.
+func (ap *ApprovalHandler) RejectApproval(w http.ResponseWriter, r *http.Request) {
+	approval, ok := ap.authorizedApproval(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+
+	// The body is optional.
+	err := utils.ReadJSON(w, r, &input)
+	if err != nil && !errors.Is(err, utils.ErrEmptyBody) {
+		errorResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(len(input.Reason) <= 500, "reason", "must not be more than 500 bytes long")
+	if !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	pending := snapshot(approval)
+	approval, err = ap.service.Reject(r.Context(), approval.ID, caller(r), input.Reason)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditApprovalRejected, "approvals/"+approval.ID.String(), pending, snapshot(approval))
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"approval": responseFormat(r).approval(approval)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// authorizedApproval returns the approval named in the path if the caller
+// may carry out its action, answering the request otherwise.
+func (ap *ApprovalHandler) authorizedApproval(w http.ResponseWriter, r *http.Request) (*domain.Approval, bool) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return nil, false
+	}
+
+	approval, err := ap.service.Get(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return nil, false
+	}
+
+	permission := approval.Action.Permission()
+	if err := checkPermission(r, permission, false); err != nil {
+		recordDenial(ap.access, r, permission)
+		errorResponse(w, r, err)
+		return nil, false
+	}
+	return approval, true
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+type AuditHandler struct {
+	service services.AuditService
+}
+
+func NewAuditHandler(auditService services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService,
+	}
+}
+
+type auditContextKey struct{}
+
+// auditRecord is the change a state-changing request made, as its handler
+// describes it with auditChange.
+type auditRecord struct {
+	action        domain.AuditAction
+	resource      string
+	before, after json.RawMessage
+}
+
+// Middleware records every request other than GET, HEAD and OPTIONS that
+// succeeds in the audit log, with the caller, the request id and the
+// client's address. Handlers describe the change with auditChange; requests
+// whose handler does not are recorded by method and path. Changes described
+// are recorded even when the request then fails, as approvals whose action
+// fails do. The response is held back until the request is recorded, and
+// replaced by a server error when it cannot be. It must run after
+// Authenticate, and after IdempotencyKeys so that replays, which change
+// nothing, are not recorded.
+func (a *AuditHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		record := &auditRecord{}
+		r = r.WithContext(context.WithValue(r.Context(), auditContextKey{}, record))
+		rec := &bufferedResponse{header: w.Header().Clone()}
+
+		next.ServeHTTP(rec, r)
+		if rec.status >= http.StatusBadRequest && record.action == "" {
+			rec.flush(w)
+			return
+		}
+
+		entry := &domain.AuditEntry{
+			Actor:     caller(r),
+			Action:    record.action,
+			Resource:  record.resource,
+			RequestID: middleware.GetReqID(r.Context()),
+			ClientIP:  r.RemoteAddr,
+			Before:    record.before,
+			After:     record.after,
+		}
+		if entry.Action == "" {
+			entry.Action, entry.Resource = domain.AuditRequest, r.Method+" "+r.URL.Path
+		}
+
+		// The change is made whether or not the client is still waiting.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := a.service.Record(ctx, entry); err != nil {
+			utils.ServerErrorResponse(w, r, fmt.Errorf("recording %s %s in the audit log: %w", entry.Action, entry.Resource, err))
+			return
+		}
+		rec.flush(w)
+	})
+}
+
+// auditChange describes the change the request made for the audit log: the
+// action, the resource it was made to, and snapshots of the resource before
+// and after it, nil when the resource did not exist.
+func auditChange(r *http.Request, action domain.AuditAction, resource string, before, after json.RawMessage) {
+	if record, ok := r.Context().Value(auditContextKey{}).(*auditRecord); ok {
+		record.action, record.resource = action, resource
+		record.before, record.after = before, after
+	}
+}
+
+// snapshot is the JSON encoding of a resource for the audit log, nil for
+// nil.
+func snapshot(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+// GetAuditLog returns one page of the audit log, newest entry first,
+// filtered by the actor, action and resource parameters and the from and to
+// times.
+func (a *AuditHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.AuditFilter{
+		Actor:    query.Get("actor"),
+		Action:   domain.AuditAction(query.Get("action")),
+		Resource: query.Get("resource"),
+	}
+
+	var err error
+	if filter.From, err = utils.ReadTimeQuery(r, "from", time.Time{}); err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	if filter.To, err = utils.ReadTimeQuery(r, "to", time.Time{}); err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.To.After(filter.From) {
+		errorResponse(w, r, utils.ErrInvalidPeriod)
+		return
+	}
+
+	v := validator.New()
+	page := utils.ReadPage(r, v)
+	if !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	entries, err := a.service.GetAll(r.Context(), filter)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	entries, metadata := utils.Paginate(entries, page)
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"entries": responseFormat(r).auditEntries(entries), "metadata": metadata}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// VerifyAuditLog checks the hash chain of the whole audit log.
+func (a *AuditHandler) VerifyAuditLog(w http.ResponseWriter, r *http.Request) {
+	result, err := a.service.Verify(r.Context())
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"verification": result}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// bufferedResponse holds a response back until flush passes it on.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *bufferedResponse) Header() http.Header {
+	return rec.header
+}
+
+func (rec *bufferedResponse) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *bufferedResponse) Write(b []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(b)
+}
+
+// flush writes the response held back to w.
+func (rec *bufferedResponse) flush(w http.ResponseWriter) {
+	for name := range w.Header() {
+		delete(w.Header(), name)
+	}
+	for name, values := range rec.header {
+		w.Header()[name] = values
+	}
+	rec.WriteHeader(http.StatusOK)
+	w.WriteHeader(rec.status)
+	w.Write(rec.body.Bytes())
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+// AuthHandler authenticates requests, either with an API key, for clients
+// of the service, or with a bearer token from the identity provider, for
+// customers and back-office users.
+type AuthHandler struct {
+	keys   services.APIKeyService
+	users  services.UserService
+	access services.AccessService
+}
+
+func NewAuthHandler(apiKeyService services.APIKeyService, userService services.UserService, accessService services.AccessService) *AuthHandler {
+	return &AuthHandler{
+		apiKeyService,
+		userService,
+		accessService,
+	}
+}
+
+type apiKeyContextKey struct{}
+
+type userContextKey struct{}
+
+// Authenticate requires requests to carry an active API key in the X-API-Key
+// header or a valid bearer token in the Authorization header, and makes them
+// available to the handlers through APIClient and CurrentUser.
+func (a *AuthHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " "); found && strings.EqualFold(scheme, "Bearer") {
+			user, err := a.users.Authenticate(r.Context(), strings.TrimSpace(token))
+			if err != nil {
+				unauthenticated(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userContextKey{}, user)))
+			return
+		}
+
+		plain := r.Header.Get("X-API-Key")
+		if plain == "" {
+			unauthenticated(w, r, utils.ErrMissingCredentials)
+			return
+		}
+
+		key, err := a.keys.Authenticate(r.Context(), plain)
+		if err != nil {
+			unauthenticated(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, key)))
+	})
+}
+
+// unauthenticated sends the error of a request whose credentials were
+// refused, naming the schemes it could have used.
+func unauthenticated(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Add("WWW-Authenticate", `ApiKey realm="agile-transfer", header="X-API-Key"`)
+	w.Header().Add("WWW-Authenticate", `Bearer realm="agile-transfer"`)
+	errorResponse(w, r, err)
+}
+
+// APIClient is the key the request was authenticated with, nil when it was
+// authenticated with a bearer token or the route does not require
+// authentication.
+func APIClient(r *http.Request) *domain.APIKey {
+	key, _ := r.Context().Value(apiKeyContextKey{}).(*domain.APIKey)
+	return key
+}
+
+// CurrentUser is the user the bearer token of the request was issued to, nil
+// when it was authenticated with an API key or the route does not require
+// authentication.
+func CurrentUser(r *http.Request) *domain.User {
+	user, _ := r.Context().Value(userContextKey{}).(*domain.User)
+	return user
+}
+
+// caller names who made the request, so that what clients choose, such as
+// idempotency keys, only has to be unique per caller.
+func caller(r *http.Request) string {
+	if key := APIClient(r); key != nil {
+		return "key:" + key.Client
+	}
+	if user := CurrentUser(r); user != nil {
+		return "user:" + user.Subject
+	}
+	return ""
+}
+
+// RequirePermission refuses requests whose credentials do not grant
+// permission: API keys need the scope the permission belongs to, users a
+// role with the permission. Customers are refused whatever the permission.
+// Refusals are recorded in the audit trail. It must run after Authenticate.
+func (a *AuthHandler) RequirePermission(permission domain.Permission) func(http.Handler) http.Handler {
+	return a.requirePermission(permission, false)
+}
+
+// RequireOwnerPermission is RequirePermission for the routes customers may
+// use too, when they are granted permission. Their handlers must check that
+// the customer owns the accounts involved.
+func (a *AuthHandler) RequireOwnerPermission(permission domain.Permission) func(http.Handler) http.Handler {
+	return a.requirePermission(permission, true)
+}
+
+func (a *AuthHandler) requirePermission(permission domain.Permission, customers bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := checkPermission(r, permission, customers)
+			switch {
+			case errors.Is(err, utils.ErrMissingCredentials):
+				unauthenticated(w, r, err)
+			case err != nil:
+				recordDenial(a.access, r, permission)
+				errorResponse(w, r, err)
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// checkPermission returns why the credentials of the request do not grant
+// permission, nil when they do. Customers are only granted it when
+// customers is set.
+func checkPermission(r *http.Request, permission domain.Permission, customers bool) error {
+	switch key, user := APIClient(r), CurrentUser(r); {
+	case key != nil:
+		if !key.Allows(permission.Scope()) {
+			return fmt.Errorf("%w: %s", utils.ErrInsufficientScope, permission.Scope())
+		}
+	case user != nil:
+		if !user.Can(permission) || (!customers && !user.Staff()) {
+			return fmt.Errorf("%w: %s", utils.ErrPermissionDenied, permission)
+		}
+	default:
+		return utils.ErrMissingCredentials
+	}
+	return nil
+}
+
+// recordDenial records that the request was refused for lack of permission.
+// A failure to record it is logged, and the request refused all the same.
+func recordDenial(access services.AccessService, r *http.Request, permission domain.Permission) {
+	entry := &domain.AuditEntry{
+		Actor:     caller(r),
+		Resource:  r.Method + " " + r.URL.Path,
+		RequestID: middleware.GetReqID(r.Context()),
+		ClientIP:  r.RemoteAddr,
+		Detail:    "missing permission " + string(permission),
+	}
+	if err := access.Deny(r.Context(), entry); err != nil {
+		utils.LogError(fmt.Errorf("recording denied request: %w", err))
+	}
+}
+
+// authorizeAccount refuses customers access to the accounts of others. API
+// keys and back-office users may use every account their permissions let
+// them.
+func authorizeAccount(r *http.Request, account *domain.Account) error {
+	if user := CurrentUser(r); user != nil && !user.Owns(account) {
+		return utils.ErrAccountNotOwned
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/petrostrak/agile-transfer/internal/tlsconfig"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+// ClientCertificates binds API clients to the subjects of the client
+// certificates they connect with under mutual TLS, such as
+// "CN=partner,O=Acme".
+type ClientCertificates struct {
+	subjects *tlsconfig.ClientSubjects
+}
+
+// NewClientCertificates binds the API client each subject maps to.
+func NewClientCertificates(clients map[string]string) *ClientCertificates {
+	return &ClientCertificates{tlsconfig.NewClientSubjects(clients)}
+}
+
+// Middleware refuses the requests made with an API key of a client other
+// than the one the certificate of the connection maps to, and those of bound
+// clients over connections without one of their certificates. Requests
+// authenticated with a bearer token, and those of unbound clients over
+// connections with unmapped certificates, are let through. It must run after
+// Authenticate.
+func (c *ClientCertificates) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := APIClient(r)
+		if key != nil && !c.subjects.Allows(r.TLS, key.Client) {
+			unauthenticated(w, r, utils.ErrCertificateMismatch)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+type CustomerHandler struct {
+	service services.CustomerService
+}
+
+func NewCustomerHandler(customerService services.CustomerService) *CustomerHandler {
+	return &CustomerHandler{
+		customerService,
+	}
+}
+
+func (c *CustomerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name              string              `json:"name"`
+		Email             string              `json:"email"`
+		Type              domain.CustomerType `json:"type"`
+		RestrictTransfers bool                `json:"restrict_transfers"`
+		Subject           *string             `json:"subject"`
+	}
+
+	err := utils.ReadJSON(w, r, &input)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	customer := &domain.Customer{
+		Name:              input.Name,
+		Email:             input.Email,
+		Type:              input.Type,
+		Status:            domain.CustomerActive,
+		RestrictTransfers: input.RestrictTransfers,
+		Subject:           input.Subject,
+	}
+
+	v := validator.New()
+	if domain.ValidateCustomer(v, customer); !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = c.service.Insert(r.Context(), customer)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditCustomerCreated, "customers/"+customer.ID.String(), nil, snapshot(customer))
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("%s/customers/%s", APIVersion(r).Prefix(), customer.ID))
+
+	err = utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"customer": responseFormat(r).customer(customer)}, headers)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (c *CustomerHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	customer, err := c.service.Get(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"customer": responseFormat(r).customer(customer)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (c *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	customer, err := c.service.Get(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		Name              *string                `json:"name"`
+		Email             *string                `json:"email"`
+		Type              *domain.CustomerType   `json:"type"`
+		Status            *domain.CustomerStatus `json:"status"`
+		RestrictTransfers *bool                  `json:"restrict_transfers"`
+		Subject           *string                `json:"subject"`
+	}
+
+	err = utils.ReadJSON(w, r, &input)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	original := snapshot(customer)
+	if input.Name != nil {
+		customer.Name = *input.Name
+	}
+	if input.Email != nil {
+		customer.Email = *input.Email
+	}
+	if input.Type != nil {
+		customer.Type = *input.Type
+	}
+	if input.Status != nil {
+		customer.Status = *input.Status
+	}
+	if input.RestrictTransfers != nil {
+		customer.RestrictTransfers = *input.RestrictTransfers
+	}
+	// An empty subject unlinks the customer from its user.
+	if input.Subject != nil {
+		customer.Subject = input.Subject
+		if *input.Subject == "" {
+			customer.Subject = nil
+		}
+	}
+
+	v := validator.New()
+	if domain.ValidateCustomer(v, customer); !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = c.service.Update(r.Context(), customer)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditCustomerUpdated, "customers/"+customer.ID.String(), original, snapshot(customer))
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"customer": responseFormat(r).customer(customer)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (c *CustomerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	customer, err := c.service.Get(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = c.service.Delete(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditCustomerDeleted, "customers/"+id.String(), snapshot(customer), nil)
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"message": "customer successfully deleted"}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// GetAllCustomers returns one page of the customers, oldest first.
+func (c *CustomerHandler) GetAllCustomers(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	page := utils.ReadPage(r, v)
+	if !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	customers, err := c.service.GetAll(r.Context())
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+		return
+	}
+	customers, metadata := utils.Paginate(customers, page)
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"customers": responseFormat(r).customers(customers), "metadata": metadata}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// GetCustomerAccounts lists the accounts of a customer along with the total
+// balance the customer holds in each currency.
+func (c *CustomerHandler) GetCustomerAccounts(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	accounts, balances, err := c.service.Accounts(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	f := responseFormat(r)
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"accounts": f.accounts(accounts), "balances": f.currencyBalances(balances)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (c *CustomerHandler) GetBeneficiaries(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	beneficiaries, err := c.service.GetBeneficiaries(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"beneficiaries": responseFormat(r).beneficiaries(beneficiaries)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (c *CustomerHandler) AddBeneficiary(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		AccountID uuid.UUID `json:"account_id"`
+	}
+
+	err = utils.ReadJSON(w, r, &input)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if domain.ValidateID(v, "account_id", input.AccountID); !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	beneficiary := &domain.Beneficiary{
+		CustomerID: id,
+		AccountID:  input.AccountID,
+	}
+
+	err = c.service.AddBeneficiary(r.Context(), beneficiary)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditBeneficiaryAdded, fmt.Sprintf("customers/%s/beneficiaries/%s", id, input.AccountID), nil, snapshot(beneficiary))
+
+	err = utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"beneficiary": responseFormat(r).beneficiary(beneficiary)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (c *CustomerHandler) RemoveBeneficiary(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	accountID, err := utils.ReadUUIDParam(r, "accountID")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = c.service.RemoveBeneficiary(r.Context(), id, accountID)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditBeneficiaryRemoved, fmt.Sprintf("customers/%s/beneficiaries/%s", id, accountID), nil, nil)
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"message": "beneficiary successfully removed"}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
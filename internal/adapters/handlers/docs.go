@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+)
+
+// docsPage renders the OpenAPI document served next to it, at openapi.json.
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Agile Transfer API</title>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+	<redoc spec-url="openapi.json"></redoc>
+	<script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
+
+type DocsHandler struct {
+	spec []byte
+}
+
+func NewDocsHandler(spec []byte) *DocsHandler {
+	return &DocsHandler{
+		spec,
+	}
+}
+
+func (d *DocsHandler) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(d.spec)
+}
+
+func (d *DocsHandler) Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(docsPage))
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+// problemCode is the status and stable code an error is reported with. The
+// codes are part of the API and documented in docs/errors.md; do not rename
+// them.
+type problemCode struct {
+	err    error
+	status int
+	code   string
+}
+
+var problemCodes = []problemCode{
+	// Malformed requests
+	{utils.ErrEmptyBody, http.StatusBadRequest, "empty_body"},
+	{utils.ErrBadJSON, http.StatusBadRequest, "malformed_body"},
+	{utils.ErrSingleJSON, http.StatusBadRequest, "malformed_body"},
+	{utils.ErrBodyTooLarge, http.StatusRequestEntityTooLarge, "body_too_large"},
+	{utils.ErrInvalidIDParam, http.StatusBadRequest, "invalid_parameter"},
+	{utils.ErrInvalidDateParam, http.StatusBadRequest, "invalid_parameter"},
+	{utils.ErrInvalidTimeParam, http.StatusBadRequest, "invalid_parameter"},
+	{utils.ErrInvalidFormat, http.StatusBadRequest, "invalid_parameter"},
+	{utils.ErrInvalidTimestamps, http.StatusBadRequest, "invalid_parameter"},
+	{utils.ErrInvalidPeriod, http.StatusBadRequest, "invalid_period"},
+	{utils.ErrInvalidIdempotency, http.StatusBadRequest, "invalid_idempotency_key"},
+	{utils.ErrVersionSunset, http.StatusGone, "api_version_sunset"},
+
+	// Authentication
+	{utils.ErrMissingCredentials, http.StatusUnauthorized, "unauthenticated"},
+	{utils.ErrInvalidAPIKey, http.StatusUnauthorized, "unauthenticated"},
+	{utils.ErrInvalidToken, http.StatusUnauthorized, "unauthenticated"},
+	{utils.ErrSignatureRequired, http.StatusUnauthorized, "signature_required"},
+	{utils.ErrInvalidSignature, http.StatusUnauthorized, "invalid_signature"},
+	{utils.ErrStaleSignature, http.StatusUnauthorized, "stale_signature"},
+	{utils.ErrReplayedSignature, http.StatusUnauthorized, "replayed_signature"},
+	{utils.ErrCertificateMismatch, http.StatusUnauthorized, "certificate_mismatch"},
+	{utils.ErrInsufficientScope, http.StatusForbidden, "insufficient_scope"},
+	{utils.ErrPermissionDenied, http.StatusForbidden, "permission_denied"},
+	{utils.ErrAccountNotOwned, http.StatusForbidden, "account_not_owned"},
+	{utils.ErrSelfApproval, http.StatusForbidden, "self_approval"},
+
+	// Missing and conflicting resources
+	{repository.ErrUnknownAccount, http.StatusUnprocessableEntity, "unknown_account"},
+	{repository.ErrUnknownCustomer, http.StatusUnprocessableEntity, "unknown_customer"},
+	{repository.ErrPocketExists, http.StatusConflict, "pocket_exists"},
+	{repository.ErrCurrencyInUse, http.StatusConflict, "currency_in_use"},
+	{repository.ErrDuplicateEmail, http.StatusConflict, "duplicate_email"},
+	{repository.ErrDuplicateSubject, http.StatusConflict, "duplicate_subject"},
+	{repository.ErrDuplicateRole, http.StatusConflict, "duplicate_role"},
+	{utils.ErrCustomerHasAccounts, http.StatusConflict, "customer_has_accounts"},
+	{utils.ErrIdempotencyInUse, http.StatusConflict, "idempotency_key_in_use"},
+	{utils.ErrIdempotencyReused, http.StatusUnprocessableEntity, "idempotency_key_reused"},
+	{utils.ErrAPIKeyInactive, http.StatusConflict, "api_key_inactive"},
+	{utils.ErrSigningInactive, http.StatusConflict, "signing_secret_inactive"},
+	{utils.ErrApprovalNotPending, http.StatusConflict, "approval_not_pending"},
+	{repository.ErrTransferExists, http.StatusConflict, "transfer_reversed"},
+	{utils.ErrPreconditionMissing, http.StatusPreconditionRequired, "precondition_required"},
+	{utils.ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed"},
+	{utils.ErrRateLimited, http.StatusTooManyRequests, "rate_limited"},
+	{repository.ErrEditConflict, http.StatusPreconditionFailed, "precondition_failed"},
+
+	// Business rules
+	{utils.ErrInsufficientBalance, http.StatusUnprocessableEntity, "insufficient_balance"},
+	{repository.ErrInsufficientFunds, http.StatusUnprocessableEntity, "insufficient_balance"},
+	{utils.ErrIdenticalAccount, http.StatusUnprocessableEntity, "identical_account"},
+	{utils.ErrInvalidAmount, http.StatusUnprocessableEntity, "invalid_amount"},
+	{utils.ErrSameCurrency, http.StatusUnprocessableEntity, "same_currency"},
+	{utils.ErrPocketNotFound, http.StatusUnprocessableEntity, "pocket_not_found"},
+	{repository.ErrPocketNotFound, http.StatusUnprocessableEntity, "pocket_not_found"},
+	{repository.ErrConversionFailed, http.StatusUnprocessableEntity, "conversion_failed"},
+	{utils.ErrCustomerNotActive, http.StatusUnprocessableEntity, "customer_not_active"},
+	{utils.ErrAccountFrozen, http.StatusUnprocessableEntity, "account_frozen"},
+	{utils.ErrTargetNotAllowed, http.StatusUnprocessableEntity, "target_not_allowed"},
+	{utils.ErrInvalidCustomer, http.StatusUnprocessableEntity, "invalid_customer"},
+	{utils.ErrInvalidInterest, http.StatusUnprocessableEntity, "invalid_interest_config"},
+	{domain.ErrCurrencyMismatch, http.StatusUnprocessableEntity, "currency_mismatch"},
+	{currency.ErrUnknownCurrency, http.StatusUnprocessableEntity, "unknown_currency"},
+	{currency.ErrCurrencyDisabled, http.StatusUnprocessableEntity, "currency_disabled"},
+	{currency.ErrInvalidPrecision, http.StatusUnprocessableEntity, "invalid_precision"},
+
+	// Dependencies
+	{utils.ErrCurrencyConvertion, http.StatusBadGateway, "currency_conversion_failed"},
+}
+
+// errorResponse sends err as a problem with its documented code, or as an
+// internal error when it is not one the API reports.
+func errorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		utils.NotFoundResponse(w, r)
+		return
+	}
+
+	for _, p := range problemCodes {
+		if errors.Is(err, p.err) {
+			utils.ErrorResponse(w, r, p.status, p.code, err.Error())
+			return
+		}
+	}
+
+	utils.ServerErrorResponse(w, r, err)
+}
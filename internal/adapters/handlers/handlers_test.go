@@ -4,6 +4,8 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -17,7 +19,12 @@ import (
 	"github.com/ory/dockertest"
 	"github.com/ory/dockertest/docker"
 	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/config"
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
 	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/utils"
+	"github.com/shopspring/decimal"
 )
 
 var (
@@ -36,8 +43,14 @@ var (
 	testRepo        repository.PostgresRepository
 	accountService  *services.AccountService
 	transferService *services.TransferService
+	ledgerService   *services.LedgerService
+	customerService *services.CustomerService
+	walletService   *services.WalletService
 	accountHandler  *AccountHandler
 	transferHandler *TransferHandler
+	ledgerHandler   *LedgerHandler
+	customerHandler *CustomerHandler
+	walletHandler   *WalletHandler
 )
 
 func TestMain(m *testing.M) {
@@ -89,14 +102,26 @@ func TestMain(m *testing.M) {
 	}
 
 	testRepo = repository.PostgresRepository{
-		&repository.AccountRepository{testDB},
-		&repository.TransferRepository{testDB},
+		AccountRepository:  &repository.AccountRepository{DB: testDB},
+		TransferRepository: &repository.TransferRepository{DB: testDB},
+		InterestRepository: &repository.InterestRepository{DB: testDB},
+		LedgerRepository:   &repository.LedgerRepository{DB: testDB},
+		CustomerRepository: &repository.CustomerRepository{DB: testDB},
+		WalletRepository:   &repository.WalletRepository{DB: testDB},
 	}
 
 	accountService = services.NewAccountService(testRepo.AccountRepository)
-	transferService = services.NewTransferService(testRepo.TransferRepository)
-	accountHandler = NewAccountHandler(*accountService)
-	transferHandler = NewTransferHandler(*transferService)
+	transferService = services.NewTransferService(testRepo.TransferRepository, testRepo.CustomerRepository, fixedRate, domain.ApprovalPolicy{})
+	ledgerService = services.NewLedgerService(testRepo.LedgerRepository, testRepo.AccountRepository)
+	customerService = services.NewCustomerService(testRepo.CustomerRepository, testRepo.AccountRepository)
+	walletService = services.NewWalletService(testRepo.WalletRepository, testRepo.AccountRepository, fixedRate)
+	// No action needs approval.
+	approvalService := services.NewApprovalService(nil, domain.ApprovalPolicy{}, accountService, transferService, nil)
+	accountHandler = NewAccountHandler(*accountService, *approvalService, config.Default().Handlers)
+	transferHandler = NewTransferHandler(*transferService, *accountService, *approvalService, config.Default().Handlers)
+	ledgerHandler = NewLedgerHandler(*ledgerService)
+	customerHandler = NewCustomerHandler(*customerService)
+	walletHandler = NewWalletHandler(*walletService)
 
 	code := m.Run()
 
@@ -107,6 +132,10 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
+func fixedRate(from, to string, amount decimal.Decimal) (decimal.Decimal, error) {
+	return amount.Mul(decimal.NewFromInt(2)), nil
+}
+
 func createTables() error {
 	tableSQL, err := os.ReadFile("./testdata/init_schema.sql")
 	if err != nil {
@@ -148,8 +177,13 @@ func Test_AccountHandlers(t *testing.T) {
 			accountHandler.CreateAccount,
 			http.StatusCreated,
 		},
+		{"createAccount-InvalidCurrency", "POST", `{"balance": 150000,"currency": "EURO"}`, "", accountHandler.CreateAccount, http.StatusUnprocessableEntity},
+		{"createAccount-InvalidPrecision", "POST", `{"balance": 1500.001,"currency": "EUR"}`, "", accountHandler.CreateAccount, http.StatusUnprocessableEntity},
+		{"createAccount-NegativeBalance", "POST", `{"balance": -1,"currency": "EUR"}`, "", accountHandler.CreateAccount, http.StatusUnprocessableEntity},
+		{"createAccount-MissingCurrency", "POST", `{"balance": 100}`, "", accountHandler.CreateAccount, http.StatusUnprocessableEntity},
+		{"createAccount-UnknownField", "POST", `{"balance": 100,"currency": "EUR","owner": "jane"}`, "", accountHandler.CreateAccount, http.StatusBadRequest},
 		{"getAccount", "GET", "", "604f02b2-4e45-48d6-a952-03a0136e8140", accountHandler.GetAccount, http.StatusOK},
-		{"getAccount-Invalid", "", "GET", "121f03cd-ce8c-447d-8747-fb8cb7aa3a52", accountHandler.GetAccount, http.StatusMethodNotAllowed},
+		{"getAccount-Invalid", "", "GET", "121f03cd-ce8c-447d-8747-fb8cb7aa3a52", accountHandler.GetAccount, http.StatusNotFound},
 		{
 			"updateAccount",
 			"PATCH",
@@ -167,7 +201,52 @@ func Test_AccountHandlers(t *testing.T) {
 			transferHandler.CreateTransfer,
 			http.StatusCreated,
 		},
+		{
+			"createTransfer-ZeroAmount",
+			"POST",
+			`{"source_account_id": "8fa6c93b-f300-4ef8-9bac-4258caea36db","target_account_id": "604f02b2-4e45-48d6-a952-03a0136e8140","amount": 0}`,
+			"",
+			transferHandler.CreateTransfer,
+			http.StatusUnprocessableEntity,
+		},
 		{"getAllTransfers", "GET", "", "", transferHandler.GetAllTransfers, http.StatusOK},
+		{"getStatement", "GET", "", "604f02b2-4e45-48d6-a952-03a0136e8140", ledgerHandler.GetStatement, http.StatusOK},
+		{
+			"createCustomer",
+			"POST",
+			`{"name": "Jane Doe","email": "jane@example.com","type": "individual"}`,
+			"",
+			customerHandler.CreateCustomer,
+			http.StatusCreated,
+		},
+		{
+			"createCustomer-Invalid",
+			"POST",
+			`{"name": "Jane Doe","email": "jane@example.com","type": "partnership"}`,
+			"",
+			customerHandler.CreateCustomer,
+			http.StatusUnprocessableEntity,
+		},
+		{"getAllCustomers", "GET", "", "", customerHandler.GetAllCustomers, http.StatusOK},
+		{"openPocket", "POST", `{"currency": "USD"}`, "ed989ca2-bc1b-413c-8698-d3d9dfa74800", walletHandler.OpenPocket, http.StatusCreated},
+		{"openPocket-Duplicate", "POST", `{"currency": "USD"}`, "ed989ca2-bc1b-413c-8698-d3d9dfa74800", walletHandler.OpenPocket, http.StatusConflict},
+		{
+			"convert",
+			"POST",
+			`{"from_currency": "EUR","to_currency": "USD","amount": 1000}`,
+			"ed989ca2-bc1b-413c-8698-d3d9dfa74800",
+			walletHandler.Convert,
+			http.StatusOK,
+		},
+		{
+			"convert-MissingPocket",
+			"POST",
+			`{"from_currency": "EUR","to_currency": "GBP","amount": 1000}`,
+			"ed989ca2-bc1b-413c-8698-d3d9dfa74800",
+			walletHandler.Convert,
+			http.StatusUnprocessableEntity,
+		},
+		{"getPockets", "GET", "", "ed989ca2-bc1b-413c-8698-d3d9dfa74800", walletHandler.GetPockets, http.StatusOK},
 	}
 
 	for _, tt := range testCases {
@@ -183,6 +262,9 @@ func Test_AccountHandlers(t *testing.T) {
 			chiCtx.URLParams.Add("id", tt.paramID)
 			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
 		}
+		if tt.method == "PATCH" || tt.method == "DELETE" {
+			req.Header.Set("If-Match", "*")
+		}
 
 		rr := httptest.NewRecorder()
 		handler := http.HandlerFunc(tt.handler)
@@ -193,3 +275,68 @@ func Test_AccountHandlers(t *testing.T) {
 		}
 	}
 }
+
+func Test_AccountPreconditions(t *testing.T) {
+	const id = "604f02b2-4e45-48d6-a952-03a0136e8140"
+
+	testCases := []struct {
+		name               string
+		ifMatch            string
+		expectedStatusCode int
+	}{
+		{"missing", "", http.StatusPreconditionRequired},
+		{"stale", `"999999"`, http.StatusPreconditionFailed},
+		{"weak", `W/"1"`, http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range testCases {
+		req, _ := http.NewRequest("PATCH", "/", strings.NewReader(`{"balance": 100}`))
+		chiCtx := chi.NewRouteContext()
+		chiCtx.URLParams.Add("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+
+		rr := httptest.NewRecorder()
+		accountHandler.UpdateAccount(rr, req)
+
+		if rr.Code != tt.expectedStatusCode {
+			t.Errorf("%s: wrong status returned; expected %d but got %d", tt.name, tt.expectedStatusCode, rr.Code)
+		}
+	}
+}
+
+func Test_ErrorResponse(t *testing.T) {
+	testCases := []struct {
+		err            error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{repository.ErrRecordNotFound, http.StatusNotFound, "not_found"},
+		{utils.ErrInsufficientBalance, http.StatusUnprocessableEntity, "insufficient_balance"},
+		{utils.ErrIdenticalAccount, http.StatusUnprocessableEntity, "identical_account"},
+		{utils.ErrCurrencyConvertion, http.StatusBadGateway, "currency_conversion_failed"},
+		{fmt.Errorf("%w: %q", currency.ErrUnknownCurrency, "EURO"), http.StatusUnprocessableEntity, "unknown_currency"},
+		{errors.New("connection refused"), http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, tt := range testCases {
+		req, _ := http.NewRequest("GET", "/", nil)
+		rr := httptest.NewRecorder()
+
+		errorResponse(rr, req, tt.err)
+
+		var problem utils.Problem
+		if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+			t.Fatal(err)
+		}
+
+		if rr.Code != tt.expectedStatus || problem.Status != tt.expectedStatus || problem.Code != tt.expectedCode {
+			t.Errorf("%v: expected %d %s but got %d %s", tt.err, tt.expectedStatus, tt.expectedCode, rr.Code, problem.Code)
+		}
+		if rr.Header().Get("Content-Type") != "application/problem+json" {
+			t.Errorf("%v: wrong content type %q", tt.err, rr.Header().Get("Content-Type"))
+		}
+	}
+}
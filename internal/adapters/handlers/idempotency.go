@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+// IdempotencyKeys lets clients retry a POST request whose outcome they did
+// not learn. The response to a request carrying an Idempotency-Key header is
+// kept for the ttl of the service, and a request that repeats the key gets
+// that response again instead of being processed twice. Responses are kept
+// in the database, so a key is honoured by every instance.
+type IdempotencyKeys struct {
+	service services.IdempotencyService
+}
+
+func NewIdempotencyKeys(idempotencyService services.IdempotencyService) *IdempotencyKeys {
+	return &IdempotencyKeys{
+		idempotencyService,
+	}
+}
+
+// Middleware replays the response to POST requests whose Idempotency-Key has
+// been seen before. The key is reserved before the request is processed, so
+// reusing it for a different request, or before the first request has
+// finished, is refused. Server errors are not kept, so the request can be
+// retried with the same key.
+func (k *IdempotencyKeys) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > 255 {
+			errorResponse(w, r, utils.ErrInvalidIdempotency)
+			return
+		}
+		// Keys are chosen by the clients, so they only have to be unique
+		// per caller.
+		if name := caller(r); name != "" {
+			key = name + "\n" + key
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1_048_577))
+		if err != nil {
+			errorResponse(w, r, utils.ErrBadJSON)
+			return
+		}
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		fingerprint := sha256.Sum256(append([]byte(r.Method+" "+r.URL.RequestURI()+"\n"), body...))
+
+		saved, err := k.service.Reserve(r.Context(), key, fingerprint[:])
+		switch {
+		case err != nil:
+			errorResponse(w, r, err)
+			return
+		case saved != nil && !bytes.Equal(saved.Fingerprint, fingerprint[:]):
+			errorResponse(w, r, utils.ErrIdempotencyReused)
+			return
+		case saved != nil && !saved.Done:
+			errorResponse(w, r, utils.ErrIdempotencyInUse)
+			return
+		case saved != nil:
+			for name, values := range saved.Header {
+				w.Header()[name] = values
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(saved.Status)
+			w.Write(saved.Body)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		defer func() {
+			// The response is kept whether or not the client is still
+			// waiting for it.
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if !completed || rec.status >= http.StatusInternalServerError {
+				if err := k.service.Release(ctx, key); err != nil {
+					utils.LogError(fmt.Errorf("releasing idempotency key: %w", err))
+				}
+				return
+			}
+			if err := k.service.Complete(ctx, key, rec.status, w.Header().Clone(), rec.body.Bytes()); err != nil {
+				utils.LogError(fmt.Errorf("keeping the response to an idempotent request: %w", err))
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+		completed = true
+	})
+}
+
+// responseRecorder passes a response on while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+	"github.com/petrostrak/agile-transfer/utils"
+	"github.com/shopspring/decimal"
+)
+
+type InterestHandler struct {
+	service services.InterestService
+}
+
+func NewInterestHandler(interestService services.InterestService) *InterestHandler {
+	return &InterestHandler{
+		interestService,
+	}
+}
+
+func (i *InterestHandler) GetInterestConfig(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	cfg, err := i.service.GetConfig(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"interest": responseFormat(r).interestConfig(cfg)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (i *InterestHandler) SetInterestConfig(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		AnnualRate       decimal.Decimal           `json:"annual_rate"`
+		DayCount         domain.DayCountConvention `json:"day_count"`
+		Compounding      domain.Compounding        `json:"compounding"`
+		PostingFrequency domain.PostingFrequency   `json:"posting_frequency"`
+		Enabled          *bool                     `json:"enabled"`
+	}
+
+	err = utils.ReadJSON(w, r, &input)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	cfg := &domain.InterestConfig{
+		AccountID:        id,
+		AnnualRate:       input.AnnualRate,
+		DayCount:         input.DayCount,
+		Compounding:      input.Compounding,
+		PostingFrequency: input.PostingFrequency,
+		Enabled:          true,
+	}
+	if cfg.DayCount == "" {
+		cfg.DayCount = domain.DayCountActual365
+	}
+	if cfg.Compounding == "" {
+		cfg.Compounding = domain.CompoundingSimple
+	}
+	if cfg.PostingFrequency == "" {
+		cfg.PostingFrequency = domain.PostingMonthly
+	}
+	if input.Enabled != nil {
+		cfg.Enabled = *input.Enabled
+	}
+
+	v := validator.New()
+	if domain.ValidateInterestConfig(v, cfg); !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	previous, err := i.service.GetConfig(r.Context(), id)
+	if err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = i.service.SetConfig(r.Context(), cfg)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditInterestSet, "accounts/"+id.String()+"/interest", snapshot(previous), snapshot(cfg))
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"interest": responseFormat(r).interestConfig(cfg)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (i *InterestHandler) GetAccruals(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	accruals, err := i.service.GetAccruals(r.Context(), id)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"accruals": responseFormat(r).interestAccruals(accruals)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// RunInterest runs the daily interest job for the given date, yesterday by
+// default. It is safe to call again for a day that has already been processed.
+func (i *InterestHandler) RunInterest(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Minute)
+	defer cancel()
+
+	day, err := utils.ReadDateQuery(r, "date", time.Now().UTC().AddDate(0, 0, -1))
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	run, err := i.service.Run(ctx, day)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditInterestRun, "interest/"+run.Date.Format(time.DateOnly), nil, snapshot(run))
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"run": responseFormat(r).interestRun(&run)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
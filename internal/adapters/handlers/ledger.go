@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/utils"
+	"github.com/shopspring/decimal"
+)
+
+type LedgerHandler struct {
+	service services.LedgerService
+}
+
+func NewLedgerHandler(ledgerService services.LedgerService) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerService,
+	}
+}
+
+// GetStatement returns the statement of an account pocket between the from
+// and to dates, both inclusive, defaulting to the previous calendar month and
+// the base pocket of the account.
+func (l *LedgerHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	from, err := utils.ReadDateQuery(r, "from", firstOfMonth.AddDate(0, -1, 0))
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	to, err := utils.ReadDateQuery(r, "to", firstOfMonth.AddDate(0, 0, -1))
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" && format != "text" {
+		errorResponse(w, r, utils.ErrInvalidFormat)
+		return
+	}
+
+	pocket := currency.Normalize(r.URL.Query().Get("currency"))
+
+	statement, err := l.service.Statement(r.Context(), id, pocket, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	switch format {
+	case "csv":
+		err = writeStatementCSV(w, statement)
+	case "text":
+		err = writeStatementText(w, statement)
+	default:
+		err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"statement": responseFormat(r).statement(statement)}, nil)
+	}
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func writeStatementCSV(w http.ResponseWriter, statement *domain.Statement) error {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", statementFilename(statement, "csv")))
+
+	cw := csv.NewWriter(w)
+	records := [][]string{
+		{"date", "description", "transfer_id", "counterparty_id", "amount", "balance"},
+		{statement.From.Format(time.RFC3339), "opening balance", "", "", "", statement.OpeningBalance.String()},
+	}
+	for _, line := range statement.Lines {
+		records = append(records, []string{
+			line.CreatedAt.Format(time.RFC3339),
+			string(line.Kind),
+			optionalID(line.TransferID),
+			optionalID(line.CounterpartyID),
+			line.Amount.String(),
+			line.Balance.String(),
+		})
+	}
+	records = append(records, []string{statement.To.Format(time.RFC3339), "closing balance", "", "", "", statement.ClosingBalance.String()})
+
+	return cw.WriteAll(records)
+}
+
+func writeStatementText(w http.ResponseWriter, statement *domain.Statement) error {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	amount := func(d decimal.Decimal) string {
+		return domain.Money{Amount: d, Currency: statement.Currency}.AmountString()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "STATEMENT OF ACCOUNT\n\n")
+	fmt.Fprintf(w, "Account:  %s\n", statement.Account.ID)
+	fmt.Fprintf(w, "Currency: %s\n", statement.Currency)
+	fmt.Fprintf(w, "Period:   %s - %s\n\n", utils.HumanDate(statement.From), utils.HumanDate(statement.To.Add(-time.Minute)))
+
+	fmt.Fprintf(tw, "Date\tDescription\tCounterparty\tAmount\tBalance\t\n")
+	fmt.Fprintf(tw, "%s\t%s\t\t\t%s\t\n", utils.HumanDate(statement.From), "Opening balance", amount(statement.OpeningBalance))
+	for _, line := range statement.Lines {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\n",
+			utils.HumanDate(line.CreatedAt),
+			line.Kind,
+			optionalID(line.CounterpartyID),
+			amount(line.Amount),
+			amount(line.Balance),
+		)
+	}
+	fmt.Fprintf(tw, "%s\t%s\t\t\t%s\t\n", utils.HumanDate(statement.To.Add(-time.Minute)), "Closing balance", amount(statement.ClosingBalance))
+
+	return tw.Flush()
+}
+
+func statementFilename(statement *domain.Statement, ext string) string {
+	return fmt.Sprintf("statement-%s-%s-%s.%s",
+		statement.Account.ID,
+		statement.From.Format(time.DateOnly),
+		statement.To.AddDate(0, 0, -1).Format(time.DateOnly),
+		ext,
+	)
+}
+
+func optionalID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// GetBalance returns the balance of an account pocket at the as_of timestamp,
+// now by default. The currency parameter selects the pocket.
+func (l *LedgerHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	asOf, err := utils.ReadTimeQuery(r, "as_of", time.Now())
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	pocket := currency.Normalize(r.URL.Query().Get("currency"))
+
+	balance, err := l.service.BalanceAsOf(r.Context(), id, pocket, asOf)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"balance": responseFormat(r).balance(balance)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// GetBalances returns the balance of every account pocket at the as_of timestamp,
+// now by default.
+func (l *LedgerHandler) GetBalances(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	asOf, err := utils.ReadTimeQuery(r, "as_of", time.Now())
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	balances, err := l.service.BalancesAsOf(ctx, asOf)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"balances": responseFormat(r).balances(balances)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+// etag is the strong entity tag of a resource at the given version.
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// checkIfMatch requires the request to carry an If-Match header that matches
+// the resource at version, either by one of its entity tags or with "*".
+// Weak tags never match, as RFC 9110 asks of If-Match.
+func checkIfMatch(r *http.Request, version int) error {
+	header := r.Header.Values("If-Match")
+	if len(header) == 0 {
+		return utils.ErrPreconditionMissing
+	}
+
+	current := etag(version)
+	for _, line := range header {
+		for _, tag := range strings.Split(line, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || tag == current {
+				return nil
+			}
+		}
+	}
+	return utils.ErrPreconditionFailed
+}
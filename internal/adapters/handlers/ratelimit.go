@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+type RateLimiter struct {
+	service services.RateLimitService
+}
+
+func NewRateLimiter(rateLimitService services.RateLimitService) *RateLimiter {
+	return &RateLimiter{
+		rateLimitService,
+	}
+}
+
+// Limit refuses the requests over the limits of route with 429 Too Many
+// Requests and a Retry-After header. Every response of the route carries the
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy
+// headers of the bucket with the fewest requests left. When the buckets
+// cannot be read the request is let through. It must run after
+// Authenticate.
+func (l *RateLimiter) Limit(route domain.RouteLimit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accountID, _ := sourceAccount(r)
+			tightest, err := l.service.Limit(r.Context(), route, caller(r), accountID)
+			if err != nil {
+				utils.LogError(err)
+			}
+			if tightest == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(tightest.Limit.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(tightest.Remaining))
+			header.Set("RateLimit-Reset", seconds(tightest.Reset))
+			header.Set("RateLimit-Policy", tightest.Limit.Policy())
+			if !tightest.Allowed {
+				header.Set("Retry-After", seconds(tightest.RetryAfter))
+				errorResponse(w, r, utils.ErrRateLimited)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// seconds rounds d up to whole seconds, as the headers give them.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// sourceAccount is the account the request takes money from or changes: the
+// {id} of the path or, failing that, the source_account_id of the body. The
+// body is put back for the handler.
+func sourceAccount(r *http.Request) (uuid.UUID, bool) {
+	if id, err := uuid.Parse(chi.URLParam(r, "id")); err == nil {
+		return id, true
+	}
+	if r.Body == nil {
+		return uuid.UUID{}, false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1_048_577))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	if err != nil {
+		return uuid.UUID{}, false
+	}
+
+	var input struct {
+		SourceAccountID uuid.UUID `json:"source_account_id"`
+	}
+	if json.Unmarshal(body, &input) != nil || input.SourceAccountID == uuid.Nil {
+		return uuid.UUID{}, false
+	}
+	return input.SourceAccountID, true
+}
@@ -0,0 +1,651 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/utils"
+	"github.com/shopspring/decimal"
+)
+
+// The responses are built from the types below rather than from the domain
+// types, so that the API keeps its shape when the domain changes. Amounts are
+// strings with the decimals of their currency, and timestamps are RFC 3339 in
+// UTC unless the request asks for the human-readable form with
+// ?timestamps=human or "Accept: application/json; timestamps=human".
+
+type timestampsKey struct{}
+
+// Timestamps reads the timestamps format the request asks for, from the
+// timestamps query parameter or else the parameter of the same name of the
+// Accept header, and refuses unknown formats.
+func Timestamps(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested := r.URL.Query().Get("timestamps")
+		if requested == "" {
+			requested = acceptParam(r, "timestamps")
+		}
+
+		switch requested {
+		case "":
+		case "rfc3339", "human":
+			r = r.WithContext(context.WithValue(r.Context(), timestampsKey{}, requested))
+		default:
+			errorResponse(w, r, utils.ErrInvalidTimestamps)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// acceptParam is the value of the named parameter of the first JSON media
+// range in the Accept header.
+func acceptParam(r *http.Request, name string) string {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+			switch mediaType {
+			case "application/json", "application/*", "*/*":
+				return params[name]
+			}
+		}
+	}
+	return ""
+}
+
+// format is how a response renders its values.
+type format struct {
+	human bool
+	// humanAccounts renders the created_at of accounts, but no other
+	// timestamp, human-readable.
+	humanAccounts bool
+}
+
+// responseFormat is the format the request asked for, RFC 3339 timestamps by
+// default.
+func responseFormat(r *http.Request) format {
+	requested, _ := r.Context().Value(timestampsKey{}).(string)
+	return format{human: requested == "human"}
+}
+
+// accountReadFormat is responseFormat for GET /accounts and GET
+// /accounts/{id}, which version 1 was released with giving the created_at of
+// accounts human-readable. It stays so in version 1 unless a format is asked
+// for.
+func accountReadFormat(r *http.Request) format {
+	requested, _ := r.Context().Value(timestampsKey{}).(string)
+	if requested == "" && APIVersion(r) == V1 {
+		return format{humanAccounts: true}
+	}
+	return format{human: requested == "human"}
+}
+
+func (f format) time(t time.Time) string {
+	if f.human {
+		return utils.HumanDate(t.UTC())
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// amount renders an amount with the decimals of its currency.
+func amount(d decimal.Decimal, currency string) string {
+	return domain.NewMoney(d, currency).AmountString()
+}
+
+type accountResponse struct {
+	ID         uuid.UUID        `json:"id"`
+	CustomerID *uuid.UUID       `json:"customer_id"`
+	Balance    string           `json:"balance"`
+	Currency   string           `json:"currency"`
+	Frozen     bool             `json:"frozen"`
+	CreatedAt  string           `json:"created_at"`
+	Pockets    []pocketResponse `json:"pockets"`
+	Version    int              `json:"version"`
+}
+
+func (f format) account(account *domain.Account) accountResponse {
+	resp := accountResponse{
+		ID:         account.ID,
+		CustomerID: account.CustomerID,
+		Balance:    amount(account.Balance, account.Currency),
+		Currency:   account.Currency,
+		Frozen:     account.Frozen,
+		CreatedAt:  f.time(account.CreatedAt),
+		Pockets:    []pocketResponse{},
+		Version:    account.Version,
+	}
+	if f.humanAccounts {
+		resp.CreatedAt = utils.HumanDate(account.CreatedAt.UTC())
+	}
+	for i := range account.Pockets {
+		resp.Pockets = append(resp.Pockets, f.pocket(&account.Pockets[i]))
+	}
+	return resp
+}
+
+func (f format) accounts(accounts []domain.Account) []accountResponse {
+	resp := make([]accountResponse, 0, len(accounts))
+	for i := range accounts {
+		resp = append(resp, f.account(&accounts[i]))
+	}
+	return resp
+}
+
+type pocketResponse struct {
+	Currency  string `json:"currency"`
+	Balance   string `json:"balance"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (f format) pocket(pocket *domain.Pocket) pocketResponse {
+	return pocketResponse{
+		Currency:  pocket.Currency,
+		Balance:   amount(pocket.Balance, pocket.Currency),
+		CreatedAt: f.time(pocket.CreatedAt),
+	}
+}
+
+func (f format) pockets(pockets []domain.Pocket) []pocketResponse {
+	resp := make([]pocketResponse, 0, len(pockets))
+	for i := range pockets {
+		resp = append(resp, f.pocket(&pockets[i]))
+	}
+	return resp
+}
+
+type moneyResponse struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func money(m domain.Money) moneyResponse {
+	return moneyResponse{Amount: m.AmountString(), Currency: m.Currency}
+}
+
+type conversionResponse struct {
+	AccountID uuid.UUID      `json:"account_id"`
+	Amount    moneyResponse  `json:"amount"`
+	Converted moneyResponse  `json:"converted"`
+	From      pocketResponse `json:"from"`
+	To        pocketResponse `json:"to"`
+}
+
+func (f format) conversion(conversion *domain.Conversion) conversionResponse {
+	return conversionResponse{
+		AccountID: conversion.AccountID,
+		Amount:    money(conversion.Amount),
+		Converted: money(conversion.Converted),
+		From:      f.pocket(&conversion.From),
+		To:        f.pocket(&conversion.To),
+	}
+}
+
+type transferResponse struct {
+	ID              uuid.UUID     `json:"id"`
+	SourceAccountID uuid.UUID     `json:"source_account_id"`
+	TargetAccountID uuid.UUID     `json:"target_account_id"`
+	Amount          moneyResponse `json:"amount"`
+	Credited        moneyResponse `json:"credited"`
+	CreatedAt       string        `json:"created_at"`
+}
+
+func (f format) transfer(tx *domain.Transfer) transferResponse {
+	return transferResponse{
+		ID:              tx.ID,
+		SourceAccountID: tx.SourceAccountID,
+		TargetAccountID: tx.TargetAccountID,
+		Amount:          money(tx.Amount),
+		Credited:        money(tx.Credited),
+		CreatedAt:       f.time(tx.CreatedAt),
+	}
+}
+
+func (f format) transfers(transfers []domain.Transfer) []transferResponse {
+	resp := make([]transferResponse, 0, len(transfers))
+	for i := range transfers {
+		resp = append(resp, f.transfer(&transfers[i]))
+	}
+	return resp
+}
+
+type transferResultResponse struct {
+	Transfer      transferResponse `json:"transfer"`
+	SourceAccount accountResponse  `json:"source_account"`
+	TargetAccount accountResponse  `json:"target_account"`
+}
+
+func (f format) transferResult(result *domain.TransferTxResult) transferResultResponse {
+	return transferResultResponse{
+		Transfer:      f.transfer(&result.Transfer),
+		SourceAccount: f.account(&result.SourceAccount),
+		TargetAccount: f.account(&result.TargetAccount),
+	}
+}
+
+type customerResponse struct {
+	ID                uuid.UUID             `json:"id"`
+	Name              string                `json:"name"`
+	Email             string                `json:"email"`
+	Type              domain.CustomerType   `json:"type"`
+	Status            domain.CustomerStatus `json:"status"`
+	RestrictTransfers bool                  `json:"restrict_transfers"`
+	Subject           *string               `json:"subject"`
+	CreatedAt         string                `json:"created_at"`
+	UpdatedAt         string                `json:"updated_at"`
+}
+
+func (f format) customer(customer *domain.Customer) customerResponse {
+	return customerResponse{
+		ID:                customer.ID,
+		Name:              customer.Name,
+		Email:             customer.Email,
+		Type:              customer.Type,
+		Status:            customer.Status,
+		RestrictTransfers: customer.RestrictTransfers,
+		Subject:           customer.Subject,
+		CreatedAt:         f.time(customer.CreatedAt),
+		UpdatedAt:         f.time(customer.UpdatedAt),
+	}
+}
+
+func (f format) customers(customers []domain.Customer) []customerResponse {
+	resp := make([]customerResponse, 0, len(customers))
+	for i := range customers {
+		resp = append(resp, f.customer(&customers[i]))
+	}
+	return resp
+}
+
+type currencyBalanceResponse struct {
+	Currency string `json:"currency"`
+	Balance  string `json:"balance"`
+	Accounts int    `json:"accounts"`
+}
+
+func (f format) currencyBalances(balances []domain.CurrencyBalance) []currencyBalanceResponse {
+	resp := make([]currencyBalanceResponse, 0, len(balances))
+	for _, balance := range balances {
+		resp = append(resp, currencyBalanceResponse{
+			Currency: balance.Currency,
+			Balance:  amount(balance.Balance, balance.Currency),
+			Accounts: balance.Accounts,
+		})
+	}
+	return resp
+}
+
+type beneficiaryResponse struct {
+	CustomerID uuid.UUID `json:"customer_id"`
+	AccountID  uuid.UUID `json:"account_id"`
+	CreatedAt  string    `json:"created_at"`
+}
+
+func (f format) beneficiary(beneficiary *domain.Beneficiary) beneficiaryResponse {
+	return beneficiaryResponse{
+		CustomerID: beneficiary.CustomerID,
+		AccountID:  beneficiary.AccountID,
+		CreatedAt:  f.time(beneficiary.CreatedAt),
+	}
+}
+
+func (f format) beneficiaries(beneficiaries []domain.Beneficiary) []beneficiaryResponse {
+	resp := make([]beneficiaryResponse, 0, len(beneficiaries))
+	for i := range beneficiaries {
+		resp = append(resp, f.beneficiary(&beneficiaries[i]))
+	}
+	return resp
+}
+
+type interestConfigResponse struct {
+	AccountID        uuid.UUID                 `json:"account_id"`
+	AnnualRate       string                    `json:"annual_rate"`
+	DayCount         domain.DayCountConvention `json:"day_count"`
+	Compounding      domain.Compounding        `json:"compounding"`
+	PostingFrequency domain.PostingFrequency   `json:"posting_frequency"`
+	Enabled          bool                      `json:"enabled"`
+	CreatedAt        string                    `json:"created_at"`
+	UpdatedAt        string                    `json:"updated_at"`
+}
+
+func (f format) interestConfig(cfg *domain.InterestConfig) interestConfigResponse {
+	return interestConfigResponse{
+		AccountID:        cfg.AccountID,
+		AnnualRate:       cfg.AnnualRate.String(),
+		DayCount:         cfg.DayCount,
+		Compounding:      cfg.Compounding,
+		PostingFrequency: cfg.PostingFrequency,
+		Enabled:          cfg.Enabled,
+		CreatedAt:        f.time(cfg.CreatedAt),
+		UpdatedAt:        f.time(cfg.UpdatedAt),
+	}
+}
+
+// interestAccrualResponse gives the amounts in full: interest accrues in
+// fractions of the minor unit, and is only rounded when it is posted.
+type interestAccrualResponse struct {
+	ID          uuid.UUID `json:"id"`
+	AccountID   uuid.UUID `json:"account_id"`
+	AccrualDate string    `json:"accrual_date"`
+	Balance     string    `json:"balance"`
+	AnnualRate  string    `json:"annual_rate"`
+	Amount      string    `json:"amount"`
+	CreatedAt   string    `json:"created_at"`
+}
+
+func (f format) interestAccruals(accruals []domain.InterestAccrual) []interestAccrualResponse {
+	resp := make([]interestAccrualResponse, 0, len(accruals))
+	for _, accrual := range accruals {
+		resp = append(resp, interestAccrualResponse{
+			ID:          accrual.ID,
+			AccountID:   accrual.AccountID,
+			AccrualDate: f.time(accrual.AccrualDate),
+			Balance:     accrual.Balance.String(),
+			AnnualRate:  accrual.AnnualRate.String(),
+			Amount:      accrual.Amount.String(),
+			CreatedAt:   f.time(accrual.CreatedAt),
+		})
+	}
+	return resp
+}
+
+type interestRunResponse struct {
+	Date     string   `json:"date"`
+	Accrued  int      `json:"accrued"`
+	Posted   int      `json:"posted"`
+	Failures []string `json:"failures"`
+}
+
+func (f format) interestRun(run *domain.InterestRun) interestRunResponse {
+	failures := run.Failures
+	if failures == nil {
+		failures = []string{}
+	}
+	return interestRunResponse{
+		Date:     f.time(run.Date),
+		Accrued:  run.Accrued,
+		Posted:   run.Posted,
+		Failures: failures,
+	}
+}
+
+type statementResponse struct {
+	Account        accountResponse         `json:"account"`
+	Currency       string                  `json:"currency"`
+	From           string                  `json:"from"`
+	To             string                  `json:"to"`
+	OpeningBalance string                  `json:"opening_balance"`
+	ClosingBalance string                  `json:"closing_balance"`
+	Lines          []statementLineResponse `json:"lines"`
+}
+
+type statementLineResponse struct {
+	ID             uuid.UUID        `json:"id"`
+	AccountID      uuid.UUID        `json:"account_id"`
+	Currency       string           `json:"currency"`
+	TransferID     *uuid.UUID       `json:"transfer_id,omitempty"`
+	CounterpartyID *uuid.UUID       `json:"counterparty_id,omitempty"`
+	Kind           domain.EntryKind `json:"kind"`
+	Amount         string           `json:"amount"`
+	Balance        string           `json:"balance"`
+	CreatedAt      string           `json:"created_at"`
+}
+
+func (f format) statement(statement *domain.Statement) statementResponse {
+	resp := statementResponse{
+		Account:        f.account(&statement.Account),
+		Currency:       statement.Currency,
+		From:           f.time(statement.From),
+		To:             f.time(statement.To),
+		OpeningBalance: amount(statement.OpeningBalance, statement.Currency),
+		ClosingBalance: amount(statement.ClosingBalance, statement.Currency),
+		Lines:          []statementLineResponse{},
+	}
+	for _, line := range statement.Lines {
+		resp.Lines = append(resp.Lines, statementLineResponse{
+			ID:             line.ID,
+			AccountID:      line.AccountID,
+			Currency:       line.Currency,
+			TransferID:     line.TransferID,
+			CounterpartyID: line.CounterpartyID,
+			Kind:           line.Kind,
+			Amount:         amount(line.Amount, line.Currency),
+			Balance:        amount(line.Balance, line.Currency),
+			CreatedAt:      f.time(line.CreatedAt),
+		})
+	}
+	return resp
+}
+
+type balanceResponse struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Currency  string    `json:"currency"`
+	Balance   string    `json:"balance"`
+	AsOf      string    `json:"as_of"`
+}
+
+func (f format) balance(balance *domain.Balance) balanceResponse {
+	return balanceResponse{
+		AccountID: balance.AccountID,
+		Currency:  balance.Currency,
+		Balance:   amount(balance.Balance, balance.Currency),
+		AsOf:      f.time(balance.AsOf),
+	}
+}
+
+func (f format) balances(balances []domain.Balance) []balanceResponse {
+	resp := make([]balanceResponse, 0, len(balances))
+	for i := range balances {
+		resp = append(resp, f.balance(&balances[i]))
+	}
+	return resp
+}
+
+type apiKeyResponse struct {
+	ID         uuid.UUID      `json:"id"`
+	Client     string         `json:"client"`
+	Prefix     string         `json:"prefix"`
+	Scopes     []domain.Scope `json:"scopes"`
+	CreatedAt  string         `json:"created_at"`
+	ExpiresAt  *string        `json:"expires_at"`
+	RevokedAt  *string        `json:"revoked_at"`
+	LastUsedAt *string        `json:"last_used_at"`
+}
+
+func (f format) apiKey(key *domain.APIKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         key.ID,
+		Client:     key.Client,
+		Prefix:     key.Prefix,
+		Scopes:     append([]domain.Scope{}, key.Scopes...),
+		CreatedAt:  f.time(key.CreatedAt),
+		ExpiresAt:  f.optionalTime(key.ExpiresAt),
+		RevokedAt:  f.optionalTime(key.RevokedAt),
+		LastUsedAt: f.optionalTime(key.LastUsedAt),
+	}
+}
+
+func (f format) apiKeys(keys []domain.APIKey) []apiKeyResponse {
+	resp := make([]apiKeyResponse, 0, len(keys))
+	for i := range keys {
+		resp = append(resp, f.apiKey(&keys[i]))
+	}
+	return resp
+}
+
+type signingSecretResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Client    string    `json:"client"`
+	CreatedAt string    `json:"created_at"`
+	ExpiresAt *string   `json:"expires_at"`
+	RevokedAt *string   `json:"revoked_at"`
+}
+
+func (f format) signingSecret(secret *domain.SigningSecret) signingSecretResponse {
+	return signingSecretResponse{
+		ID:        secret.ID,
+		Client:    secret.Client,
+		CreatedAt: f.time(secret.CreatedAt),
+		ExpiresAt: f.optionalTime(secret.ExpiresAt),
+		RevokedAt: f.optionalTime(secret.RevokedAt),
+	}
+}
+
+func (f format) signingSecrets(secrets []domain.SigningSecret) []signingSecretResponse {
+	resp := make([]signingSecretResponse, 0, len(secrets))
+	for i := range secrets {
+		resp = append(resp, f.signingSecret(&secrets[i]))
+	}
+	return resp
+}
+
+// optionalTime renders t like time, or as null when it is not set.
+func (f format) optionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := f.time(*t)
+	return &s
+}
+
+type roleResponse struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Permissions []domain.Permission `json:"permissions"`
+	CreatedAt   string              `json:"created_at"`
+	UpdatedAt   string              `json:"updated_at"`
+}
+
+func (f format) role(role *domain.Role) roleResponse {
+	return roleResponse{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: append([]domain.Permission{}, role.Permissions...),
+		CreatedAt:   f.time(role.CreatedAt),
+		UpdatedAt:   f.time(role.UpdatedAt),
+	}
+}
+
+func (f format) roles(roles []domain.Role) []roleResponse {
+	resp := make([]roleResponse, 0, len(roles))
+	for i := range roles {
+		resp = append(resp, f.role(&roles[i]))
+	}
+	return resp
+}
+
+type transferOrderResponse struct {
+	TargetAccountID uuid.UUID `json:"target_account_id"`
+	Amount          string    `json:"amount"`
+	SourceCurrency  string    `json:"source_currency"`
+	TargetCurrency  string    `json:"target_currency"`
+}
+
+type accountChangeResponse struct {
+	Version    int        `json:"version"`
+	Balance    string     `json:"balance"`
+	Currency   string     `json:"currency"`
+	CustomerID *uuid.UUID `json:"customer_id"`
+}
+
+type approvalResponse struct {
+	ID          uuid.UUID              `json:"id"`
+	Action      domain.ApprovalAction  `json:"action"`
+	Status      domain.ApprovalStatus  `json:"status"`
+	AccountID   uuid.UUID              `json:"account_id"`
+	Transfer    *transferOrderResponse `json:"transfer"`
+	Change      *accountChangeResponse `json:"change"`
+	RequestedBy string                 `json:"requested_by"`
+	DecidedBy   *string                `json:"decided_by"`
+	Reason      string                 `json:"reason"`
+	TransferID  *uuid.UUID             `json:"transfer_id"`
+	CreatedAt   string                 `json:"created_at"`
+	ExpiresAt   string                 `json:"expires_at"`
+	DecidedAt   *string                `json:"decided_at"`
+}
+
+func (f format) approval(approval *domain.Approval) approvalResponse {
+	resp := approvalResponse{
+		ID:          approval.ID,
+		Action:      approval.Action,
+		Status:      approval.Status,
+		AccountID:   approval.AccountID,
+		RequestedBy: approval.RequestedBy,
+		DecidedBy:   approval.DecidedBy,
+		Reason:      approval.Reason,
+		TransferID:  approval.TransferID,
+		CreatedAt:   f.time(approval.CreatedAt),
+		ExpiresAt:   f.time(approval.ExpiresAt),
+		DecidedAt:   f.optionalTime(approval.DecidedAt),
+	}
+	if order := approval.Transfer; order != nil {
+		resp.Transfer = &transferOrderResponse{
+			TargetAccountID: order.TargetAccountID,
+			Amount:          amount(order.Amount, order.SourceCurrency),
+			SourceCurrency:  order.SourceCurrency,
+			TargetCurrency:  order.TargetCurrency,
+		}
+	}
+	if change := approval.Change; change != nil {
+		resp.Change = &accountChangeResponse{
+			Version:    change.Version,
+			Balance:    amount(change.Balance, change.Currency),
+			Currency:   change.Currency,
+			CustomerID: change.CustomerID,
+		}
+	}
+	return resp
+}
+
+func (f format) approvals(approvals []domain.Approval) []approvalResponse {
+	resp := make([]approvalResponse, 0, len(approvals))
+	for i := range approvals {
+		resp = append(resp, f.approval(&approvals[i]))
+	}
+	return resp
+}
+
+type auditEntryResponse struct {
+	ID         int64              `json:"id"`
+	OccurredAt string             `json:"occurred_at"`
+	Actor      string             `json:"actor"`
+	Action     domain.AuditAction `json:"action"`
+	Resource   string             `json:"resource"`
+	RequestID  string             `json:"request_id"`
+	ClientIP   string             `json:"client_ip"`
+	Detail     string             `json:"detail"`
+	Before     json.RawMessage    `json:"before"`
+	After      json.RawMessage    `json:"after"`
+	PrevHash   string             `json:"prev_hash"`
+	Hash       string             `json:"hash"`
+}
+
+func (f format) auditEntries(entries []domain.AuditEntry) []auditEntryResponse {
+	resp := make([]auditEntryResponse, 0, len(entries))
+	for _, e := range entries {
+		resp = append(resp, auditEntryResponse{
+			ID:         e.ID,
+			OccurredAt: f.time(e.OccurredAt),
+			Actor:      e.Actor,
+			Action:     e.Action,
+			Resource:   e.Resource,
+			RequestID:  e.RequestID,
+			ClientIP:   e.ClientIP,
+			Detail:     e.Detail,
+			Before:     e.Before,
+			After:      e.After,
+			PrevHash:   e.PrevHash,
+			Hash:       e.Hash,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+type RoleHandler struct {
+	service services.AccessService
+}
+
+func NewRoleHandler(accessService services.AccessService) *RoleHandler {
+	return &RoleHandler{
+		accessService,
+	}
+}
+
+func (ro *RoleHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name        string              `json:"name"`
+		Description string              `json:"description"`
+		Permissions []domain.Permission `json:"permissions"`
+	}
+
+	err := utils.ReadJSON(w, r, &input)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	role := &domain.Role{
+		Name:        input.Name,
+		Description: input.Description,
+		Permissions: input.Permissions,
+	}
+
+	v := validator.New()
+	if domain.ValidateRole(v, role); !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = ro.service.Insert(r.Context(), role)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditRoleCreated, "roles/"+role.Name, nil, snapshot(role))
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("%s/roles/%s", APIVersion(r).Prefix(), role.Name))
+
+	err = utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"role": responseFormat(r).role(role)}, headers)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (ro *RoleHandler) GetRole(w http.ResponseWriter, r *http.Request) {
+	role, err := ro.service.Get(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"role": responseFormat(r).role(role)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (ro *RoleHandler) GetAllRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := ro.service.GetAll(r.Context())
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"roles": responseFormat(r).roles(roles)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// UpdateRole changes the description or permissions of a role. Users holding
+// it get the new permissions with their next request.
+func (ro *RoleHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	role, err := ro.service.Get(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		Description *string             `json:"description"`
+		Permissions []domain.Permission `json:"permissions"`
+	}
+
+	err = utils.ReadJSON(w, r, &input)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	original := snapshot(role)
+	if input.Description != nil {
+		role.Description = *input.Description
+	}
+	if input.Permissions != nil {
+		role.Permissions = input.Permissions
+	}
+
+	v := validator.New()
+	if domain.ValidateRole(v, role); !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = ro.service.Update(r.Context(), role)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditRoleUpdated, "roles/"+role.Name, original, snapshot(role))
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"role": responseFormat(r).role(role)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// DeleteRole removes a role. Users whose tokens still name it lose its
+// permissions.
+func (ro *RoleHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	role, err := ro.service.Get(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = ro.service.Delete(r.Context(), role.Name)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditRoleDeleted, "roles/"+role.Name, snapshot(role), nil)
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"message": "role successfully deleted"}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// GetPermissions returns the permissions matrix: every permission, the API
+// key scope that grants it and the roles that grant it.
+func (ro *RoleHandler) GetPermissions(w http.ResponseWriter, r *http.Request) {
+	matrix, err := ro.service.Matrix(r.Context())
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"permissions": matrix}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
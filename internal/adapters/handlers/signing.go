@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+type SigningHandler struct {
+	service services.SigningService
+}
+
+func NewSigningHandler(signingService services.SigningService) *SigningHandler {
+	return &SigningHandler{
+		signingService,
+	}
+}
+
+// Middleware checks the signature of the requests of API clients holding a
+// signing secret, which must sign every request. Requests authenticated with
+// a bearer token are not signed. It must run after Authenticate.
+func (s *SigningHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := APIClient(r)
+		if key == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = io.ReadAll(io.LimitReader(r.Body, 1_048_577))
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+			if err != nil {
+				errorResponse(w, r, err)
+				return
+			}
+		}
+
+		err := s.service.Verify(r.Context(), services.SignedRequest{
+			Client:    key.Client,
+			Method:    r.Method,
+			URI:       r.URL.RequestURI(),
+			Body:      body,
+			Signature: r.Header.Get(domain.SignatureHeader),
+			Timestamp: r.Header.Get(domain.SignatureTimestampHeader),
+			Nonce:     r.Header.Get(domain.SignatureNonceHeader),
+		})
+		if err != nil {
+			unauthenticated(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IssueSecret creates a signing secret for a client. The response is the
+// only time the secret is shown.
+func (s *SigningHandler) IssueSecret(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Client string `json:"client"`
+	}
+
+	err := utils.ReadJSON(w, r, &input)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	secret := &domain.SigningSecret{
+		Client: input.Client,
+	}
+
+	v := validator.New()
+	if domain.ValidateSigningSecret(v, secret); !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	plain, err := s.service.Issue(r.Context(), secret)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditSecretIssued, "signing-secrets/"+secret.ID.String(), nil, snapshot(secret))
+
+	s.writeSecret(w, r, secret, plain)
+}
+
+func (s *SigningHandler) GetSecret(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	secret, err := s.service.Get(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"signing_secret": responseFormat(r).signingSecret(secret)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (s *SigningHandler) GetAllSecrets(w http.ResponseWriter, r *http.Request) {
+	secrets, err := s.service.GetAll(r.Context())
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"signing_secrets": responseFormat(r).signingSecrets(secrets)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// RotateSecret issues a secret for the client of an existing one, which
+// keeps working for the overlap given, e.g. "1h", so that the client can
+// switch secrets without downtime.
+func (s *SigningHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		Overlap *string `json:"overlap"`
+	}
+
+	// The body is optional.
+	err = utils.ReadJSON(w, r, &input)
+	if err != nil && !errors.Is(err, utils.ErrEmptyBody) {
+		errorResponse(w, r, err)
+		return
+	}
+
+	overlap := defaultOverlap
+	v := validator.New()
+	if input.Overlap != nil {
+		overlap, err = time.ParseDuration(*input.Overlap)
+		v.Check(err == nil, "overlap", "must be a duration such as 30m or 24h")
+		v.Check(err != nil || (overlap >= 0 && overlap <= 30*24*time.Hour), "overlap", "must be between 0s and 720h")
+	}
+	if !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	secret, plain, err := s.service.Rotate(r.Context(), id, overlap)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	// The snapshot is of the new secret; the old one is named by the resource.
+	auditChange(r, domain.AuditSecretRotated, "signing-secrets/"+id.String(), nil, snapshot(secret))
+
+	s.writeSecret(w, r, secret, plain)
+}
+
+// RevokeSecret stops a secret from working at once.
+func (s *SigningHandler) RevokeSecret(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = s.service.Revoke(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditSecretRevoked, "signing-secrets/"+id.String(), nil, nil)
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"message": "signing secret successfully revoked"}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (s *SigningHandler) writeSecret(w http.ResponseWriter, r *http.Request, secret *domain.SigningSecret, plain string) {
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("%s/signing-secrets/%s", APIVersion(r).Prefix(), secret.ID))
+	headers.Set("Cache-Control", "no-store")
+
+	err := utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"signing_secret": responseFormat(r).signingSecret(secret), "secret": plain}, headers)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
@@ -19,6 +19,146 @@ ALTER TABLE "transfers" ADD FOREIGN KEY ("source_account_id") REFERENCES "accoun
 
 ALTER TABLE "transfers" ADD FOREIGN KEY ("target_account_id") REFERENCES "accounts" ("id");
 
+CREATE TABLE "interest_configs" (
+  "account_id" uuid NOT NULL,
+  "annual_rate" decimal NOT NULL,
+  "day_count" varchar NOT NULL DEFAULT 'ACT/365',
+  "compounding" varchar NOT NULL DEFAULT 'simple',
+  "posting_frequency" varchar NOT NULL DEFAULT 'monthly',
+  "enabled" boolean NOT NULL DEFAULT true,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "updated_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id")
+);
+
+CREATE TABLE "interest_accruals" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "account_id" uuid NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" decimal NOT NULL,
+  "annual_rate" decimal NOT NULL,
+  "amount" decimal NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("id"),
+  UNIQUE ("account_id", "accrual_date")
+);
+
+CREATE TABLE "interest_postings" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "account_id" uuid NOT NULL,
+  "posting_date" date NOT NULL,
+  "accrued_amount" decimal NOT NULL,
+  "posted_amount" decimal NOT NULL,
+  "transfer_id" uuid,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("id"),
+  UNIQUE ("account_id", "posting_date")
+);
+
+ALTER TABLE "interest_configs" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfers" ADD COLUMN "created_at" timestamp NOT NULL DEFAULT (now());
+
+CREATE TABLE "entries" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "seq" bigserial NOT NULL,
+  "account_id" uuid NOT NULL,
+  "transfer_id" uuid,
+  "kind" varchar NOT NULL,
+  "amount" decimal NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX ON "entries" ("account_id", "created_at", "seq");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE TABLE "balance_snapshots" (
+  "account_id" uuid NOT NULL,
+  "taken_at" timestamp NOT NULL,
+  "balance" decimal NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "taken_at")
+);
+
+ALTER TABLE "balance_snapshots" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+CREATE TABLE "customers" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "name" varchar NOT NULL,
+  "email" varchar NOT NULL UNIQUE,
+  "type" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "restrict_transfers" boolean NOT NULL DEFAULT false,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "updated_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("id")
+);
+
+CREATE TABLE "beneficiaries" (
+  "customer_id" uuid NOT NULL,
+  "account_id" uuid NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("customer_id", "account_id")
+);
+
+ALTER TABLE "accounts" ADD COLUMN "customer_id" uuid;
+
+CREATE INDEX ON "accounts" ("customer_id");
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("customer_id") REFERENCES "customers" ("id");
+
+ALTER TABLE "beneficiaries" ADD FOREIGN KEY ("customer_id") REFERENCES "customers" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "beneficiaries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+CREATE TABLE "pockets" (
+  "account_id" uuid NOT NULL,
+  "currency" varchar NOT NULL,
+  "balance" decimal NOT NULL DEFAULT 0,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "currency")
+);
+
+ALTER TABLE "pockets" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "entries" ADD COLUMN "currency" varchar;
+
+ALTER TABLE "entries" ALTER COLUMN "currency" SET NOT NULL;
+
+ALTER TABLE "balance_snapshots" ADD COLUMN "currency" varchar;
+
+ALTER TABLE "balance_snapshots" ALTER COLUMN "currency" SET NOT NULL;
+
+ALTER TABLE "balance_snapshots" DROP CONSTRAINT "balance_snapshots_pkey";
+
+ALTER TABLE "balance_snapshots" ADD PRIMARY KEY ("account_id", "currency", "taken_at");
+
+ALTER TABLE "transfers" ADD COLUMN "credited_amount" decimal;
+
+ALTER TABLE "transfers" ADD COLUMN "credited_currency" varchar;
+
+UPDATE "transfers" SET "credited_amount" = "amount", "credited_currency" = "currency";
+
+UPDATE "transfers" SET "amount" = -"entries"."amount", "currency" = "entries"."currency"
+FROM "entries"
+WHERE "entries"."transfer_id" = "transfers"."id"
+  AND "entries"."account_id" = "transfers"."source_account_id"
+  AND "entries"."amount" < 0;
+
+ALTER TABLE "transfers" ALTER COLUMN "credited_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ALTER COLUMN "credited_currency" SET NOT NULL;
+
 INSERT INTO accounts (id, balance, currency)
 		VALUES ('604f02b2-4e45-48d6-a952-03a0136e8140', 350000, 'EUR');
 
@@ -34,8 +174,129 @@ INSERT INTO accounts (id, balance, currency)
 INSERT INTO accounts (id, balance, currency)
 		VALUES ('71376d61-8b6c-4289-b5c4-79cb36add23f', 120000, 'EUR');
 
-INSERT INTO transfers (source_account_id, target_account_id, amount, currency)
-		VALUES ('8fa6c93b-f300-4ef8-9bac-4258caea36db', '604f02b2-4e45-48d6-a952-03a0136e8140', 50000, 'EUR');
+INSERT INTO transfers (source_account_id, target_account_id, amount, currency, credited_amount, credited_currency)
+		VALUES ('8fa6c93b-f300-4ef8-9bac-4258caea36db', '604f02b2-4e45-48d6-a952-03a0136e8140', 50000, 'EUR', 50000, 'EUR');
         
-INSERT INTO transfers (source_account_id, target_account_id, amount, currency)
-		VALUES ('ed989ca2-bc1b-413c-8698-d3d9dfa74800', '6ce82b44-95a5-4e96-915b-1e5b48f3e52a', 70000, 'EUR');
\ No newline at end of file
+INSERT INTO transfers (source_account_id, target_account_id, amount, currency, credited_amount, credited_currency)
+		VALUES ('ed989ca2-bc1b-413c-8698-d3d9dfa74800', '6ce82b44-95a5-4e96-915b-1e5b48f3e52a', 70000, 'EUR', 70000, 'EUR');
+
+INSERT INTO "entries" ("account_id", "kind", "amount", "currency")
+SELECT "id", 'opening', "balance", "currency" FROM "accounts";
+
+INSERT INTO "pockets" ("account_id", "currency", "balance", "created_at")
+SELECT "id", "currency", "balance", "created_at" FROM "accounts";
+ALTER TABLE "accounts" ADD COLUMN "version" integer NOT NULL DEFAULT 1;
+
+CREATE TABLE "api_keys" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "client" varchar NOT NULL,
+  "prefix" varchar NOT NULL UNIQUE,
+  "hash" bytea NOT NULL,
+  "scopes" varchar[] NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "expires_at" timestamp,
+  "revoked_at" timestamp,
+  "last_used_at" timestamp,
+  PRIMARY KEY ("id")
+);
+
+ALTER TABLE "customers" ADD COLUMN "subject" varchar UNIQUE;
+
+CREATE TABLE "roles" (
+  "name" varchar PRIMARY KEY,
+  "description" varchar NOT NULL DEFAULT '',
+  "permissions" varchar[] NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "updated_at" timestamp NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "audit_log" (
+  "id" bigserial PRIMARY KEY,
+  "occurred_at" timestamp NOT NULL DEFAULT (now()),
+  "actor" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "resource" varchar NOT NULL,
+  "request_id" varchar NOT NULL DEFAULT '',
+  "client_ip" varchar NOT NULL DEFAULT '',
+  "detail" varchar NOT NULL DEFAULT ''
+);
+
+CREATE INDEX ON "audit_log" ("occurred_at");
+
+CREATE TABLE "approvals" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "action" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "account_id" uuid NOT NULL,
+  "payload" jsonb NOT NULL,
+  "requested_by" varchar NOT NULL,
+  "decided_by" varchar,
+  "reason" varchar NOT NULL DEFAULT '',
+  "transfer_id" uuid REFERENCES "transfers" ("id"),
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "expires_at" timestamp NOT NULL,
+  "decided_at" timestamp,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX ON "approvals" ("status", "expires_at");
+
+ALTER TABLE "audit_log"
+  ADD COLUMN "before" json,
+  ADD COLUMN "after" json,
+  ADD COLUMN "prev_hash" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "hash" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "audit_log" ("actor");
+CREATE INDEX ON "audit_log" ("resource");
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+  BEFORE UPDATE OR DELETE OR TRUNCATE ON "audit_log"
+  FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+CREATE UNLOGGED TABLE "rate_limit_buckets" (
+  "key" varchar PRIMARY KEY,
+  "tokens" double precision NOT NULL,
+  "updated_at" timestamp NOT NULL
+);
+
+CREATE TABLE "signing_secrets" (
+  "id" uuid DEFAULT gen_random_uuid(),
+  "client" varchar NOT NULL,
+  "secret" varchar NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "expires_at" timestamp,
+  "revoked_at" timestamp,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX ON "signing_secrets" ("client");
+
+CREATE TABLE "signature_nonces" (
+  "client" varchar NOT NULL,
+  "nonce" varchar NOT NULL,
+  "expires_at" timestamp NOT NULL,
+  PRIMARY KEY ("client", "nonce")
+);
+
+CREATE INDEX ON "signature_nonces" ("expires_at");
+
+CREATE TABLE "idempotency_keys" (
+  "key" varchar PRIMARY KEY,
+  "fingerprint" bytea NOT NULL,
+  "done" boolean NOT NULL DEFAULT false,
+  "status" integer,
+  "header" jsonb,
+  "body" bytea,
+  "expires_at" timestamp NOT NULL
+);
+
+CREATE INDEX ON "idempotency_keys" ("expires_at");
+
+ALTER TABLE "accounts" ADD COLUMN "frozen" boolean NOT NULL DEFAULT false;
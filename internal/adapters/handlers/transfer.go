@@ -4,28 +4,38 @@ import (
 	"context"
 	"errors"
 	"net/http"
-	"time"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/config"
 	"github.com/petrostrak/agile-transfer/internal/core/domain"
 	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/validator"
 	"github.com/petrostrak/agile-transfer/utils"
 	"github.com/shopspring/decimal"
 )
 
 type TransferHandler struct {
-	service services.TransferService
+	service   services.TransferService
+	accounts  services.AccountService
+	approvals services.ApprovalService
+	timeouts  config.Handlers
 }
 
-func NewTransferHandler(transferService services.TransferService) *TransferHandler {
+func NewTransferHandler(transferService services.TransferService, accountService services.AccountService, approvalService services.ApprovalService, timeouts config.Handlers) *TransferHandler {
 	return &TransferHandler{
 		transferService,
+		accountService,
+		approvalService,
+		timeouts,
 	}
 }
 
+// CreateTransfer makes the transfer, or holds it for approval when it is
+// above the threshold of its source currency.
 func (t *TransferHandler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 4*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), t.timeouts.TransferTimeout)
 	defer cancel()
 
 	var input struct {
@@ -33,90 +43,150 @@ func (t *TransferHandler) CreateTransfer(w http.ResponseWriter, r *http.Request)
 		TargetAccountID uuid.UUID       `json:"target_account_id"`
 		Amount          decimal.Decimal `json:"amount"`
 		Currency        string          `json:"currency"`
+		SourceCurrency  string          `json:"source_currency"`
+		TargetCurrency  string          `json:"target_currency"`
 	}
 
 	err := utils.ReadJSON(w, r, &input)
 	if err != nil {
-		utils.BadRequestResponse(w, r, err)
+		errorResponse(w, r, err)
 		return
 	}
 
-	accounts, err := t.service.ValidateAccounts(ctx, input.SourceAccountID, input.TargetAccountID)
-	if err != nil {
-		utils.BadRequestResponse(w, r, err)
+	v := validator.New()
+	domain.ValidateID(v, "source_account_id", input.SourceAccountID)
+	domain.ValidateID(v, "target_account_id", input.TargetAccountID)
+	v.Check(input.SourceAccountID != input.TargetAccountID, "target_account_id", "must differ from source_account_id")
+	domain.ValidateAmount(v, "amount", input.Amount)
+	if input.SourceCurrency != "" {
+		domain.ValidateCurrency(v, "source_currency", input.SourceCurrency, "amount", input.Amount)
+	}
+	if input.TargetCurrency != "" {
+		domain.ValidateCurrency(v, "target_currency", input.TargetCurrency, "", decimal.Zero)
+	}
+	if !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	arg := domain.TransferTxParams{
-		SourceAccountID:  input.SourceAccountID,
-		TargetAccountID:  input.TargetAccountID,
-		SourceBalance:    accounts[0].Balance,
-		SourceCurrency:   accounts[0].Currency,
-		TargetCurrency:   accounts[1].Currency,
-		AmountToTransfer: input.Amount,
+	if err = t.authorizeSource(r, input.SourceAccountID); err != nil {
+		errorResponse(w, r, err)
+		return
 	}
 
-	result, err := t.service.TransferTx(ctx, arg)
+	// currency, kept from the first version of the API, must name the
+	// currency of the pocket the transfer is debited from.
+	if input.Currency != "" {
+		source := input.SourceCurrency
+		if source == "" {
+			account, err := t.accounts.Get(input.SourceAccountID)
+			if err != nil {
+				errorResponse(w, r, err)
+				return
+			}
+			source = account.Currency
+		}
+		if !strings.EqualFold(input.Currency, source) {
+			v.AddError("currency", "must be the currency of the source pocket")
+			utils.FailedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
+
+	// Transfers move money between the base pockets unless the request
+	// names the pockets to debit and credit.
+	result, err := t.service.Transfer(ctx, input.SourceAccountID, input.TargetAccountID, input.Amount, input.SourceCurrency, input.TargetCurrency)
+	if errors.Is(err, utils.ErrApprovalRequired) {
+		requestApproval(w, r, t.approvals, &domain.Approval{
+			Action:    domain.ApprovalTransfer,
+			AccountID: input.SourceAccountID,
+			Transfer: &domain.TransferOrder{
+				TargetAccountID: input.TargetAccountID,
+				Amount:          input.Amount,
+				SourceCurrency:  input.SourceCurrency,
+				TargetCurrency:  input.TargetCurrency,
+			},
+		})
+		return
+	}
 	if err != nil {
-		utils.BadRequestResponse(w, r, err)
+		errorResponse(w, r, err)
 		return
 	}
+	auditChange(r, domain.AuditTransferCreated, "transfers/"+result.Transfer.ID.String(), nil, snapshot(result.Transfer))
 
-	err = utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"transaction": result}, nil)
+	err = utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"transaction": responseFormat(r).transferResult(result)}, nil)
 	if err != nil {
 		utils.ServerErrorResponse(w, r, err)
 	}
 }
 
-func (t *TransferHandler) GetAllTransfers(w http.ResponseWriter, r *http.Request) {
-	transfers, err := t.service.GetAll()
+// ReverseTransfer sends the money of the transfer back to its source account.
+// A transfer can be reversed once.
+func (t *TransferHandler) ReverseTransfer(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), t.timeouts.TransferTimeout)
+	defer cancel()
+
+	id, err := utils.ReadUUIDParam(r, "id")
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrRecordNotFound):
-			utils.NotFoundResponse(w, r)
-		default:
-			utils.ServerErrorResponse(w, r, err)
-		}
+		errorResponse(w, r, err)
+		return
+	}
+
+	transfer, err := t.service.Get(id)
+	if err != nil {
+		errorResponse(w, r, err)
 		return
 	}
 
-	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"transfers": transfers}, nil)
+	result, err := t.service.Reverse(ctx, id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditTransferReversed, "transfers/"+transfer.ID.String(), snapshot(transfer), snapshot(result.Transfer))
+
+	err = utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"transaction": responseFormat(r).transferResult(result)}, nil)
 	if err != nil {
 		utils.ServerErrorResponse(w, r, err)
 	}
 }
 
-func (t *TransferHandler) TransferTx(ctx context.Context, arg domain.TransferTxParams) (*domain.TransferTxResult, error) {
-	var result domain.TransferTxResult
-
-	err := t.service.ExecTx(ctx, func() error {
-		var err error
-
-		result.SourceAccount, result.TargetAccount, err = t.service.AddMoney(
-			ctx,
-			arg.SourceAccountID,
-			arg.AmountToTransfer.Neg(),
-			arg.TargetAccountID,
-			arg.AmountToTransfer,
-		)
-		if err != nil {
-			return err
-		}
+// authorizeSource refuses users other than admins sending money from the
+// accounts of others. An account that does not exist is not theirs either.
+func (t *TransferHandler) authorizeSource(r *http.Request, id uuid.UUID) error {
+	if user := CurrentUser(r); user == nil || user.Admin() {
+		return nil
+	}
 
-		trasfer := domain.Transfer{
-			SourceAccountID: arg.SourceAccountID,
-			TargetAccountID: arg.TargetAccountID,
-			Amount:          arg.AmountToTransfer,
-			Currency:        arg.TargetCurrency,
+	account, err := t.accounts.Get(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return utils.ErrAccountNotOwned
 		}
+		return err
+	}
+	return authorizeAccount(r, account)
+}
 
-		result.Transfer, err = t.service.Insert(ctx, trasfer)
-		if err != nil {
-			return err
-		}
+// GetAllTransfers returns one page of the transfers, ordered by id.
+func (t *TransferHandler) GetAllTransfers(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	page := utils.ReadPage(r, v)
+	if !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
 
-		return nil
-	})
+	transfers, err := t.service.GetAll()
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	transfers, metadata := utils.Paginate(transfers, page)
 
-	return &result, err
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"transfers": responseFormat(r).transfers(transfers), "metadata": metadata}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
 }
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+// Version is a version of the REST API. Every version is served under its own
+// path prefix by the same handlers, which call APIVersion to pick the
+// representation of their responses.
+type Version int
+
+const (
+	V1 Version = iota + 1
+	// V2 is in development; its representations may still change until it is
+	// released.
+	V2
+)
+
+// Prefix is the path every route of the version is served under, e.g. "/v1".
+func (v Version) Prefix() string {
+	return fmt.Sprintf("/v%d", v)
+}
+
+type versionKey struct{}
+
+// UseVersion marks the requests it serves as made to version v of the API.
+func UseVersion(v Version) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), versionKey{}, v)))
+		})
+	}
+}
+
+// APIVersion is the version of the API the request was made to, V1 unless
+// set by UseVersion.
+func APIVersion(r *http.Request) Version {
+	if v, ok := r.Context().Value(versionKey{}).(Version); ok {
+		return v
+	}
+	return V1
+}
+
+// Deprecate announces that the routes it wraps, served under prefix, will be
+// removed at sunset in favour of the same routes under successor. Responses
+// carry the Deprecation (RFC 9745) and Sunset (RFC 8594) headers and a link
+// to the successor. Once the sunset has passed, requests are refused with
+// 410 Gone.
+func Deprecate(deprecated, sunset time.Time, prefix, successor string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			link := successor + strings.TrimPrefix(r.URL.Path, prefix)
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, link))
+
+			if !time.Now().Before(sunset) {
+				errorResponse(w, r, utils.ErrVersionSunset)
+				return
+			}
+
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", deprecated.Unix()))
+			w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+	"github.com/petrostrak/agile-transfer/utils"
+	"github.com/shopspring/decimal"
+)
+
+type WalletHandler struct {
+	service services.WalletService
+}
+
+func NewWalletHandler(walletService services.WalletService) *WalletHandler {
+	return &WalletHandler{
+		walletService,
+	}
+}
+
+func (wh *WalletHandler) GetPockets(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	pockets, err := wh.service.Pockets(r.Context(), id)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"pockets": responseFormat(r).pockets(pockets)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (wh *WalletHandler) OpenPocket(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		Currency string `json:"currency"`
+	}
+
+	err = utils.ReadJSON(w, r, &input)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if domain.ValidateCurrency(v, "currency", input.Currency, "", decimal.Zero); !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	pocket, err := wh.service.OpenPocket(r.Context(), id, input.Currency)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditPocketOpened, "accounts/"+id.String()+"/pockets/"+pocket.Currency, nil, snapshot(pocket))
+
+	err = utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"pocket": responseFormat(r).pocket(pocket)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
+
+// Convert exchanges money between two pockets of the same account at the
+// current rate.
+func (wh *WalletHandler) Convert(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ReadUUIDParam(r, "id")
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		FromCurrency string          `json:"from_currency"`
+		ToCurrency   string          `json:"to_currency"`
+		Amount       decimal.Decimal `json:"amount"`
+	}
+
+	err = utils.ReadJSON(w, r, &input)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	domain.ValidateAmount(v, "amount", input.Amount)
+	domain.ValidateCurrency(v, "from_currency", input.FromCurrency, "amount", input.Amount)
+	domain.ValidateCurrency(v, "to_currency", input.ToCurrency, "", decimal.Zero)
+	v.Check(currency.Normalize(input.FromCurrency) != currency.Normalize(input.ToCurrency), "to_currency", "must differ from from_currency")
+	if !v.Valid() {
+		utils.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	conversion := &domain.Conversion{
+		AccountID: id,
+		Amount:    domain.NewMoney(input.Amount, input.FromCurrency),
+		Converted: domain.Money{Currency: input.ToCurrency},
+	}
+
+	err = wh.service.Convert(r.Context(), conversion)
+	if err != nil {
+		errorResponse(w, r, err)
+		return
+	}
+	auditChange(r, domain.AuditPocketsConverted, "accounts/"+id.String()+"/pockets", nil, snapshot(conversion))
+
+	err = utils.WriteJSON(w, http.StatusOK, utils.Envelope{"conversion": responseFormat(r).conversion(conversion)}, nil)
+	if err != nil {
+		utils.ServerErrorResponse(w, r, err)
+	}
+}
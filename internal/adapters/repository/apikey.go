@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type APIKeyRepository struct {
+	DB *sql.DB
+}
+
+const apiKeyColumns = `id, client, prefix, hash, scopes, created_at, expires_at, revoked_at, last_used_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }, key *domain.APIKey) error {
+	var scopes []string
+	err := row.Scan(
+		&key.ID,
+		&key.Client,
+		&key.Prefix,
+		&key.Hash,
+		pq.Array(&scopes),
+		&key.CreatedAt,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.LastUsedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	key.Scopes = make([]domain.Scope, len(scopes))
+	for i, scope := range scopes {
+		key.Scopes[i] = domain.Scope(scope)
+	}
+	return nil
+}
+
+func (a *APIKeyRepository) Insert(ctx context.Context, key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (client, prefix, hash, scopes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+	args := []any{key.Client, key.Prefix, key.Hash, pq.Array(scopes)}
+
+	return a.DB.QueryRowContext(ctx, query, args...).Scan(&key.ID, &key.CreatedAt)
+}
+
+func (a *APIKeyRepository) Get(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1`
+
+	var key domain.APIKey
+	err := scanAPIKey(a.DB.QueryRowContext(ctx, query, id), &key)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &key, nil
+}
+
+// GetByPrefix returns the key with the given public part, or nil when there
+// is none.
+func (a *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = $1`
+
+	var key domain.APIKey
+	err := scanAPIKey(a.DB.QueryRowContext(ctx, query, prefix), &key)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, nil
+		default:
+			return nil, err
+		}
+	}
+	return &key, nil
+}
+
+func (a *APIKeyRepository) GetAll(ctx context.Context) ([]domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at, id`
+
+	rows, err := a.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+	for rows.Next() {
+		var key domain.APIKey
+		if err := scanAPIKey(rows, &key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke marks the key as revoked at the given time, unless it already is.
+func (a *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = COALESCE(revoked_at, $1)
+		WHERE id = $2`
+
+	return a.exec(ctx, query, at.UTC(), id)
+}
+
+// Expire sets the time the key stops working.
+func (a *APIKeyRepository) Expire(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `
+		UPDATE api_keys
+		SET expires_at = $1
+		WHERE id = $2`
+
+	return a.exec(ctx, query, at.UTC(), id)
+}
+
+// Touch records that the key was used at the given time.
+func (a *APIKeyRepository) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = GREATEST(last_used_at, $1)
+		WHERE id = $2`
+
+	return a.exec(ctx, query, at.UTC(), id)
+}
+
+// exec runs a statement that changes the key with the given id, reporting
+// ErrRecordNotFound when there is none.
+func (a *APIKeyRepository) exec(ctx context.Context, query string, args ...any) error {
+	result, err := a.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type ApprovalRepository struct {
+	DB *sql.DB
+}
+
+// approvalPayload is how the details of the action are stored.
+type approvalPayload struct {
+	Transfer *domain.TransferOrder `json:"transfer,omitempty"`
+	Change   *domain.AccountChange `json:"change,omitempty"`
+}
+
+const approvalColumns = `id, action, status, account_id, payload, requested_by, decided_by, reason, transfer_id, created_at, expires_at, decided_at`
+
+func scanApproval(row interface{ Scan(...any) error }, approval *domain.Approval) error {
+	var payload []byte
+	err := row.Scan(
+		&approval.ID,
+		&approval.Action,
+		&approval.Status,
+		&approval.AccountID,
+		&payload,
+		&approval.RequestedBy,
+		&approval.DecidedBy,
+		&approval.Reason,
+		&approval.TransferID,
+		&approval.CreatedAt,
+		&approval.ExpiresAt,
+		&approval.DecidedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	var details approvalPayload
+	if err := json.Unmarshal(payload, &details); err != nil {
+		return err
+	}
+	approval.Transfer, approval.Change = details.Transfer, details.Change
+	return nil
+}
+
+func (a *ApprovalRepository) Insert(ctx context.Context, approval *domain.Approval) error {
+	query := `
+		INSERT INTO approvals (action, status, account_id, payload, requested_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	payload, err := json.Marshal(approvalPayload{approval.Transfer, approval.Change})
+	if err != nil {
+		return err
+	}
+	args := []any{approval.Action, approval.Status, approval.AccountID, payload, approval.RequestedBy, approval.ExpiresAt.UTC()}
+
+	return a.DB.QueryRowContext(ctx, query, args...).Scan(&approval.ID, &approval.CreatedAt)
+}
+
+func (a *ApprovalRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Approval, error) {
+	query := `SELECT ` + approvalColumns + ` FROM approvals WHERE id = $1`
+
+	var approval domain.Approval
+	err := scanApproval(a.DB.QueryRowContext(ctx, query, id), &approval)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &approval, nil
+}
+
+func (a *ApprovalRepository) GetAll(ctx context.Context, status domain.ApprovalStatus) ([]domain.Approval, error) {
+	query := `
+		SELECT ` + approvalColumns + ` FROM approvals
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC, id`
+
+	rows, err := a.DB.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var approvals []domain.Approval
+	for rows.Next() {
+		var approval domain.Approval
+		if err := scanApproval(rows, &approval); err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, approval)
+	}
+	return approvals, rows.Err()
+}
+
+// Decide records the decision only while the approval is pending, so that of
+// two people deciding at once only one carries the action out.
+func (a *ApprovalRepository) Decide(ctx context.Context, approval *domain.Approval) (bool, error) {
+	query := `
+		UPDATE approvals
+		SET status = $1, decided_by = $2, decided_at = $3, reason = $4
+		WHERE id = $5 AND status = 'pending' AND expires_at > $3`
+
+	args := []any{approval.Status, approval.DecidedBy, approval.DecidedAt.UTC(), approval.Reason, approval.ID}
+
+	result, err := a.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+
+func (a *ApprovalRepository) Finish(ctx context.Context, approval *domain.Approval) error {
+	query := `
+		UPDATE approvals
+		SET status = $1, reason = $2, transfer_id = $3
+		WHERE id = $4`
+
+	result, err := a.DB.ExecContext(ctx, query, approval.Status, approval.Reason, approval.TransferID, approval.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (a *ApprovalRepository) Expire(ctx context.Context, at time.Time) error {
+	query := `
+		UPDATE approvals
+		SET status = 'expired'
+		WHERE status = 'pending' AND expires_at <= $1`
+
+	_, err := a.DB.ExecContext(ctx, query, at.UTC())
+	return err
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type AuditRepository struct {
+	DB *sql.DB
+}
+
+const auditColumns = `id, occurred_at, actor, action, resource, request_id, client_ip, detail, before, after, prev_hash, hash`
+
+// auditLock is the advisory lock that makes entries be sealed one at a time,
+// so that no two entries are chained to the same one.
+const auditLock = 4_045_001
+
+// Insert seals the entry to the last one and appends it, holding auditLock
+// until the entry is committed.
+func (a *AuditRepository) Insert(ctx context.Context, entry *domain.AuditEntry) (err error) {
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+			}
+		}
+	}()
+
+	if err = insertAudit(ctx, tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertAudit appends the entry in tx, so that it is committed with the
+// change it records or not at all. auditLock is held until tx ends.
+func insertAudit(ctx context.Context, tx *sql.Tx, entry *domain.AuditEntry) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditLock); err != nil {
+		return err
+	}
+
+	var prev string
+	err := tx.QueryRowContext(ctx, `SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&prev)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	entry.Seal(prev)
+
+	query := `
+		INSERT INTO audit_log (occurred_at, actor, action, resource, request_id, client_ip, detail, before, after, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`
+
+	args := []any{entry.OccurredAt, entry.Actor, entry.Action, entry.Resource, entry.RequestID, entry.ClientIP, entry.Detail, nullJSON(entry.Before), nullJSON(entry.After), entry.PrevHash, entry.Hash}
+
+	return tx.QueryRowContext(ctx, query, args...).Scan(&entry.ID)
+}
+
+// nullJSON stores empty snapshots as NULL. The json type keeps the text as
+// it is, so the stored snapshot still matches the hash.
+func nullJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+func (a *AuditRepository) GetAll(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	query := `
+		SELECT ` + auditColumns + ` FROM audit_log
+		WHERE ($1 = '' OR actor = $1)
+			AND ($2 = '' OR action = $2)
+			AND ($3 = '' OR resource = $3)
+			AND ($4::timestamp IS NULL OR occurred_at >= $4)
+			AND ($5::timestamp IS NULL OR occurred_at < $5)
+		ORDER BY id DESC`
+
+	from := sql.NullTime{Time: filter.From.UTC(), Valid: !filter.From.IsZero()}
+	to := sql.NullTime{Time: filter.To.UTC(), Valid: !filter.To.IsZero()}
+
+	return a.query(ctx, query, filter.Actor, filter.Action, filter.Resource, from, to)
+}
+
+func (a *AuditRepository) GetChain(ctx context.Context, afterID int64, limit int) ([]domain.AuditEntry, error) {
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE id > $1 ORDER BY id LIMIT $2`
+
+	return a.query(ctx, query, afterID, limit)
+}
+
+func (a *AuditRepository) query(ctx context.Context, query string, args ...any) ([]domain.AuditEntry, error) {
+	rows, err := a.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []domain.AuditEntry
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var before, after []byte
+		err := rows.Scan(
+			&entry.ID,
+			&entry.OccurredAt,
+			&entry.Actor,
+			&entry.Action,
+			&entry.Resource,
+			&entry.RequestID,
+			&entry.ClientIP,
+			&entry.Detail,
+			&before,
+			&after,
+			&entry.PrevHash,
+			&entry.Hash,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entry.Before, entry.After = before, after
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
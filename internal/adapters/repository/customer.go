@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+var (
+	ErrDuplicateEmail   = errors.New("duplicate email")
+	ErrDuplicateSubject = errors.New("duplicate subject")
+)
+
+type CustomerRepository struct {
+	DB *sql.DB
+}
+
+func (c *CustomerRepository) Insert(ctx context.Context, customer *domain.Customer) error {
+	query := `
+		INSERT INTO customers (name, email, type, status, restrict_transfers, subject)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`
+
+	args := []any{customer.Name, customer.Email, customer.Type, customer.Status, customer.RestrictTransfers, customer.Subject}
+
+	err := c.DB.QueryRowContext(ctx, query, args...).Scan(&customer.ID, &customer.CreatedAt, &customer.UpdatedAt)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "customers_email_key"`:
+			return ErrDuplicateEmail
+		case err.Error() == `pq: duplicate key value violates unique constraint "customers_subject_key"`:
+			return ErrDuplicateSubject
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CustomerRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
+	query := `
+		SELECT id, name, email, type, status, restrict_transfers, subject, created_at, updated_at
+		FROM customers
+		WHERE id = $1`
+
+	var customer domain.Customer
+	err := c.DB.QueryRowContext(ctx, query, id).Scan(
+		&customer.ID,
+		&customer.Name,
+		&customer.Email,
+		&customer.Type,
+		&customer.Status,
+		&customer.RestrictTransfers,
+		&customer.Subject,
+		&customer.CreatedAt,
+		&customer.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &customer, nil
+}
+
+// GetBySubject returns the customer whose user has the given subject at the
+// identity provider, or nil when there is none.
+func (c *CustomerRepository) GetBySubject(ctx context.Context, subject string) (*domain.Customer, error) {
+	query := `
+		SELECT id, name, email, type, status, restrict_transfers, subject, created_at, updated_at
+		FROM customers
+		WHERE subject = $1`
+
+	var customer domain.Customer
+	err := c.DB.QueryRowContext(ctx, query, subject).Scan(
+		&customer.ID,
+		&customer.Name,
+		&customer.Email,
+		&customer.Type,
+		&customer.Status,
+		&customer.RestrictTransfers,
+		&customer.Subject,
+		&customer.CreatedAt,
+		&customer.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, nil
+		default:
+			return nil, err
+		}
+	}
+	return &customer, nil
+}
+
+func (c *CustomerRepository) Update(ctx context.Context, customer *domain.Customer) error {
+	query := `
+		UPDATE customers
+		SET name = $1, email = $2, type = $3, status = $4, restrict_transfers = $5, subject = $6, updated_at = now()
+		WHERE id = $7
+		RETURNING updated_at`
+
+	args := []any{customer.Name, customer.Email, customer.Type, customer.Status, customer.RestrictTransfers, customer.Subject, customer.ID}
+
+	err := c.DB.QueryRowContext(ctx, query, args...).Scan(&customer.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		case err.Error() == `pq: duplicate key value violates unique constraint "customers_email_key"`:
+			return ErrDuplicateEmail
+		case err.Error() == `pq: duplicate key value violates unique constraint "customers_subject_key"`:
+			return ErrDuplicateSubject
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CustomerRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `
+		DELETE FROM customers
+		WHERE id = $1`
+
+	result, err := c.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (c *CustomerRepository) GetAll(ctx context.Context) ([]domain.Customer, error) {
+	query := `
+		SELECT id, name, email, type, status, restrict_transfers, subject, created_at, updated_at
+		FROM customers
+		ORDER BY created_at, id`
+
+	rows, err := c.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var customers []domain.Customer
+	for rows.Next() {
+		var customer domain.Customer
+		if err := rows.Scan(
+			&customer.ID,
+			&customer.Name,
+			&customer.Email,
+			&customer.Type,
+			&customer.Status,
+			&customer.RestrictTransfers,
+			&customer.Subject,
+			&customer.CreatedAt,
+			&customer.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+
+	return customers, rows.Err()
+}
+
+func (c *CustomerRepository) GetAccounts(ctx context.Context, id uuid.UUID) ([]domain.Account, error) {
+	query := `
+		SELECT id, customer_id, balance, currency, frozen, created_at, version
+		FROM accounts
+		WHERE customer_id = $1
+		ORDER BY created_at, id`
+
+	rows, err := c.DB.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []domain.Account
+	for rows.Next() {
+		var account domain.Account
+		if err := rows.Scan(
+			&account.ID,
+			&account.CustomerID,
+			&account.Balance,
+			&account.Currency,
+			&account.Frozen,
+			&account.CreatedAt,
+			&account.Version,
+		); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = loadPockets(ctx, c.DB, accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+func (c *CustomerRepository) AddBeneficiary(ctx context.Context, beneficiary *domain.Beneficiary) error {
+	query := `
+		INSERT INTO beneficiaries (customer_id, account_id)
+		VALUES ($1, $2)
+		ON CONFLICT (customer_id, account_id) DO UPDATE SET customer_id = EXCLUDED.customer_id
+		RETURNING created_at`
+
+	args := []any{beneficiary.CustomerID, beneficiary.AccountID}
+
+	return c.DB.QueryRowContext(ctx, query, args...).Scan(&beneficiary.CreatedAt)
+}
+
+func (c *CustomerRepository) RemoveBeneficiary(ctx context.Context, customerID, accountID uuid.UUID) error {
+	query := `
+		DELETE FROM beneficiaries
+		WHERE customer_id = $1 AND account_id = $2`
+
+	result, err := c.DB.ExecContext(ctx, query, customerID, accountID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (c *CustomerRepository) GetBeneficiaries(ctx context.Context, customerID uuid.UUID) ([]domain.Beneficiary, error) {
+	query := `
+		SELECT customer_id, account_id, created_at
+		FROM beneficiaries
+		WHERE customer_id = $1
+		ORDER BY created_at`
+
+	rows, err := c.DB.QueryContext(ctx, query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var beneficiaries []domain.Beneficiary
+	for rows.Next() {
+		var beneficiary domain.Beneficiary
+		if err := rows.Scan(&beneficiary.CustomerID, &beneficiary.AccountID, &beneficiary.CreatedAt); err != nil {
+			return nil, err
+		}
+		beneficiaries = append(beneficiaries, beneficiary)
+	}
+
+	return beneficiaries, rows.Err()
+}
+
+func (c *CustomerRepository) IsBeneficiary(ctx context.Context, customerID, accountID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM beneficiaries
+			WHERE customer_id = $1 AND account_id = $2
+		)`
+
+	var exists bool
+	err := c.DB.QueryRowContext(ctx, query, customerID, accountID).Scan(&exists)
+
+	return exists, err
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type IdempotencyRepository struct {
+	DB *sql.DB
+}
+
+// Reserve inserts the key, or takes over one that expired, in one statement
+// so that of two instances seeing the same key only one processes the
+// request.
+func (i *IdempotencyRepository) Reserve(ctx context.Context, response *domain.IdempotentResponse, now time.Time) (*domain.IdempotentResponse, error) {
+	query := `
+		INSERT INTO idempotency_keys (key, fingerprint, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, done = false, status = NULL, header = NULL, body = NULL, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= $4
+		RETURNING true`
+
+	var reserved bool
+	err := i.DB.QueryRowContext(ctx, query, response.Key, response.Fingerprint, response.ExpiresAt.UTC(), now.UTC()).Scan(&reserved)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	query = `
+		SELECT fingerprint, done, COALESCE(status, 0), header, body, expires_at
+		FROM idempotency_keys
+		WHERE key = $1`
+
+	kept := domain.IdempotentResponse{Key: response.Key}
+	var header []byte
+	err = i.DB.QueryRowContext(ctx, query, response.Key).Scan(
+		&kept.Fingerprint,
+		&kept.Done,
+		&kept.Status,
+		&header,
+		&kept.Body,
+		&kept.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		if err := json.Unmarshal(header, &kept.Header); err != nil {
+			return nil, err
+		}
+	}
+	return &kept, nil
+}
+
+func (i *IdempotencyRepository) Complete(ctx context.Context, response *domain.IdempotentResponse) error {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE idempotency_keys
+		SET done = true, status = $2, header = $3, body = $4, expires_at = $5
+		WHERE key = $1`
+
+	_, err = i.DB.ExecContext(ctx, query, response.Key, response.Status, header, response.Body, response.ExpiresAt.UTC())
+	return err
+}
+
+func (i *IdempotencyRepository) Release(ctx context.Context, key string) error {
+	_, err := i.DB.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND NOT done`, key)
+	return err
+}
+
+func (i *IdempotencyRepository) PurgeIdempotencyKeys(ctx context.Context, at time.Time) error {
+	_, err := i.DB.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1`, at.UTC())
+	return err
+}
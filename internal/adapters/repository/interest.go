@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/shopspring/decimal"
+)
+
+type InterestRepository struct {
+	DB *sql.DB
+}
+
+func (i *InterestRepository) GetConfig(ctx context.Context, accountID uuid.UUID) (*domain.InterestConfig, error) {
+	query := `
+		SELECT account_id, annual_rate, day_count, compounding, posting_frequency, enabled, created_at, updated_at
+		FROM interest_configs
+		WHERE account_id = $1`
+
+	var cfg domain.InterestConfig
+	err := i.DB.QueryRowContext(ctx, query, accountID).Scan(
+		&cfg.AccountID,
+		&cfg.AnnualRate,
+		&cfg.DayCount,
+		&cfg.Compounding,
+		&cfg.PostingFrequency,
+		&cfg.Enabled,
+		&cfg.CreatedAt,
+		&cfg.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &cfg, nil
+}
+
+func (i *InterestRepository) UpsertConfig(ctx context.Context, cfg *domain.InterestConfig) error {
+	query := `
+		INSERT INTO interest_configs (account_id, annual_rate, day_count, compounding, posting_frequency, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (account_id) DO UPDATE
+		SET annual_rate = EXCLUDED.annual_rate,
+			day_count = EXCLUDED.day_count,
+			compounding = EXCLUDED.compounding,
+			posting_frequency = EXCLUDED.posting_frequency,
+			enabled = EXCLUDED.enabled,
+			updated_at = now()
+		RETURNING created_at, updated_at`
+
+	args := []any{cfg.AccountID, cfg.AnnualRate, cfg.DayCount, cfg.Compounding, cfg.PostingFrequency, cfg.Enabled}
+
+	return i.DB.QueryRowContext(ctx, query, args...).Scan(&cfg.CreatedAt, &cfg.UpdatedAt)
+}
+
+func (i *InterestRepository) GetEnabledConfigs(ctx context.Context) ([]domain.InterestConfig, error) {
+	query := `
+		SELECT account_id, annual_rate, day_count, compounding, posting_frequency, enabled, created_at, updated_at
+		FROM interest_configs
+		WHERE enabled
+		ORDER BY account_id`
+
+	rows, err := i.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var configs []domain.InterestConfig
+	for rows.Next() {
+		var cfg domain.InterestConfig
+		if err := rows.Scan(
+			&cfg.AccountID,
+			&cfg.AnnualRate,
+			&cfg.DayCount,
+			&cfg.Compounding,
+			&cfg.PostingFrequency,
+			&cfg.Enabled,
+			&cfg.CreatedAt,
+			&cfg.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		configs = append(configs, cfg)
+	}
+
+	return configs, rows.Err()
+}
+
+// InsertAccrual stores the accrual unless one already exists for the same
+// account and day, and reports whether a row was written.
+func (i *InterestRepository) InsertAccrual(ctx context.Context, accrual *domain.InterestAccrual) (bool, error) {
+	query := `
+		INSERT INTO interest_accruals (account_id, accrual_date, balance, annual_rate, amount)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (account_id, accrual_date) DO NOTHING
+		RETURNING id, created_at`
+
+	args := []any{accrual.AccountID, accrual.AccrualDate, accrual.Balance, accrual.AnnualRate, accrual.Amount}
+
+	err := i.DB.QueryRowContext(ctx, query, args...).Scan(&accrual.ID, &accrual.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (i *InterestRepository) GetAccruals(ctx context.Context, accountID uuid.UUID) ([]domain.InterestAccrual, error) {
+	query := `
+		SELECT id, account_id, accrual_date, balance, annual_rate, amount, created_at
+		FROM interest_accruals
+		WHERE account_id = $1
+		ORDER BY accrual_date`
+
+	rows, err := i.DB.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accruals []domain.InterestAccrual
+	for rows.Next() {
+		var accrual domain.InterestAccrual
+		if err := rows.Scan(
+			&accrual.ID,
+			&accrual.AccountID,
+			&accrual.AccrualDate,
+			&accrual.Balance,
+			&accrual.AnnualRate,
+			&accrual.Amount,
+			&accrual.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		accruals = append(accruals, accrual)
+	}
+
+	return accruals, rows.Err()
+}
+
+// UnpostedInterest returns the interest accrued up to and including the given
+// day that has not been paid out by a posting yet.
+func (i *InterestRepository) UnpostedInterest(ctx context.Context, accountID uuid.UUID, upTo time.Time) (decimal.Decimal, error) {
+	query := `
+		SELECT
+			COALESCE((SELECT SUM(amount) FROM interest_accruals WHERE account_id = $1 AND accrual_date <= $2), 0) -
+			COALESCE((SELECT SUM(posted_amount) FROM interest_postings WHERE account_id = $1), 0)`
+
+	var unposted decimal.Decimal
+	err := i.DB.QueryRowContext(ctx, query, accountID, upTo).Scan(&unposted)
+
+	return unposted, err
+}
+
+// InsertPosting reserves the posting for the account and day, and reports
+// false if the day has already been posted.
+func (i *InterestRepository) InsertPosting(ctx context.Context, posting *domain.InterestPosting) (bool, error) {
+	query := `
+		INSERT INTO interest_postings (account_id, posting_date, accrued_amount, posted_amount)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (account_id, posting_date) DO NOTHING
+		RETURNING id, created_at`
+
+	args := []any{posting.AccountID, posting.PostingDate, posting.AccruedAmount, posting.PostedAmount}
+
+	err := i.DB.QueryRowContext(ctx, query, args...).Scan(&posting.ID, &posting.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (i *InterestRepository) CompletePosting(ctx context.Context, postingID, transferID uuid.UUID) error {
+	query := `
+		UPDATE interest_postings
+		SET transfer_id = $1
+		WHERE id = $2`
+
+	_, err := i.DB.ExecContext(ctx, query, transferID, postingID)
+	return err
+}
+
+// IncompletePostings returns the reserved postings that were never marked
+// complete. Their TransferID is set when the transfer of the posting, which
+// has the posting's id, was made nonetheless.
+func (i *InterestRepository) IncompletePostings(ctx context.Context) ([]domain.InterestPosting, error) {
+	query := `
+		SELECT p.id, p.account_id, p.posting_date, p.accrued_amount, p.posted_amount, t.id, p.created_at
+		FROM interest_postings p
+		LEFT JOIN transfers t ON t.id = p.id
+		WHERE p.transfer_id IS NULL
+		ORDER BY p.posting_date, p.account_id`
+
+	rows, err := i.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var postings []domain.InterestPosting
+	for rows.Next() {
+		var posting domain.InterestPosting
+		if err := rows.Scan(
+			&posting.ID,
+			&posting.AccountID,
+			&posting.PostingDate,
+			&posting.AccruedAmount,
+			&posting.PostedAmount,
+			&posting.TransferID,
+			&posting.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		postings = append(postings, posting)
+	}
+
+	return postings, rows.Err()
+}
+
+// LastAccrualDate returns the latest day up to and including upTo interest
+// was accrued for, the zero time when none was.
+func (i *InterestRepository) LastAccrualDate(ctx context.Context, upTo time.Time) (time.Time, error) {
+	var last sql.NullTime
+	err := i.DB.QueryRowContext(ctx, `SELECT MAX(accrual_date) FROM interest_accruals WHERE accrual_date <= $1`, upTo).Scan(&last)
+
+	return last.Time, err
+}
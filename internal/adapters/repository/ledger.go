@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/shopspring/decimal"
+)
+
+type LedgerRepository struct {
+	DB *sql.DB
+}
+
+// BalanceAt returns the balance of an account pocket just before the given
+// instant. It starts from the latest snapshot taken at or before that instant,
+// so only the entries made since the snapshot have to be summed.
+func (l *LedgerRepository) BalanceAt(ctx context.Context, accountID uuid.UUID, currency string, at time.Time) (decimal.Decimal, error) {
+	query := `
+		WITH snapshot AS (
+			SELECT taken_at, balance
+			FROM balance_snapshots
+			WHERE account_id = $1 AND currency = $3 AND taken_at <= $2
+			ORDER BY taken_at DESC
+			LIMIT 1
+		)
+		SELECT COALESCE((SELECT balance FROM snapshot), 0) + COALESCE((
+			SELECT SUM(amount)
+			FROM entries
+			WHERE account_id = $1 AND currency = $3 AND created_at < $2
+				AND created_at >= COALESCE((SELECT taken_at FROM snapshot), '-infinity')
+		), 0)`
+
+	var balance decimal.Decimal
+	err := l.DB.QueryRowContext(ctx, query, accountID, at, currency).Scan(&balance)
+
+	return balance, err
+}
+
+// balancesAtQuery computes the balance of every pocket that existed just
+// before $1, the same way BalanceAt does for a single pocket.
+const balancesAtQuery = `
+		SELECT p.account_id, p.currency, COALESCE(s.balance, 0) + COALESCE((
+			SELECT SUM(e.amount)
+			FROM entries e
+			WHERE e.account_id = p.account_id AND e.currency = p.currency AND e.created_at < $1
+				AND e.created_at >= COALESCE(s.taken_at, '-infinity')
+		), 0)
+		FROM pockets p
+		LEFT JOIN LATERAL (
+			SELECT taken_at, balance
+			FROM balance_snapshots
+			WHERE account_id = p.account_id AND currency = p.currency AND taken_at <= $1
+			ORDER BY taken_at DESC
+			LIMIT 1
+		) s ON true
+		WHERE p.created_at < $1`
+
+// BalancesAt returns the balance of every account pocket just before the
+// given instant.
+func (l *LedgerRepository) BalancesAt(ctx context.Context, at time.Time) ([]domain.Balance, error) {
+	query := balancesAtQuery + `
+		ORDER BY p.account_id, p.currency`
+
+	rows, err := l.DB.QueryContext(ctx, query, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []domain.Balance
+	for rows.Next() {
+		balance := domain.Balance{AsOf: at}
+		if err := rows.Scan(
+			&balance.AccountID,
+			&balance.Currency,
+			&balance.Balance,
+		); err != nil {
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+
+	return balances, rows.Err()
+}
+
+// TakeSnapshots stores the balance of every account pocket just before the
+// given instant and returns how many snapshots were written. Existing
+// snapshots for the same instant are left untouched.
+func (l *LedgerRepository) TakeSnapshots(ctx context.Context, at time.Time) (int64, error) {
+	query := `
+		INSERT INTO balance_snapshots (account_id, currency, taken_at, balance)
+		SELECT id, currency, $1::timestamp, balance FROM (` + balancesAtQuery + `
+		) AS balances (id, currency, balance)
+		ON CONFLICT (account_id, currency, taken_at) DO NOTHING`
+
+	result, err := l.DB.ExecContext(ctx, query, at)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetEntries returns the entries of an account pocket created in [from, to),
+// in the order they were applied.
+func (l *LedgerRepository) GetEntries(ctx context.Context, accountID uuid.UUID, currency string, from, to time.Time) ([]domain.Entry, error) {
+	query := `
+		SELECT e.id, e.account_id, e.currency, e.transfer_id,
+			CASE WHEN t.source_account_id = e.account_id THEN t.target_account_id ELSE t.source_account_id END,
+			e.kind, e.amount, e.created_at
+		FROM entries e
+		LEFT JOIN transfers t ON t.id = e.transfer_id
+		WHERE e.account_id = $1 AND e.currency = $4 AND e.created_at >= $2 AND e.created_at < $3
+		ORDER BY e.created_at, e.seq`
+
+	rows, err := l.DB.QueryContext(ctx, query, accountID, from, to, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []domain.Entry
+	for rows.Next() {
+		var entry domain.Entry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.AccountID,
+			&entry.Currency,
+			&entry.TransferID,
+			&entry.CounterpartyID,
+			&entry.Kind,
+			&entry.Amount,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
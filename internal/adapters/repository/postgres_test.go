@@ -5,9 +5,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -16,6 +19,7 @@ import (
 	"github.com/ory/dockertest/v3"
 	"github.com/ory/dockertest/v3/docker"
 	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
 	"github.com/shopspring/decimal"
 )
 
@@ -89,8 +93,19 @@ func TestMain(m *testing.M) {
 	}
 
 	testRepo = PostgresRepository{
-		&AccountRepository{testDB},
-		&TransferRepository{testDB},
+		AccountRepository:   &AccountRepository{DB: testDB},
+		TransferRepository:  &TransferRepository{DB: testDB},
+		InterestRepository:  &InterestRepository{DB: testDB},
+		LedgerRepository:    &LedgerRepository{DB: testDB},
+		CustomerRepository:  &CustomerRepository{DB: testDB},
+		WalletRepository:    &WalletRepository{DB: testDB},
+		APIKeyRepository:    &APIKeyRepository{DB: testDB},
+		RoleRepository:      &RoleRepository{DB: testDB},
+		AuditRepository:     &AuditRepository{DB: testDB},
+		ApprovalRepository:  &ApprovalRepository{DB: testDB},
+		RateLimitRepository: &RateLimitRepository{DB: testDB},
+		SigningRepository:   &SigningRepository{DB: testDB},
+		SchemaRepository:    &SchemaRepository{DB: testDB},
 	}
 
 	code := m.Run()
@@ -185,7 +200,6 @@ func Test_PostgresDBRepoGetAccount(t *testing.T) {
 func Test_PostgresDBRepoUpdateAccount(t *testing.T) {
 	account, _ := testRepo.AccountRepository.Get(testAccountID)
 	account.Balance = decimal.NewFromInt(500000)
-	account.Currency = "RUB"
 
 	err := testRepo.AccountRepository.Update(account)
 	if err != nil {
@@ -193,13 +207,67 @@ func Test_PostgresDBRepoUpdateAccount(t *testing.T) {
 	}
 
 	account, _ = testRepo.AccountRepository.Get(testAccountID)
-	if !account.Balance.Equal(decimal.NewFromInt(500000)) || account.Currency != "RUB" {
-		t.Errorf("expected updated record to have 500000 balance and RUB currency, but got %v and %s", account.Balance, account.Currency)
+	if !account.Balance.Equal(decimal.NewFromInt(500000)) || account.Currency != "EUR" {
+		t.Errorf("expected updated record to have 500000 balance and EUR currency, but got %v and %s", account.Balance, account.Currency)
+	}
+
+	changed := *account
+	changed.Currency = "RUB"
+	err = testRepo.AccountRepository.Update(&changed)
+	if !errors.Is(err, ErrCurrencyInUse) {
+		t.Errorf("expected the currency of a funded account not to change, but got %v", err)
+	}
+
+	unused := domain.Account{Balance: decimal.Zero, Currency: "EUR"}
+	_ = testRepo.AccountRepository.Insert(&unused)
+	unused.Currency = "RUB"
+	err = testRepo.AccountRepository.Update(&unused)
+	if err != nil || unused.Currency != "RUB" {
+		t.Errorf("expected the currency of an unused account to change, but got %s (%v)", unused.Currency, err)
+	}
+	_ = testRepo.AccountRepository.Delete(unused.ID, unused.Version)
+
+	stale := *account
+	stale.Version--
+	err = testRepo.AccountRepository.Update(&stale)
+	if !errors.Is(err, ErrEditConflict) {
+		t.Errorf("expected an edit conflict updating a stale account, but got %v", err)
+	}
+}
+
+func Test_PostgresDBRepoFreezeAccount(t *testing.T) {
+	account, _ := testRepo.AccountRepository.Get(testAccountID)
+
+	frozen, err := testRepo.AccountRepository.SetFrozen(testAccountID, true)
+	if err != nil || !frozen.Frozen || frozen.Version != account.Version+1 {
+		t.Errorf("expected the account to be frozen at the next version, but got %+v (%v)", frozen, err)
+	}
+
+	again, err := testRepo.AccountRepository.SetFrozen(testAccountID, true)
+	if err != nil || again.Version != frozen.Version {
+		t.Errorf("expected freezing a frozen account to change nothing, but got %+v (%v)", again, err)
+	}
+
+	unfrozen, err := testRepo.AccountRepository.SetFrozen(testAccountID, false)
+	if err != nil || unfrozen.Frozen {
+		t.Errorf("expected the account to be unfrozen, but got %+v (%v)", unfrozen, err)
+	}
+
+	_, err = testRepo.AccountRepository.SetFrozen(uuid.New(), true)
+	if !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("expected freezing a missing account to fail with ErrRecordNotFound, but got %v", err)
 	}
 }
 
 func Test_PostgresDBRepoDeleteAccount(t *testing.T) {
-	err := testRepo.AccountRepository.Delete(testAccountID)
+	account, _ := testRepo.AccountRepository.Get(testAccountID)
+
+	err := testRepo.AccountRepository.Delete(testAccountID, account.Version-1)
+	if !errors.Is(err, ErrEditConflict) {
+		t.Errorf("expected an edit conflict deleting a stale account, but got %v", err)
+	}
+
+	err = testRepo.AccountRepository.Delete(testAccountID, account.Version)
 	if err != nil {
 		t.Errorf("error deleting account: %s", err)
 	}
@@ -225,8 +293,7 @@ func Test_PostgresDBRepoInsertTransfer(t *testing.T) {
 	testTransfer := domain.Transfer{
 		SourceAccountID: testAccountID,
 		TargetAccountID: accounts[1].ID,
-		Amount:          decimal.NewFromInt(5400),
-		Currency:        "EUR",
+		Amount:          domain.NewMoney(decimal.NewFromInt(5400), "EUR"),
 	}
 
 	_, err := testRepo.TransferRepository.Insert(context.Background(), testTransfer)
@@ -253,12 +320,12 @@ func Test_PostgresDBRepoGetTransfer(t *testing.T) {
 		t.Errorf("error getting transfer by id: %s", err)
 	}
 
-	if !transfer.Amount.Equal(decimal.NewFromInt(5400)) {
-		t.Errorf("wrong transfer amount returned. expected 'EUR' but got %s", transfer.Amount)
+	if !transfer.Amount.Equal(domain.NewMoney(decimal.NewFromInt(5400), "EUR")) {
+		t.Errorf("wrong transfer amount returned. expected '5400.00 EUR' but got %s", transfer.Amount)
 	}
 
-	if transfer.Currency != "EUR" {
-		t.Errorf("wrong transfer currency returned. expected 'EUR' but got %s", transfer.Currency)
+	if !transfer.Credited.Equal(transfer.Amount) {
+		t.Errorf("wrong credited amount returned. expected %s but got %s", transfer.Amount, transfer.Credited)
 	}
 }
 
@@ -284,3 +351,755 @@ func Test_PostgresDBRepoValidateAccounts(t *testing.T) {
 		t.Errorf("should have 2 accounts, instead got %d", len(accounts))
 	}
 }
+
+func Test_PostgresDBRepoInterest(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2023, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+	cfg := domain.InterestConfig{
+		AccountID:        testAccountID,
+		AnnualRate:       decimal.RequireFromString("0.0365"),
+		DayCount:         domain.DayCountActual365,
+		Compounding:      domain.CompoundingSimple,
+		PostingFrequency: domain.PostingMonthly,
+		Enabled:          true,
+	}
+
+	err := testRepo.InterestRepository.UpsertConfig(ctx, &cfg)
+	if err != nil {
+		t.Errorf("error upserting interest config: %s", err)
+	}
+
+	configs, err := testRepo.InterestRepository.GetEnabledConfigs(ctx)
+	if err != nil || len(configs) != 1 {
+		t.Errorf("expected 1 enabled interest config but got %d (%v)", len(configs), err)
+	}
+
+	accrual := domain.InterestAccrual{
+		AccountID:   testAccountID,
+		AccrualDate: day,
+		Balance:     decimal.NewFromInt(200000),
+		AnnualRate:  cfg.AnnualRate,
+		Amount:      decimal.NewFromInt(20),
+	}
+
+	created, err := testRepo.InterestRepository.InsertAccrual(ctx, &accrual)
+	if err != nil || !created {
+		t.Errorf("expected accrual to be created, got %v (%v)", created, err)
+	}
+
+	created, err = testRepo.InterestRepository.InsertAccrual(ctx, &accrual)
+	if err != nil || created {
+		t.Errorf("expected second accrual for the same day to be skipped, got %v (%v)", created, err)
+	}
+
+	unposted, err := testRepo.InterestRepository.UnpostedInterest(ctx, testAccountID, day)
+	if err != nil || !unposted.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("expected 20 of unposted interest but got %v (%v)", unposted, err)
+	}
+
+	posting := domain.InterestPosting{
+		AccountID:     testAccountID,
+		PostingDate:   day,
+		AccruedAmount: unposted,
+		PostedAmount:  unposted,
+	}
+
+	reserved, err := testRepo.InterestRepository.InsertPosting(ctx, &posting)
+	if err != nil || !reserved {
+		t.Errorf("expected posting to be reserved, got %v (%v)", reserved, err)
+	}
+
+	reserved, err = testRepo.InterestRepository.InsertPosting(ctx, &posting)
+	if err != nil || reserved {
+		t.Errorf("expected second posting for the same day to be skipped, got %v (%v)", reserved, err)
+	}
+
+	unposted, _ = testRepo.InterestRepository.UnpostedInterest(ctx, testAccountID, day)
+	if !unposted.IsZero() {
+		t.Errorf("expected no unposted interest after posting but got %v", unposted)
+	}
+
+	incomplete, err := testRepo.InterestRepository.IncompletePostings(ctx)
+	if err != nil || len(incomplete) != 1 || incomplete[0].ID != posting.ID || incomplete[0].TransferID != nil {
+		t.Errorf("expected the reserved posting to be incomplete without a transfer but got %+v (%v)", incomplete, err)
+	}
+
+	last, err := testRepo.InterestRepository.LastAccrualDate(ctx, day)
+	if err != nil || !last.Equal(day) {
+		t.Errorf("expected the last accrual on %s but got %s (%v)", day, last, err)
+	}
+	if last, _ := testRepo.InterestRepository.LastAccrualDate(ctx, day.AddDate(0, 0, -1)); !last.IsZero() {
+		t.Errorf("expected no accrual before %s but got %s", day, last)
+	}
+}
+
+func Test_PostgresDBRepoLedgerReconciles(t *testing.T) {
+	ctx := context.Background()
+
+	accounts, _ := testRepo.AccountRepository.GetAll(ctx)
+	_, err := testRepo.TransferRepository.TransferTx(ctx, domain.TransferTxParams{
+		SourceAccountID: accounts[0].ID,
+		TargetAccountID: accounts[1].ID,
+		Amount:          domain.NewMoney(decimal.NewFromInt(1000), accounts[0].Currency),
+		TargetCurrency:  accounts[1].Currency,
+		Credit:          domain.NewMoney(decimal.NewFromInt(1000), accounts[1].Currency),
+	})
+	if err != nil {
+		t.Errorf("error making transfer: %s", err)
+	}
+
+	for _, acc := range accounts[:2] {
+		account, _ := testRepo.AccountRepository.Get(acc.ID)
+
+		balance, err := testRepo.LedgerRepository.BalanceAt(ctx, acc.ID, acc.Currency, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Errorf("error getting ledger balance: %s", err)
+		}
+
+		if !balance.Equal(account.Balance) {
+			t.Errorf("ledger balance %v does not match account balance %v", balance, account.Balance)
+		}
+	}
+
+	entries, err := testRepo.LedgerRepository.GetEntries(ctx, accounts[0].ID, accounts[0].Currency, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Errorf("error getting entries: %s", err)
+	}
+
+	last := entries[len(entries)-1]
+	if last.Kind != domain.EntryTransfer || !last.Amount.Equal(decimal.NewFromInt(-1000)) || *last.CounterpartyID != accounts[1].ID {
+		t.Errorf("unexpected last entry %+v", last)
+	}
+}
+
+func Test_PostgresDBRepoTransferTxAtomic(t *testing.T) {
+	ctx := context.Background()
+
+	accounts, _ := testRepo.AccountRepository.GetAll(ctx)
+	source, target := accounts[0], accounts[1]
+	transfers, _ := testRepo.TransferRepository.GetAll()
+
+	_, err := testRepo.TransferRepository.TransferTx(ctx, domain.TransferTxParams{
+		SourceAccountID: source.ID,
+		TargetAccountID: target.ID,
+		Amount:          domain.NewMoney(source.Balance.Add(decimal.NewFromInt(1)), source.Currency),
+		Credit:          domain.NewMoney(source.Balance.Add(decimal.NewFromInt(1)), target.Currency),
+	})
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("expected a transfer of more than the balance to be refused but got %v", err)
+	}
+
+	// The credit fails after the transfer is recorded and the source debited.
+	transferID := uuid.New()
+	_, err = testRepo.TransferRepository.TransferTx(ctx, domain.TransferTxParams{
+		TransferID:      transferID,
+		SourceAccountID: source.ID,
+		TargetAccountID: target.ID,
+		Amount:          domain.NewMoney(decimal.NewFromInt(10), source.Currency),
+		Credit:          domain.NewMoney(decimal.NewFromInt(10), "JPY"),
+	})
+	if !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("expected the credit of a missing pocket to fail but got %v", err)
+	}
+	if after, _ := testRepo.AccountRepository.Get(source.ID); !after.Balance.Equal(source.Balance) {
+		t.Errorf("expected the debit to be rolled back but the balance went from %v to %v", source.Balance, after.Balance)
+	}
+	if after, _ := testRepo.TransferRepository.GetAll(); len(after) != len(transfers) {
+		t.Errorf("expected no transfer to be recorded but got %d instead of %d", len(after), len(transfers))
+	}
+
+	// Retrying with the same transfer id succeeds once nothing fails.
+	_, err = testRepo.TransferRepository.TransferTx(ctx, domain.TransferTxParams{
+		TransferID:      transferID,
+		SourceAccountID: source.ID,
+		TargetAccountID: target.ID,
+		Amount:          domain.NewMoney(decimal.NewFromInt(10), source.Currency),
+		Credit:          domain.NewMoney(decimal.NewFromInt(10), target.Currency),
+	})
+	if err != nil {
+		t.Errorf("expected the retried transfer to be made but got %v", err)
+	}
+	if after, _ := testRepo.AccountRepository.Get(source.ID); !after.Balance.Equal(source.Balance.Sub(decimal.NewFromInt(10))) {
+		t.Errorf("expected the retried transfer to be debited once but got %v from %v", after.Balance, source.Balance)
+	}
+}
+
+func Test_PostgresDBRepoBalanceSnapshots(t *testing.T) {
+	ctx := context.Background()
+	accounts, _ := testRepo.AccountRepository.GetAll(ctx)
+
+	snapshotAt := time.Now().UTC()
+	written, err := testRepo.LedgerRepository.TakeSnapshots(ctx, snapshotAt)
+	if err != nil {
+		t.Errorf("error taking snapshots: %s", err)
+	}
+
+	if written != int64(len(accounts)) {
+		t.Errorf("expected %d snapshots but got %d", len(accounts), written)
+	}
+
+	written, _ = testRepo.LedgerRepository.TakeSnapshots(ctx, snapshotAt)
+	if written != 0 {
+		t.Errorf("expected repeated snapshot to write nothing but got %d", written)
+	}
+
+	_, err = testRepo.TransferRepository.AddAccountBalance(ctx, accounts[0].ID, decimal.NewFromInt(250))
+	if err != nil {
+		t.Errorf("error adding account balance: %s", err)
+	}
+
+	account, _ := testRepo.AccountRepository.Get(accounts[0].ID)
+	balance, _ := testRepo.LedgerRepository.BalanceAt(ctx, accounts[0].ID, accounts[0].Currency, time.Now().UTC().Add(time.Hour))
+	if !balance.Equal(account.Balance) {
+		t.Errorf("balance after snapshot %v does not match account balance %v", balance, account.Balance)
+	}
+
+	balance, _ = testRepo.LedgerRepository.BalanceAt(ctx, accounts[0].ID, accounts[0].Currency, snapshotAt)
+	if !balance.Equal(account.Balance.Sub(decimal.NewFromInt(250))) {
+		t.Errorf("balance at snapshot %v should not include later entries", balance)
+	}
+
+	balances, err := testRepo.LedgerRepository.BalancesAt(ctx, time.Now().UTC().Add(time.Hour))
+	if err != nil || len(balances) != len(accounts) {
+		t.Errorf("expected %d balances but got %d (%v)", len(accounts), len(balances), err)
+	}
+}
+
+func Test_PostgresDBRepoCustomer(t *testing.T) {
+	ctx := context.Background()
+
+	subject := "jane"
+	customer := domain.Customer{
+		Name:              "Jane Doe",
+		Email:             "jane@example.com",
+		Type:              domain.CustomerIndividual,
+		Status:            domain.CustomerActive,
+		RestrictTransfers: true,
+		Subject:           &subject,
+	}
+
+	err := testRepo.CustomerRepository.Insert(ctx, &customer)
+	if err != nil {
+		t.Errorf("insert customer returned an error: %s", err)
+	}
+
+	duplicate := customer
+	err = testRepo.CustomerRepository.Insert(ctx, &duplicate)
+	if !errors.Is(err, ErrDuplicateEmail) {
+		t.Errorf("expected duplicate email error but got %v", err)
+	}
+
+	duplicate.Email = "john@example.com"
+	err = testRepo.CustomerRepository.Insert(ctx, &duplicate)
+	if !errors.Is(err, ErrDuplicateSubject) {
+		t.Errorf("expected duplicate subject error but got %v", err)
+	}
+
+	found, err := testRepo.CustomerRepository.GetBySubject(ctx, subject)
+	if err != nil || found == nil || found.ID != customer.ID {
+		t.Errorf("expected to find customer %s by its subject but got %+v (%v)", customer.ID, found, err)
+	}
+	if missing, err := testRepo.CustomerRepository.GetBySubject(ctx, "unknown"); missing != nil || err != nil {
+		t.Errorf("expected no customer for an unknown subject but got %+v (%v)", missing, err)
+	}
+
+	accounts, _ := testRepo.AccountRepository.GetAll(ctx)
+	account := accounts[0]
+	account.CustomerID = &customer.ID
+
+	err = testRepo.AccountRepository.Update(&account)
+	if err != nil {
+		t.Errorf("error assigning account to customer: %s", err)
+	}
+
+	owned, err := testRepo.CustomerRepository.GetAccounts(ctx, customer.ID)
+	if err != nil || len(owned) != 1 || owned[0].ID != account.ID {
+		t.Errorf("expected customer to own account %s but got %v (%v)", account.ID, owned, err)
+	}
+
+	err = testRepo.CustomerRepository.AddBeneficiary(ctx, &domain.Beneficiary{CustomerID: customer.ID, AccountID: accounts[1].ID})
+	if err != nil {
+		t.Errorf("error adding beneficiary: %s", err)
+	}
+
+	allowed, err := testRepo.CustomerRepository.IsBeneficiary(ctx, customer.ID, accounts[1].ID)
+	if err != nil || !allowed {
+		t.Errorf("expected account %s to be a beneficiary, got %v (%v)", accounts[1].ID, allowed, err)
+	}
+
+	err = testRepo.CustomerRepository.RemoveBeneficiary(ctx, customer.ID, accounts[1].ID)
+	if err != nil {
+		t.Errorf("error removing beneficiary: %s", err)
+	}
+
+	allowed, _ = testRepo.CustomerRepository.IsBeneficiary(ctx, customer.ID, accounts[1].ID)
+	if allowed {
+		t.Errorf("expected account %s to no longer be a beneficiary", accounts[1].ID)
+	}
+}
+
+func Test_PostgresDBRepoWallet(t *testing.T) {
+	ctx := context.Background()
+
+	account := domain.Account{Balance: decimal.NewFromInt(100), Currency: "EUR"}
+	target := domain.Account{Balance: decimal.NewFromInt(100), Currency: "USD"}
+	_ = testRepo.AccountRepository.Insert(&account)
+	_ = testRepo.AccountRepository.Insert(&target)
+
+	if len(account.Pockets) != 1 || account.Pockets[0].Currency != account.Currency {
+		t.Errorf("expected a single %s pocket but got %+v", account.Currency, account.Pockets)
+	}
+
+	currency := "JPY"
+
+	pocket, err := testRepo.WalletRepository.OpenPocket(ctx, account.ID, currency)
+	if err != nil || !pocket.Balance.IsZero() {
+		t.Errorf("expected an empty %s pocket but got %+v (%v)", currency, pocket, err)
+	}
+
+	_, err = testRepo.WalletRepository.OpenPocket(ctx, account.ID, currency)
+	if !errors.Is(err, ErrPocketExists) {
+		t.Errorf("expected pocket exists error but got %v", err)
+	}
+
+	conversion := domain.Conversion{
+		AccountID: account.ID,
+		Amount:    domain.NewMoney(decimal.NewFromInt(10), account.Currency),
+		Converted: domain.NewMoney(decimal.NewFromInt(1500), currency),
+	}
+	err = testRepo.WalletRepository.Convert(ctx, &conversion)
+	if err != nil {
+		t.Errorf("error converting: %s", err)
+	}
+
+	if !conversion.From.Balance.Equal(account.Balance.Sub(decimal.NewFromInt(10))) || !conversion.To.Balance.Equal(decimal.NewFromInt(1500)) {
+		t.Errorf("unexpected pockets after conversion %+v %+v", conversion.From, conversion.To)
+	}
+
+	updated, _ := testRepo.AccountRepository.Get(account.ID)
+	if !updated.Balance.Equal(conversion.From.Balance) || len(updated.Pockets) != 2 {
+		t.Errorf("expected account balance %v and two pockets but got %+v", conversion.From.Balance, updated)
+	}
+
+	balance, _ := testRepo.LedgerRepository.BalanceAt(ctx, account.ID, currency, time.Now().UTC().Add(time.Hour))
+	if !balance.Equal(decimal.NewFromInt(1500)) {
+		t.Errorf("ledger balance of %s pocket %v does not match 1500", currency, balance)
+	}
+
+	conversion.Amount.Amount = updated.Balance.Add(decimal.NewFromInt(1))
+	err = testRepo.WalletRepository.Convert(ctx, &conversion)
+	if !errors.Is(err, ErrConversionFailed) {
+		t.Errorf("expected conversion to fail on insufficient balance but got %v", err)
+	}
+
+	_, err = testRepo.TransferRepository.TransferTx(ctx, domain.TransferTxParams{
+		SourceAccountID: account.ID,
+		TargetAccountID: target.ID,
+		Amount:          domain.NewMoney(decimal.NewFromInt(500), currency),
+		TargetCurrency:  target.Currency,
+		Credit:          domain.NewMoney(decimal.NewFromInt(4), target.Currency),
+	})
+	if err != nil {
+		t.Errorf("error transferring from %s pocket: %s", currency, err)
+	}
+
+	pockets, _ := testRepo.WalletRepository.GetPockets(ctx, account.ID)
+	for _, pocket := range pockets {
+		if pocket.Currency == currency && !pocket.Balance.Equal(decimal.NewFromInt(1000)) {
+			t.Errorf("expected 1000 left in %s pocket but got %v", currency, pocket.Balance)
+		}
+	}
+}
+
+func Test_PostgresDBRepoAPIKeys(t *testing.T) {
+	ctx := context.Background()
+
+	key := domain.APIKey{Client: "reporting", Prefix: "agt_0123456789ab", Hash: []byte("hash"), Scopes: []domain.Scope{domain.ScopeAccountsRead}}
+	err := testRepo.APIKeyRepository.Insert(ctx, &key)
+	if err != nil {
+		t.Fatalf("error inserting api key: %s", err)
+	}
+
+	found, err := testRepo.APIKeyRepository.GetByPrefix(ctx, key.Prefix)
+	if err != nil || found == nil || found.ID != key.ID || string(found.Hash) != "hash" || len(found.Scopes) != 1 || found.Scopes[0] != domain.ScopeAccountsRead {
+		t.Errorf("expected to find %+v by its prefix but got %+v (%v)", key, found, err)
+	}
+	if missing, err := testRepo.APIKeyRepository.GetByPrefix(ctx, "agt_unknown"); missing != nil || err != nil {
+		t.Errorf("expected no key for an unknown prefix but got %+v (%v)", missing, err)
+	}
+
+	at := time.Date(2023, time.June, 30, 12, 0, 0, 0, time.UTC)
+	_ = testRepo.APIKeyRepository.Touch(ctx, key.ID, at)
+	_ = testRepo.APIKeyRepository.Touch(ctx, key.ID, at.Add(-time.Hour))
+	_ = testRepo.APIKeyRepository.Expire(ctx, key.ID, at.Add(time.Hour))
+	_ = testRepo.APIKeyRepository.Revoke(ctx, key.ID, at)
+	_ = testRepo.APIKeyRepository.Revoke(ctx, key.ID, at.Add(time.Hour))
+
+	found, _ = testRepo.APIKeyRepository.Get(ctx, key.ID)
+	if found.LastUsedAt == nil || !found.LastUsedAt.Equal(at) {
+		t.Errorf("expected the key to be last used at %s but got %v", at, found.LastUsedAt)
+	}
+	if found.ExpiresAt == nil || !found.ExpiresAt.Equal(at.Add(time.Hour)) || found.RevokedAt == nil || !found.RevokedAt.Equal(at) {
+		t.Errorf("expected the key to expire at %s and be revoked at %s but got %v and %v", at.Add(time.Hour), at, found.ExpiresAt, found.RevokedAt)
+	}
+
+	if err := testRepo.APIKeyRepository.Revoke(ctx, uuid.New(), at); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("expected revoking an unknown key to fail with not found but got %v", err)
+	}
+}
+
+func Test_PostgresDBRepoRoles(t *testing.T) {
+	ctx := context.Background()
+
+	role := domain.Role{Name: "support", Permissions: []domain.Permission{domain.PermissionAccountsView}}
+	if err := testRepo.RoleRepository.Insert(ctx, &role); err != nil {
+		t.Fatalf("error inserting role: %s", err)
+	}
+	if err := testRepo.RoleRepository.Insert(ctx, &domain.Role{Name: "support"}); !errors.Is(err, ErrDuplicateRole) {
+		t.Errorf("expected a second support role to fail with ErrDuplicateRole but got %v", err)
+	}
+
+	role.Permissions = append(role.Permissions, domain.PermissionCustomersView)
+	if err := testRepo.RoleRepository.Update(ctx, &role); err != nil {
+		t.Fatalf("error updating role: %s", err)
+	}
+
+	roles, err := testRepo.RoleRepository.GetByNames(ctx, []string{"support", "unknown"})
+	if err != nil || len(roles) != 1 || len(roles[0].Permissions) != 2 || roles[0].Permissions[1] != domain.PermissionCustomersView {
+		t.Errorf("expected the updated support role but got %+v (%v)", roles, err)
+	}
+
+	if err := testRepo.RoleRepository.Delete(ctx, "support"); err != nil {
+		t.Fatalf("error deleting role: %s", err)
+	}
+	if _, err := testRepo.RoleRepository.Get(ctx, "support"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("expected the deleted role to be gone but got %v", err)
+	}
+
+	entry := domain.AuditEntry{OccurredAt: time.Now(), Actor: "user:jane", Action: domain.AuditAccessDenied, Resource: "GET /v1/customers/"}
+	if err := testRepo.AuditRepository.Insert(ctx, &entry); err != nil || entry.ID == 0 {
+		t.Errorf("expected the audit entry to be recorded but got id %d (%v)", entry.ID, err)
+	}
+	next := domain.AuditEntry{OccurredAt: time.Now(), Actor: "key:test", Action: domain.AuditAccountUpdated, Resource: "accounts/1", Before: json.RawMessage(`{"balance": "1.00"}`), After: json.RawMessage(`{"balance": "2.00"}`)}
+	if err := testRepo.AuditRepository.Insert(ctx, &next); err != nil || next.PrevHash != entry.Hash {
+		t.Errorf("expected the audit entry to be chained to the one before but got %+v (%v)", next, err)
+	}
+
+	entries, err := testRepo.AuditRepository.GetAll(ctx, domain.AuditFilter{Actor: "key:test", From: time.Now().Add(-time.Minute)})
+	if err != nil || len(entries) != 1 || string(entries[0].Before) != `{"balance": "1.00"}` {
+		t.Errorf("expected the filtered entry with its snapshots but got %+v (%v)", entries, err)
+	}
+	chain, err := testRepo.AuditRepository.GetChain(ctx, 0, 100)
+	if err != nil {
+		t.Fatalf("error reading the audit chain: %s", err)
+	}
+	var check domain.AuditChain
+	if !check.Check(chain) {
+		t.Errorf("expected the stored audit chain to be intact but got %+v", check.Result())
+	}
+
+	if _, err := testDB.ExecContext(ctx, `UPDATE audit_log SET actor = 'key:other'`); err == nil {
+		t.Errorf("expected audit entries to be impossible to change")
+	}
+	if _, err := testDB.ExecContext(ctx, `DELETE FROM audit_log`); err == nil {
+		t.Errorf("expected audit entries to be impossible to delete")
+	}
+}
+
+func Test_PostgresDBRepoApprovals(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	approval := domain.Approval{
+		Action:      domain.ApprovalTransfer,
+		Status:      domain.ApprovalPending,
+		AccountID:   uuid.New(),
+		Transfer:    &domain.TransferOrder{TargetAccountID: uuid.New(), Amount: decimal.NewFromInt(5000), SourceCurrency: "EUR"},
+		RequestedBy: "user:jane",
+		ExpiresAt:   now.Add(time.Hour),
+	}
+	if err := testRepo.ApprovalRepository.Insert(ctx, &approval); err != nil {
+		t.Fatalf("error inserting approval: %s", err)
+	}
+	stale := domain.Approval{Action: domain.ApprovalAccountClosure, Status: domain.ApprovalPending, AccountID: uuid.New(), Change: &domain.AccountChange{Version: 3}, RequestedBy: "user:jane", ExpiresAt: now.Add(-time.Minute)}
+	if err := testRepo.ApprovalRepository.Insert(ctx, &stale); err != nil {
+		t.Fatalf("error inserting approval: %s", err)
+	}
+
+	if err := testRepo.ApprovalRepository.Expire(ctx, now); err != nil {
+		t.Fatalf("error expiring approvals: %s", err)
+	}
+	pending, err := testRepo.ApprovalRepository.GetAll(ctx, domain.ApprovalPending)
+	if err != nil || len(pending) != 1 || pending[0].ID != approval.ID || pending[0].Transfer == nil || !pending[0].Transfer.Amount.Equal(decimal.NewFromInt(5000)) {
+		t.Errorf("expected only the fresh approval to be pending but got %+v (%v)", pending, err)
+	}
+
+	checker := "user:john"
+	approval.Status, approval.DecidedBy, approval.DecidedAt = domain.ApprovalApproved, &checker, &now
+	if decided, err := testRepo.ApprovalRepository.Decide(ctx, &approval); err != nil || !decided {
+		t.Fatalf("expected the approval to be decided but got %t (%v)", decided, err)
+	}
+	if decided, err := testRepo.ApprovalRepository.Decide(ctx, &approval); err != nil || decided {
+		t.Errorf("expected a decided approval not to be decided again but got %t (%v)", decided, err)
+	}
+
+	approval.Status, approval.Reason = domain.ApprovalFailed, "insufficient balance"
+	if err := testRepo.ApprovalRepository.Finish(ctx, &approval); err != nil {
+		t.Fatalf("error finishing approval: %s", err)
+	}
+	found, err := testRepo.ApprovalRepository.Get(ctx, approval.ID)
+	if err != nil || found.Status != domain.ApprovalFailed || found.DecidedBy == nil || *found.DecidedBy != checker || found.Change != nil {
+		t.Errorf("expected the failed approval but got %+v (%v)", found, err)
+	}
+
+	if _, err := testRepo.ApprovalRepository.Get(ctx, uuid.New()); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("expected an unknown approval to fail with not found but got %v", err)
+	}
+}
+
+func Test_PostgresDBRepoSigning(t *testing.T) {
+	ctx := context.Background()
+
+	secret := domain.SigningSecret{Client: "partner", Secret: "ags_secret"}
+	err := testRepo.SigningRepository.Insert(ctx, &secret)
+	if err != nil {
+		t.Fatalf("error inserting signing secret: %s", err)
+	}
+
+	at := time.Date(2023, time.June, 30, 12, 0, 0, 0, time.UTC)
+	_ = testRepo.SigningRepository.Expire(ctx, secret.ID, at.Add(time.Hour))
+	_ = testRepo.SigningRepository.Revoke(ctx, secret.ID, at)
+	_ = testRepo.SigningRepository.Revoke(ctx, secret.ID, at.Add(time.Hour))
+
+	found, err := testRepo.SigningRepository.GetByClient(ctx, "partner")
+	if err != nil || len(found) != 1 || found[0].ID != secret.ID || found[0].Secret != "ags_secret" {
+		t.Fatalf("expected to find %+v by its client but got %+v (%v)", secret, found, err)
+	}
+	if found[0].ExpiresAt == nil || !found[0].ExpiresAt.Equal(at.Add(time.Hour)) || found[0].RevokedAt == nil || !found[0].RevokedAt.Equal(at) {
+		t.Errorf("expected the secret to expire at %s and be revoked at %s but got %v and %v", at.Add(time.Hour), at, found[0].ExpiresAt, found[0].RevokedAt)
+	}
+	if _, err := testRepo.SigningRepository.Get(ctx, uuid.New()); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound for an unknown secret but got %v", err)
+	}
+
+	for i, fresh := range []bool{true, false} {
+		used, err := testRepo.SigningRepository.UseNonce(ctx, "partner", "n-1", at, at.Add(5*time.Minute))
+		if err != nil || used != fresh {
+			t.Errorf("use %d: expected the nonce to be fresh %t but got %t (%v)", i+1, fresh, used, err)
+		}
+	}
+	if used, err := testRepo.SigningRepository.UseNonce(ctx, "other", "n-1", at, at.Add(5*time.Minute)); err != nil || !used {
+		t.Errorf("expected the nonces of clients to be apart but got %t (%v)", used, err)
+	}
+	if used, err := testRepo.SigningRepository.UseNonce(ctx, "partner", "n-1", at.Add(5*time.Minute), at.Add(10*time.Minute)); err != nil || !used {
+		t.Errorf("expected an expired nonce to be usable again but got %t (%v)", used, err)
+	}
+
+	err = testRepo.SigningRepository.PurgeNonces(ctx, at.Add(time.Hour))
+	if err != nil {
+		t.Errorf("error purging nonces: %s", err)
+	}
+	if used, _ := testRepo.SigningRepository.UseNonce(ctx, "partner", "n-1", at, at.Add(5*time.Minute)); !used {
+		t.Error("expected the purged nonce to be forgotten")
+	}
+}
+
+func Test_PostgresDBRepoRateLimits(t *testing.T) {
+	ctx := context.Background()
+	limit := domain.RateLimit{Limit: 2, Period: time.Minute}
+	now := time.Now()
+
+	for i, allowed := range []bool{true, true, false} {
+		decision, err := testRepo.RateLimitRepository.Take(ctx, "transfer:client:key:test", limit, now)
+		if err != nil {
+			t.Fatalf("error taking a token: %s", err)
+		}
+		if decision.Allowed != allowed {
+			t.Errorf("request %d: expected allowed to be %t but got %+v", i+1, allowed, decision)
+		}
+	}
+
+	decision, err := testRepo.RateLimitRepository.Take(ctx, "transfer:client:key:test", limit, now.Add(30*time.Second))
+	if err != nil || !decision.Allowed || decision.Remaining != 0 {
+		t.Errorf("expected the bucket to have refilled one token but got %+v (%v)", decision, err)
+	}
+	if decision, err := testRepo.RateLimitRepository.Take(ctx, "transfer:client:key:other", limit, now); err != nil || decision.Remaining != 1 {
+		t.Errorf("expected another key to have a bucket of its own but got %+v (%v)", decision, err)
+	}
+
+	if err := testRepo.RateLimitRepository.PurgeBuckets(ctx, now.Add(10*time.Second)); err != nil {
+		t.Fatalf("error purging buckets: %s", err)
+	}
+	var keys []string
+	rows, _ := testDB.QueryContext(ctx, `SELECT key FROM rate_limit_buckets ORDER BY key`)
+	for rows.Next() {
+		var key string
+		_ = rows.Scan(&key)
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if len(keys) != 1 || keys[0] != "transfer:client:key:test" {
+		t.Errorf("expected only the bucket updated since to be kept but got %v", keys)
+	}
+}
+
+func Test_PostgresDBRepoIdempotencyKeys(t *testing.T) {
+	ctx := context.Background()
+	at := time.Now().UTC().Truncate(time.Second)
+	reserve := func(fingerprint string, now time.Time) (*domain.IdempotentResponse, error) {
+		return testRepo.IdempotencyRepository.Reserve(ctx, &domain.IdempotentResponse{
+			Key:         "key:test\nk-1",
+			Fingerprint: []byte(fingerprint),
+			ExpiresAt:   now.Add(domain.IdempotencyTimeout),
+		}, now)
+	}
+
+	if kept, err := reserve("a", at); err != nil || kept != nil {
+		t.Fatalf("expected the key to be reserved but got %+v (%v)", kept, err)
+	}
+	if kept, err := reserve("a", at); err != nil || kept == nil || kept.Done || string(kept.Fingerprint) != "a" {
+		t.Errorf("expected the key to be in use but got %+v (%v)", kept, err)
+	}
+
+	err := testRepo.IdempotencyRepository.Complete(ctx, &domain.IdempotentResponse{
+		Key:       "key:test\nk-1",
+		Done:      true,
+		Status:    201,
+		Header:    map[string][]string{"Location": {"/v1/accounts/1"}},
+		Body:      []byte(`{"id": 1}`),
+		ExpiresAt: at.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("error completing the key: %s", err)
+	}
+	// A completed key is not released.
+	_ = testRepo.IdempotencyRepository.Release(ctx, "key:test\nk-1")
+	kept, err := reserve("b", at.Add(30*time.Minute))
+	if err != nil || kept == nil || !kept.Done || kept.Status != 201 || kept.Header["Location"][0] != "/v1/accounts/1" || string(kept.Body) != `{"id": 1}` || string(kept.Fingerprint) != "a" {
+		t.Errorf("expected the response to be kept but got %+v (%v)", kept, err)
+	}
+
+	if kept, err := reserve("b", at.Add(time.Hour)); err != nil || kept != nil {
+		t.Errorf("expected the expired key to be reserved again but got %+v (%v)", kept, err)
+	}
+	if err := testRepo.IdempotencyRepository.Release(ctx, "key:test\nk-1"); err != nil {
+		t.Errorf("error releasing the key: %s", err)
+	}
+	if kept, err := reserve("c", at.Add(time.Hour)); err != nil || kept != nil {
+		t.Errorf("expected the released key to be reserved again but got %+v (%v)", kept, err)
+	}
+
+	err = testRepo.IdempotencyRepository.PurgeIdempotencyKeys(ctx, at.Add(2*time.Hour))
+	if err != nil {
+		t.Errorf("error purging idempotency keys: %s", err)
+	}
+	var count int
+	_ = testDB.QueryRow(`SELECT count(*) FROM idempotency_keys`).Scan(&count)
+	if count != 0 {
+		t.Errorf("expected the expired keys to be purged but %d are left", count)
+	}
+}
+
+func Test_PostgresDBRepoSchema(t *testing.T) {
+	ctx := context.Background()
+
+	// The test database is made from testdata/init_schema.sql, not by migrate.
+	version, dirty, err := testRepo.SchemaRepository.Version(ctx)
+	if err != nil || version != 0 || dirty {
+		t.Errorf("expected a database never migrated to be at 0 but got %d, %t (%v)", version, dirty, err)
+	}
+
+	_, err = testDB.Exec(`CREATE TABLE schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL);
+		INSERT INTO schema_migrations VALUES (16, true)`)
+	if err != nil {
+		t.Fatalf("error creating schema_migrations: %s", err)
+	}
+	defer testDB.Exec(`DROP TABLE schema_migrations`)
+
+	version, dirty, err = testRepo.SchemaRepository.Version(ctx)
+	if err != nil || version != 16 || !dirty {
+		t.Errorf("expected the dirty migration 16 but got %d, %t (%v)", version, dirty, err)
+	}
+
+	migrations, _ := filepath.Glob("../../../db/migration/*.up.sql")
+	if len(migrations) != SchemaVersion {
+		t.Errorf("expected SchemaVersion to be the latest of the %d migrations but it is %d", len(migrations), SchemaVersion)
+	}
+}
+
+func Test_PostgresDBRepoInterestPostingRetried(t *testing.T) {
+	ctx := context.Background()
+
+	expense := domain.Account{Balance: decimal.NewFromInt(1000), Currency: "EUR"}
+	saver := domain.Account{Balance: decimal.NewFromInt(100), Currency: "EUR"}
+	for _, acc := range []*domain.Account{&expense, &saver} {
+		if err := testRepo.AccountRepository.Insert(acc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	posting := domain.InterestPosting{
+		AccountID:     saver.ID,
+		PostingDate:   time.Date(2031, time.March, 1, 0, 0, 0, 0, time.UTC),
+		AccruedAmount: decimal.NewFromInt(5),
+		PostedAmount:  decimal.NewFromInt(5),
+	}
+	if reserved, err := testRepo.InterestRepository.InsertPosting(ctx, &posting); err != nil || !reserved {
+		t.Fatalf("expected the posting to be reserved but got %v (%v)", reserved, err)
+	}
+
+	// The credit of the saver fails once the transfer is recorded and the
+	// expense account debited.
+	_, err := testDB.Exec(fmt.Sprintf(`
+		CREATE FUNCTION fail_credit() RETURNS trigger AS $$ BEGIN RAISE EXCEPTION 'credit failed'; END $$ LANGUAGE plpgsql;
+		CREATE TRIGGER fail_credit BEFORE INSERT ON entries
+			FOR EACH ROW WHEN (NEW.account_id = '%s' AND NEW.amount > 0) EXECUTE FUNCTION fail_credit();`, saver.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transfers := services.NewTransferService(testRepo.TransferRepository, testRepo.CustomerRepository, nil, domain.ApprovalPolicy{})
+	interest := services.NewInterestService(testRepo.InterestRepository, testRepo.AccountRepository, testRepo.LedgerRepository, transfers, map[string]uuid.UUID{"EUR": expense.ID})
+
+	incomplete := func() bool {
+		postings, _ := testRepo.InterestRepository.IncompletePostings(ctx)
+		for _, p := range postings {
+			if p.ID == posting.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	if _, err := interest.Run(ctx, posting.PostingDate); err != nil {
+		t.Fatal(err)
+	}
+	if !incomplete() {
+		t.Error("expected the posting to stay incomplete while its credit fails")
+	}
+	if _, err := testRepo.TransferRepository.Get(posting.ID); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("expected the failed transfer to be rolled back but got %v", err)
+	}
+	if account, _ := testRepo.AccountRepository.Get(expense.ID); !account.Balance.Equal(expense.Balance) {
+		t.Errorf("expected the debit of the expense account to be rolled back but got %v", account.Balance)
+	}
+
+	if _, err := testDB.Exec(`DROP TRIGGER fail_credit ON entries; DROP FUNCTION fail_credit();`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := interest.Run(ctx, posting.PostingDate); err != nil {
+		t.Fatal(err)
+	}
+	if incomplete() {
+		t.Error("expected the retried posting to be completed")
+	}
+	if account, _ := testRepo.AccountRepository.Get(saver.ID); !account.Balance.Equal(decimal.NewFromInt(105)) {
+		t.Errorf("expected the saver to be paid once but the balance is %v", account.Balance)
+	}
+	// Only the transfer that was made is in the audit log.
+	entries, err := testRepo.AuditRepository.GetAll(ctx, domain.AuditFilter{Resource: "transfers/" + posting.ID.String()})
+	if err != nil || len(entries) != 1 || entries[0].Actor != domain.AuditActorInterest || entries[0].Action != domain.AuditTransferCreated {
+		t.Errorf("expected the interest transfer to be audited once but got %+v (%v)", entries, err)
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type RateLimitRepository struct {
+	DB *sql.DB
+}
+
+// Take locks the row of the bucket, creating it full if there is none, so
+// that instances sharing the database take tokens from it one at a time.
+func (l *RateLimitRepository) Take(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (decision domain.RateDecision, err error) {
+	tx, err := l.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return decision, err
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+			}
+		}
+	}()
+
+	now = now.UTC()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO NOTHING`, key, limit.Limit, now)
+	if err != nil {
+		return decision, err
+	}
+
+	var bucket domain.RateBucket
+	err = tx.QueryRowContext(ctx, `SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`, key).Scan(&bucket.Tokens, &bucket.UpdatedAt)
+	if err != nil {
+		return decision, err
+	}
+
+	decision = bucket.Take(limit, now)
+	_, err = tx.ExecContext(ctx, `UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1`, key, bucket.Tokens, bucket.UpdatedAt)
+	if err != nil {
+		return decision, err
+	}
+	return decision, tx.Commit()
+}
+
+func (l *RateLimitRepository) PurgeBuckets(ctx context.Context, at time.Time) error {
+	_, err := l.DB.ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < $1`, at.UTC())
+	return err
+}
+
+// MemoryRateLimitRepository keeps the buckets in memory, for a single
+// instance whose limits need not hold across others.
+type MemoryRateLimitRepository struct {
+	mu      sync.Mutex
+	buckets map[string]*domain.RateBucket
+}
+
+func NewMemoryRateLimitRepository() *MemoryRateLimitRepository {
+	return &MemoryRateLimitRepository{buckets: make(map[string]*domain.RateBucket)}
+}
+
+func (l *MemoryRateLimitRepository) Take(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (domain.RateDecision, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &domain.RateBucket{}
+		l.buckets[key] = bucket
+	}
+	return bucket.Take(limit, now), nil
+}
+
+func (l *MemoryRateLimitRepository) PurgeBuckets(ctx context.Context, at time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, bucket := range l.buckets {
+		if bucket.UpdatedAt.Before(at) {
+			delete(l.buckets, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package repositorytest
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type APIKeyRepository struct {
+	*store
+}
+
+// apiKey returns a copy of the stored key that shares nothing with it.
+func (s *store) apiKey(id uuid.UUID) (domain.APIKey, bool) {
+	key, ok := s.apiKeys[id]
+	if !ok {
+		return domain.APIKey{}, false
+	}
+	copied := *key
+	copied.Scopes = append([]domain.Scope(nil), key.Scopes...)
+	copied.Hash = append([]byte(nil), key.Hash...)
+	return copied, true
+}
+
+func (a *APIKeyRepository) Insert(ctx context.Context, key *domain.APIKey) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key.ID = uuid.New()
+	key.CreatedAt = a.now()
+
+	stored := *key
+	stored.Scopes = append([]domain.Scope(nil), key.Scopes...)
+	stored.Hash = append([]byte(nil), key.Hash...)
+	a.apiKeys[key.ID] = &stored
+	return nil
+}
+
+func (a *APIKeyRepository) Get(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key, ok := a.apiKey(id)
+	if !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+	return &key, nil
+}
+
+func (a *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for id, key := range a.apiKeys {
+		if key.Prefix == prefix {
+			copied, _ := a.apiKey(id)
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (a *APIKeyRepository) GetAll(ctx context.Context) ([]domain.APIKey, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var keys []domain.APIKey
+	for id := range a.apiKeys {
+		copied, _ := a.apiKey(id)
+		keys = append(keys, copied)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID.String() < keys[j].ID.String()
+	})
+	return keys, nil
+}
+
+func (a *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return a.update(id, func(key *domain.APIKey) {
+		if key.RevokedAt == nil {
+			key.RevokedAt = &at
+		}
+	})
+}
+
+func (a *APIKeyRepository) Expire(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return a.update(id, func(key *domain.APIKey) {
+		key.ExpiresAt = &at
+	})
+}
+
+func (a *APIKeyRepository) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return a.update(id, func(key *domain.APIKey) {
+		if key.LastUsedAt == nil || at.After(*key.LastUsedAt) {
+			key.LastUsedAt = &at
+		}
+	})
+}
+
+func (a *APIKeyRepository) update(id uuid.UUID, change func(*domain.APIKey)) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key, ok := a.apiKeys[id]
+	if !ok {
+		return repository.ErrRecordNotFound
+	}
+	change(key)
+	return nil
+}
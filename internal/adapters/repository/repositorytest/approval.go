@@ -0,0 +1,119 @@
+package repositorytest
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type ApprovalRepository struct {
+	*store
+}
+
+// approval returns a copy of the stored approval that shares nothing with
+// it.
+func (s *store) approval(id uuid.UUID) (domain.Approval, bool) {
+	approval, ok := s.approvals[id]
+	if !ok {
+		return domain.Approval{}, false
+	}
+	return copyApproval(approval), true
+}
+
+func copyApproval(approval *domain.Approval) domain.Approval {
+	copied := *approval
+	if approval.Transfer != nil {
+		transfer := *approval.Transfer
+		copied.Transfer = &transfer
+	}
+	if approval.Change != nil {
+		change := *approval.Change
+		copied.Change = &change
+	}
+	return copied
+}
+
+func (a *ApprovalRepository) Insert(ctx context.Context, approval *domain.Approval) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	approval.ID = uuid.New()
+	approval.CreatedAt = a.now()
+
+	stored := copyApproval(approval)
+	a.approvals[approval.ID] = &stored
+	return nil
+}
+
+func (a *ApprovalRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Approval, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	approval, ok := a.approval(id)
+	if !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+	return &approval, nil
+}
+
+func (a *ApprovalRepository) GetAll(ctx context.Context, status domain.ApprovalStatus) ([]domain.Approval, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var approvals []domain.Approval
+	for id, approval := range a.approvals {
+		if status == "" || approval.Status == status {
+			copied, _ := a.approval(id)
+			approvals = append(approvals, copied)
+		}
+	}
+	sort.Slice(approvals, func(i, j int) bool {
+		if !approvals[i].CreatedAt.Equal(approvals[j].CreatedAt) {
+			return approvals[i].CreatedAt.After(approvals[j].CreatedAt)
+		}
+		return approvals[i].ID.String() < approvals[j].ID.String()
+	})
+	return approvals, nil
+}
+
+func (a *ApprovalRepository) Decide(ctx context.Context, approval *domain.Approval) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stored, ok := a.approvals[approval.ID]
+	if !ok || stored.Status != domain.ApprovalPending || !stored.ExpiresAt.After(*approval.DecidedAt) {
+		return false, nil
+	}
+	stored.Status, stored.Reason = approval.Status, approval.Reason
+	decidedBy, decidedAt := *approval.DecidedBy, *approval.DecidedAt
+	stored.DecidedBy, stored.DecidedAt = &decidedBy, &decidedAt
+	return true, nil
+}
+
+func (a *ApprovalRepository) Finish(ctx context.Context, approval *domain.Approval) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stored, ok := a.approvals[approval.ID]
+	if !ok {
+		return repository.ErrRecordNotFound
+	}
+	stored.Status, stored.Reason, stored.TransferID = approval.Status, approval.Reason, approval.TransferID
+	return nil
+}
+
+func (a *ApprovalRepository) Expire(ctx context.Context, at time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, approval := range a.approvals {
+		if approval.Status == domain.ApprovalPending && !approval.ExpiresAt.After(at) {
+			approval.Status = domain.ApprovalExpired
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package repositorytest
+
+import (
+	"context"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type AuditRepository struct {
+	*store
+}
+
+func (a *AuditRepository) Insert(ctx context.Context, entry *domain.AuditEntry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.auditErr != nil {
+		return a.auditErr
+	}
+	a.appendAudit(entry)
+	return nil
+}
+
+// appendAudit seals the entry to the last one and appends it. The caller
+// holds the lock.
+func (s *store) appendAudit(entry *domain.AuditEntry) {
+	var prev string
+	if n := len(s.audit); n > 0 {
+		prev = s.audit[n-1].Hash
+	}
+	entry.Seal(prev)
+	entry.ID = int64(len(s.audit) + 1)
+	s.audit = append(s.audit, copyEntry(entry))
+}
+
+func (a *AuditRepository) GetAll(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var entries []domain.AuditEntry
+	for i := len(a.audit) - 1; i >= 0; i-- {
+		e := &a.audit[i]
+		switch {
+		case filter.Actor != "" && e.Actor != filter.Actor,
+			filter.Action != "" && e.Action != filter.Action,
+			filter.Resource != "" && e.Resource != filter.Resource,
+			!filter.From.IsZero() && e.OccurredAt.Before(filter.From),
+			!filter.To.IsZero() && !e.OccurredAt.Before(filter.To):
+			continue
+		}
+		entries = append(entries, copyEntry(e))
+	}
+	return entries, nil
+}
+
+func (a *AuditRepository) GetChain(ctx context.Context, afterID int64, limit int) ([]domain.AuditEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var entries []domain.AuditEntry
+	for i := range a.audit {
+		if a.audit[i].ID > afterID && len(entries) < limit {
+			entries = append(entries, copyEntry(&a.audit[i]))
+		}
+	}
+	return entries, nil
+}
+
+// Entries returns the entries recorded so far, oldest first, for tests to
+// look at.
+func (a *AuditRepository) Entries() []domain.AuditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entries := make([]domain.AuditEntry, len(a.audit))
+	for i := range a.audit {
+		entries[i] = copyEntry(&a.audit[i])
+	}
+	return entries
+}
+
+// FailWith makes recording entries fail with err, until it is called with
+// nil, for tests of what happens when the log cannot be written.
+func (a *AuditRepository) FailWith(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.auditErr = err
+}
+
+// Tamper lets tests change a stored entry, as someone with access to the
+// database could, to check that the change is detected.
+func (a *AuditRepository) Tamper(id int64, change func(*domain.AuditEntry)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if id >= 1 && id <= int64(len(a.audit)) {
+		change(&a.audit[id-1])
+	}
+}
+
+// copyEntry returns a copy of the entry that shares no snapshot with it.
+func copyEntry(e *domain.AuditEntry) domain.AuditEntry {
+	copied := *e
+	copied.Before = append([]byte(nil), e.Before...)
+	copied.After = append([]byte(nil), e.After...)
+	return copied
+}
@@ -0,0 +1,202 @@
+package repositorytest
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type CustomerRepository struct {
+	*store
+}
+
+func (c *CustomerRepository) Insert(ctx context.Context, customer *domain.Customer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.emailTaken(customer.Email, uuid.Nil) {
+		return repository.ErrDuplicateEmail
+	}
+	if c.subjectTaken(customer.Subject, uuid.Nil) {
+		return repository.ErrDuplicateSubject
+	}
+
+	customer.ID = uuid.New()
+	customer.CreatedAt = c.now()
+	customer.UpdatedAt = customer.CreatedAt
+
+	stored := *customer
+	c.customers[customer.ID] = &stored
+	return nil
+}
+
+func (c *CustomerRepository) emailTaken(email string, except uuid.UUID) bool {
+	for id, customer := range c.customers {
+		if id != except && customer.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CustomerRepository) subjectTaken(subject *string, except uuid.UUID) bool {
+	if subject == nil {
+		return false
+	}
+	for id, customer := range c.customers {
+		if id != except && customer.Subject != nil && *customer.Subject == *subject {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CustomerRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	customer, ok := c.customers[id]
+	if !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+	copied := *customer
+	return &copied, nil
+}
+
+func (c *CustomerRepository) GetBySubject(ctx context.Context, subject string) (*domain.Customer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, customer := range c.customers {
+		if customer.Subject != nil && *customer.Subject == subject {
+			copied := *customer
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *CustomerRepository) Update(ctx context.Context, customer *domain.Customer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored, ok := c.customers[customer.ID]
+	if !ok {
+		return repository.ErrRecordNotFound
+	}
+	if c.emailTaken(customer.Email, customer.ID) {
+		return repository.ErrDuplicateEmail
+	}
+	if c.subjectTaken(customer.Subject, customer.ID) {
+		return repository.ErrDuplicateSubject
+	}
+
+	customer.CreatedAt = stored.CreatedAt
+	customer.UpdatedAt = c.now()
+	*stored = *customer
+	return nil
+}
+
+func (c *CustomerRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.customers[id]; !ok {
+		return repository.ErrRecordNotFound
+	}
+	delete(c.customers, id)
+
+	beneficiaries := c.beneficiaries[:0]
+	for _, beneficiary := range c.beneficiaries {
+		if beneficiary.CustomerID != id {
+			beneficiaries = append(beneficiaries, beneficiary)
+		}
+	}
+	c.beneficiaries = beneficiaries
+	return nil
+}
+
+func (c *CustomerRepository) GetAll(ctx context.Context) ([]domain.Customer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var customers []domain.Customer
+	for _, customer := range c.customers {
+		customers = append(customers, *customer)
+	}
+	sort.Slice(customers, func(i, j int) bool {
+		if !customers[i].CreatedAt.Equal(customers[j].CreatedAt) {
+			return customers[i].CreatedAt.Before(customers[j].CreatedAt)
+		}
+		return customers[i].ID.String() < customers[j].ID.String()
+	})
+	return customers, nil
+}
+
+func (c *CustomerRepository) GetAccounts(ctx context.Context, id uuid.UUID) ([]domain.Account, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.sortedAccounts(func(acc *domain.Account) bool {
+		return acc.CustomerID != nil && *acc.CustomerID == id
+	}), nil
+}
+
+// AddBeneficiary stores the beneficiary, keeping the original one when the
+// account is already a beneficiary of the customer.
+func (c *CustomerRepository) AddBeneficiary(ctx context.Context, beneficiary *domain.Beneficiary) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, existing := range c.beneficiaries {
+		if existing.CustomerID == beneficiary.CustomerID && existing.AccountID == beneficiary.AccountID {
+			beneficiary.CreatedAt = existing.CreatedAt
+			return nil
+		}
+	}
+
+	beneficiary.CreatedAt = c.now()
+	c.beneficiaries = append(c.beneficiaries, *beneficiary)
+	return nil
+}
+
+func (c *CustomerRepository) RemoveBeneficiary(ctx context.Context, customerID, accountID uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, beneficiary := range c.beneficiaries {
+		if beneficiary.CustomerID == customerID && beneficiary.AccountID == accountID {
+			c.beneficiaries = append(c.beneficiaries[:i], c.beneficiaries[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrRecordNotFound
+}
+
+func (c *CustomerRepository) GetBeneficiaries(ctx context.Context, customerID uuid.UUID) ([]domain.Beneficiary, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var beneficiaries []domain.Beneficiary
+	for _, beneficiary := range c.beneficiaries {
+		if beneficiary.CustomerID == customerID {
+			beneficiaries = append(beneficiaries, beneficiary)
+		}
+	}
+	return beneficiaries, nil
+}
+
+func (c *CustomerRepository) IsBeneficiary(ctx context.Context, customerID, accountID uuid.UUID) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, beneficiary := range c.beneficiaries {
+		if beneficiary.CustomerID == customerID && beneficiary.AccountID == accountID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
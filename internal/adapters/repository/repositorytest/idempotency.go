@@ -0,0 +1,62 @@
+package repositorytest
+
+import (
+	"context"
+	"time"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type IdempotencyRepository struct {
+	*store
+}
+
+func (i *IdempotencyRepository) Reserve(ctx context.Context, response *domain.IdempotentResponse, now time.Time) (*domain.IdempotentResponse, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if kept, ok := i.responses[response.Key]; ok && now.Before(kept.ExpiresAt) {
+		copied := *kept
+		return &copied, nil
+	}
+	stored := *response
+	stored.Done = false
+	i.responses[response.Key] = &stored
+	return nil, nil
+}
+
+func (i *IdempotencyRepository) Complete(ctx context.Context, response *domain.IdempotentResponse) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	kept, ok := i.responses[response.Key]
+	if !ok {
+		return nil
+	}
+	fingerprint := kept.Fingerprint
+	*kept = *response
+	kept.Fingerprint = fingerprint
+	return nil
+}
+
+func (i *IdempotencyRepository) Release(ctx context.Context, key string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if kept, ok := i.responses[key]; ok && !kept.Done {
+		delete(i.responses, key)
+	}
+	return nil
+}
+
+func (i *IdempotencyRepository) PurgeIdempotencyKeys(ctx context.Context, at time.Time) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for key, kept := range i.responses {
+		if kept.ExpiresAt.Before(at) {
+			delete(i.responses, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package repositorytest
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/shopspring/decimal"
+)
+
+type InterestRepository struct {
+	*store
+}
+
+func (i *InterestRepository) GetConfig(ctx context.Context, accountID uuid.UUID) (*domain.InterestConfig, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	cfg, ok := i.configs[accountID]
+	if !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+	copied := *cfg
+	return &copied, nil
+}
+
+func (i *InterestRepository) UpsertConfig(ctx context.Context, cfg *domain.InterestConfig) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	cfg.UpdatedAt = i.now()
+	cfg.CreatedAt = cfg.UpdatedAt
+	if existing, ok := i.configs[cfg.AccountID]; ok {
+		cfg.CreatedAt = existing.CreatedAt
+	}
+
+	stored := *cfg
+	i.configs[cfg.AccountID] = &stored
+	return nil
+}
+
+func (i *InterestRepository) GetEnabledConfigs(ctx context.Context) ([]domain.InterestConfig, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var configs []domain.InterestConfig
+	for _, cfg := range i.configs {
+		if cfg.Enabled {
+			configs = append(configs, *cfg)
+		}
+	}
+	sort.Slice(configs, func(a, b int) bool {
+		return configs[a].AccountID.String() < configs[b].AccountID.String()
+	})
+	return configs, nil
+}
+
+// InsertAccrual stores the accrual unless one already exists for the same
+// account and day, and reports whether it was stored.
+func (i *InterestRepository) InsertAccrual(ctx context.Context, accrual *domain.InterestAccrual) (bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, existing := range i.accruals {
+		if existing.AccountID == accrual.AccountID && existing.AccrualDate.Equal(accrual.AccrualDate) {
+			return false, nil
+		}
+	}
+
+	accrual.ID = uuid.New()
+	accrual.CreatedAt = i.now()
+	i.accruals = append(i.accruals, *accrual)
+	return true, nil
+}
+
+func (i *InterestRepository) GetAccruals(ctx context.Context, accountID uuid.UUID) ([]domain.InterestAccrual, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var accruals []domain.InterestAccrual
+	for _, accrual := range i.accruals {
+		if accrual.AccountID == accountID {
+			accruals = append(accruals, accrual)
+		}
+	}
+	sort.Slice(accruals, func(a, b int) bool {
+		return accruals[a].AccrualDate.Before(accruals[b].AccrualDate)
+	})
+	return accruals, nil
+}
+
+// UnpostedInterest returns the interest accrued up to and including the given
+// day that has not been paid out by a posting yet.
+func (i *InterestRepository) UnpostedInterest(ctx context.Context, accountID uuid.UUID, upTo time.Time) (decimal.Decimal, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	unposted := decimal.Zero
+	for _, accrual := range i.accruals {
+		if accrual.AccountID == accountID && !accrual.AccrualDate.After(upTo) {
+			unposted = unposted.Add(accrual.Amount)
+		}
+	}
+	for _, posting := range i.postings {
+		if posting.AccountID == accountID {
+			unposted = unposted.Sub(posting.PostedAmount)
+		}
+	}
+	return unposted, nil
+}
+
+// InsertPosting reserves the posting for the account and day, and reports
+// false if the day has already been posted.
+func (i *InterestRepository) InsertPosting(ctx context.Context, posting *domain.InterestPosting) (bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, existing := range i.postings {
+		if existing.AccountID == posting.AccountID && existing.PostingDate.Equal(posting.PostingDate) {
+			return false, nil
+		}
+	}
+
+	posting.ID = uuid.New()
+	posting.CreatedAt = i.now()
+	i.postings = append(i.postings, *posting)
+	return true, nil
+}
+
+func (i *InterestRepository) CompletePosting(ctx context.Context, postingID, transferID uuid.UUID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for n := range i.postings {
+		if i.postings[n].ID == postingID {
+			i.postings[n].TransferID = &transferID
+		}
+	}
+	return nil
+}
+
+// IncompletePostings returns the reserved postings that were never marked
+// complete. Their TransferID is set when the transfer of the posting, which
+// has the posting's id, was made nonetheless.
+func (i *InterestRepository) IncompletePostings(ctx context.Context) ([]domain.InterestPosting, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var postings []domain.InterestPosting
+	for _, posting := range i.postings {
+		if posting.TransferID != nil {
+			continue
+		}
+		for _, transfer := range i.transfers {
+			if transfer.ID == posting.ID {
+				id := transfer.ID
+				posting.TransferID = &id
+			}
+		}
+		postings = append(postings, posting)
+	}
+	sort.Slice(postings, func(a, b int) bool {
+		if !postings[a].PostingDate.Equal(postings[b].PostingDate) {
+			return postings[a].PostingDate.Before(postings[b].PostingDate)
+		}
+		return postings[a].AccountID.String() < postings[b].AccountID.String()
+	})
+	return postings, nil
+}
+
+// LastAccrualDate returns the latest day up to and including upTo interest
+// was accrued for, the zero time when none was.
+func (i *InterestRepository) LastAccrualDate(ctx context.Context, upTo time.Time) (time.Time, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var last time.Time
+	for _, accrual := range i.accruals {
+		if accrual.AccrualDate.After(last) && !accrual.AccrualDate.After(upTo) {
+			last = accrual.AccrualDate
+		}
+	}
+	return last, nil
+}
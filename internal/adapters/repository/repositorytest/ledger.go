@@ -0,0 +1,124 @@
+package repositorytest
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/shopspring/decimal"
+)
+
+type LedgerRepository struct {
+	*store
+}
+
+// BalanceAt returns the balance of an account pocket just before the given
+// instant, starting from the latest snapshot taken at or before it.
+func (l *LedgerRepository) BalanceAt(ctx context.Context, accountID uuid.UUID, code string, at time.Time) (decimal.Decimal, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.balanceAt(accountID, code, at), nil
+}
+
+func (l *LedgerRepository) balanceAt(accountID uuid.UUID, code string, at time.Time) decimal.Decimal {
+	var since time.Time
+	balance := decimal.Zero
+	for key, snapshot := range l.snapshots {
+		if key.accountID == accountID && key.currency == code && !key.takenAt.After(at) && key.takenAt.After(since) {
+			since, balance = key.takenAt, snapshot
+		}
+	}
+
+	for _, entry := range l.entries {
+		if entry.AccountID == accountID && entry.Currency == code && entry.CreatedAt.Before(at) && !entry.CreatedAt.Before(since) {
+			balance = balance.Add(entry.Amount)
+		}
+	}
+	return balance
+}
+
+// BalancesAt returns the balance of every account pocket that existed just
+// before the given instant.
+func (l *LedgerRepository) BalancesAt(ctx context.Context, at time.Time) ([]domain.Balance, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.balancesAt(at), nil
+}
+
+func (l *LedgerRepository) balancesAt(at time.Time) []domain.Balance {
+	var balances []domain.Balance
+	for _, acc := range l.accounts {
+		for _, pocket := range acc.Pockets {
+			if !pocket.CreatedAt.Before(at) {
+				continue
+			}
+			balances = append(balances, domain.Balance{
+				AccountID: acc.ID,
+				Currency:  pocket.Currency,
+				Balance:   l.balanceAt(acc.ID, pocket.Currency, at),
+				AsOf:      at,
+			})
+		}
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].AccountID != balances[j].AccountID {
+			return balances[i].AccountID.String() < balances[j].AccountID.String()
+		}
+		return balances[i].Currency < balances[j].Currency
+	})
+	return balances
+}
+
+// TakeSnapshots stores the balance of every account pocket just before the
+// given instant, leaving existing snapshots for that instant untouched.
+func (l *LedgerRepository) TakeSnapshots(ctx context.Context, at time.Time) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var taken int64
+	for _, balance := range l.balancesAt(at) {
+		key := snapshotKey{balance.AccountID, balance.Currency, at}
+		if _, ok := l.snapshots[key]; ok {
+			continue
+		}
+		l.snapshots[key] = balance.Balance
+		taken++
+	}
+	return taken, nil
+}
+
+// GetEntries returns the entries of an account pocket created in [from, to),
+// in the order they were applied.
+func (l *LedgerRepository) GetEntries(ctx context.Context, accountID uuid.UUID, code string, from, to time.Time) ([]domain.Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var entries []domain.Entry
+	for _, entry := range l.entries {
+		if entry.AccountID != accountID || entry.Currency != code || entry.CreatedAt.Before(from) || !entry.CreatedAt.Before(to) {
+			continue
+		}
+		if entry.TransferID != nil {
+			entry.CounterpartyID = l.counterparty(*entry.TransferID, accountID)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (l *LedgerRepository) counterparty(transferID, accountID uuid.UUID) *uuid.UUID {
+	for _, tx := range l.transfers {
+		if tx.ID != transferID {
+			continue
+		}
+		if tx.SourceAccountID == accountID {
+			return &tx.TargetAccountID
+		}
+		return &tx.SourceAccountID
+	}
+	return nil
+}
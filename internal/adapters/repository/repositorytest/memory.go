@@ -0,0 +1,430 @@
+// Package repositorytest is a repository adapter for tests that keeps
+// everything in memory. It behaves like the Postgres adapter, down to the
+// errors it returns, so that tests need no database.
+package repositorytest
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/shopspring/decimal"
+)
+
+// Repository bundles the in-memory adapters for every port. They all share
+// the same data.
+type Repository struct {
+	*AccountRepository
+	*TransferRepository
+	*InterestRepository
+	*LedgerRepository
+	*CustomerRepository
+	*WalletRepository
+	*APIKeyRepository
+	*RoleRepository
+	*AuditRepository
+	*ApprovalRepository
+	*RateLimitRepository
+	*SigningRepository
+	*IdempotencyRepository
+}
+
+func NewRepository() *Repository {
+	s := &store{
+		accounts:  make(map[uuid.UUID]*domain.Account),
+		customers: make(map[uuid.UUID]*domain.Customer),
+		configs:   make(map[uuid.UUID]*domain.InterestConfig),
+		apiKeys:   make(map[uuid.UUID]*domain.APIKey),
+		roles:     make(map[string]*domain.Role),
+		approvals: make(map[uuid.UUID]*domain.Approval),
+		secrets:   make(map[uuid.UUID]*domain.SigningSecret),
+		nonces:    make(map[nonceKey]time.Time),
+		responses: make(map[string]*domain.IdempotentResponse),
+		snapshots: make(map[snapshotKey]decimal.Decimal),
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+
+	return &Repository{
+		&AccountRepository{s},
+		&TransferRepository{s},
+		&InterestRepository{s},
+		&LedgerRepository{s},
+		&CustomerRepository{s},
+		&WalletRepository{s},
+		&APIKeyRepository{s},
+		&RoleRepository{s},
+		&AuditRepository{s},
+		&ApprovalRepository{s},
+		repository.NewMemoryRateLimitRepository(),
+		&SigningRepository{s},
+		&IdempotencyRepository{s},
+	}
+}
+
+// RateLimitRepository is the in-memory store of rate limits the service
+// uses itself.
+type RateLimitRepository = repository.MemoryRateLimitRepository
+
+type snapshotKey struct {
+	accountID uuid.UUID
+	currency  string
+	takenAt   time.Time
+}
+
+type store struct {
+	mu sync.Mutex
+
+	accounts      map[uuid.UUID]*domain.Account
+	transfers     []domain.Transfer
+	entries       []domain.Entry
+	snapshots     map[snapshotKey]decimal.Decimal
+	customers     map[uuid.UUID]*domain.Customer
+	beneficiaries []domain.Beneficiary
+	configs       map[uuid.UUID]*domain.InterestConfig
+	accruals      []domain.InterestAccrual
+	postings      []domain.InterestPosting
+	apiKeys       map[uuid.UUID]*domain.APIKey
+	roles         map[string]*domain.Role
+	audit         []domain.AuditEntry
+	auditErr      error
+	approvals     map[uuid.UUID]*domain.Approval
+	secrets       map[uuid.UUID]*domain.SigningSecret
+	nonces        map[nonceKey]time.Time
+	responses     map[string]*domain.IdempotentResponse
+
+	now func() time.Time
+}
+
+// account returns a copy of the stored account that shares nothing with it.
+func (s *store) account(id uuid.UUID) (domain.Account, bool) {
+	acc, ok := s.accounts[id]
+	if !ok {
+		return domain.Account{}, false
+	}
+	copied := *acc
+	copied.Pockets = append([]domain.Pocket(nil), acc.Pockets...)
+	return copied, true
+}
+
+// sortedAccounts returns copies of the accounts that pass keep, ordered by
+// id like the Postgres adapter does.
+func (s *store) sortedAccounts(keep func(*domain.Account) bool) []domain.Account {
+	var accounts []domain.Account
+	for id, acc := range s.accounts {
+		if keep(acc) {
+			copied, _ := s.account(id)
+			accounts = append(accounts, copied)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].ID.String() < accounts[j].ID.String()
+	})
+	return accounts
+}
+
+// addBalance adds amount to the pocket of its currency, the base pocket when
+// the currency is empty, keeps the base balance of the account in step and
+// records the change in the ledger.
+func (s *store) addBalance(id uuid.UUID, amount domain.Money, transferID *uuid.UUID, kind domain.EntryKind) (domain.Account, error) {
+	acc, ok := s.accounts[id]
+	if !ok {
+		return domain.Account{}, repository.ErrRecordNotFound
+	}
+	code := amount.Currency
+	if code == "" {
+		code = acc.Currency
+	}
+
+	i := pocketIndex(acc, code)
+	if i < 0 {
+		return domain.Account{}, repository.ErrRecordNotFound
+	}
+	acc.Pockets[i].Balance = acc.Pockets[i].Balance.Add(amount.Amount)
+	if code == acc.Currency {
+		acc.Balance = acc.Pockets[i].Balance
+	}
+	acc.Version++
+
+	s.addEntry(id, code, transferID, kind, amount.Amount)
+
+	copied, _ := s.account(id)
+	return copied, nil
+}
+
+func (s *store) addEntry(accountID uuid.UUID, code string, transferID *uuid.UUID, kind domain.EntryKind, amount decimal.Decimal) {
+	s.entries = append(s.entries, domain.Entry{
+		ID:         uuid.New(),
+		AccountID:  accountID,
+		Currency:   code,
+		TransferID: transferID,
+		Kind:       kind,
+		Amount:     amount,
+		CreatedAt:  s.now(),
+	})
+}
+
+func pocketIndex(acc *domain.Account, code string) int {
+	for i, pocket := range acc.Pockets {
+		if pocket.Currency == code {
+			return i
+		}
+	}
+	return -1
+}
+
+type AccountRepository struct {
+	*store
+}
+
+func (a *AccountRepository) Insert(acc *domain.Account) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if acc.CustomerID != nil {
+		if _, ok := a.customers[*acc.CustomerID]; !ok {
+			return repository.ErrUnknownCustomer
+		}
+	}
+
+	acc.ID = uuid.New()
+	acc.CreatedAt = a.now()
+	acc.Version = 1
+	acc.Pockets = []domain.Pocket{{Currency: acc.Currency, Balance: acc.Balance, CreatedAt: acc.CreatedAt}}
+
+	stored := *acc
+	stored.Pockets = append([]domain.Pocket(nil), acc.Pockets...)
+	a.accounts[acc.ID] = &stored
+
+	a.addEntry(acc.ID, acc.Currency, nil, domain.EntryOpening, acc.Balance)
+	return nil
+}
+
+func (a *AccountRepository) Get(id uuid.UUID) (*domain.Account, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	acc, ok := a.account(id)
+	if !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+	return &acc, nil
+}
+
+// Update sets the base pocket of the account to the given balance and
+// currency, recording the difference as an adjustment. It fails with
+// ErrEditConflict when the account is no longer at account.Version.
+func (a *AccountRepository) Update(account *domain.Account) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	acc, ok := a.accounts[account.ID]
+	if !ok {
+		return repository.ErrRecordNotFound
+	}
+	if acc.Version != account.Version {
+		return repository.ErrEditConflict
+	}
+	if account.CustomerID != nil {
+		if _, ok := a.customers[*account.CustomerID]; !ok {
+			return repository.ErrUnknownCustomer
+		}
+	}
+
+	base := pocketIndex(acc, acc.Currency)
+	if account.Currency != acc.Currency {
+		for _, entry := range a.entries {
+			if entry.AccountID == acc.ID && !entry.Amount.IsZero() {
+				return repository.ErrCurrencyInUse
+			}
+		}
+		if pocketIndex(acc, account.Currency) >= 0 {
+			return repository.ErrPocketExists
+		}
+		acc.Pockets[base].Currency = account.Currency
+	}
+
+	if difference := account.Balance.Sub(acc.Balance); !difference.IsZero() {
+		a.addEntry(acc.ID, account.Currency, nil, domain.EntryAdjustment, difference)
+	}
+	acc.Pockets[base].Balance = account.Balance
+	acc.Balance, acc.Currency, acc.CustomerID = account.Balance, account.Currency, account.CustomerID
+	acc.Version++
+
+	updated, _ := a.account(acc.ID)
+	*account = updated
+	return nil
+}
+
+func (a *AccountRepository) SetFrozen(id uuid.UUID, frozen bool) (*domain.Account, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	acc, ok := a.accounts[id]
+	if !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+	if acc.Frozen != frozen {
+		acc.Frozen = frozen
+		acc.Version++
+	}
+
+	updated, _ := a.account(id)
+	return &updated, nil
+}
+
+func (a *AccountRepository) Delete(id uuid.UUID, version int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	acc, ok := a.accounts[id]
+	if !ok {
+		return repository.ErrRecordNotFound
+	}
+	if acc.Version != version {
+		return repository.ErrEditConflict
+	}
+	delete(a.accounts, id)
+	return nil
+}
+
+func (a *AccountRepository) GetAll(ctx context.Context) ([]domain.Account, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.sortedAccounts(func(*domain.Account) bool { return true }), nil
+}
+
+type TransferRepository struct {
+	*store
+}
+
+// Insert records a transfer. When Credited is not set the target account is
+// taken to have received the same amount that left the source account.
+func (t *TransferRepository) Insert(ctx context.Context, tx domain.Transfer) (domain.Transfer, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.insert(tx), nil
+}
+
+func (t *TransferRepository) insert(tx domain.Transfer) domain.Transfer {
+	if tx.Credited.Currency == "" {
+		tx.Credited = tx.Amount
+	}
+	if tx.ID == uuid.Nil {
+		tx.ID = uuid.New()
+	}
+	tx.CreatedAt = t.now()
+	t.transfers = append(t.transfers, tx)
+	return tx
+}
+
+func (t *TransferRepository) Get(id uuid.UUID) (*domain.Transfer, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, tx := range t.transfers {
+		if tx.ID == id {
+			return &tx, nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (t *TransferRepository) GetAll() ([]domain.Transfer, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	transfers := append([]domain.Transfer(nil), t.transfers...)
+	sort.Slice(transfers, func(i, j int) bool {
+		return transfers[i].ID.String() < transfers[j].ID.String()
+	})
+	return transfers, nil
+}
+
+// AddAccountBalance changes the balance of the base pocket of an account.
+func (t *TransferRepository) AddAccountBalance(ctx context.Context, id uuid.UUID, amount decimal.Decimal) (domain.Account, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.addBalance(id, domain.Money{Amount: amount}, nil, domain.EntryAdjustment)
+}
+
+// TransferTx applies the transfer at once: both pockets, and that the source
+// one holds the amount, are checked before either of them changes.
+func (t *TransferRepository) TransferTx(ctx context.Context, arg domain.TransferTxParams) (*domain.TransferTxResult, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, side := range []struct {
+		id   uuid.UUID
+		code string
+	}{{arg.SourceAccountID, arg.Amount.Currency}, {arg.TargetAccountID, arg.Credit.Currency}} {
+		acc, ok := t.accounts[side.id]
+		if !ok || (side.code != "" && pocketIndex(acc, side.code) < 0) {
+			return nil, repository.ErrRecordNotFound
+		}
+	}
+	source := t.accounts[arg.SourceAccountID]
+	code := arg.Amount.Currency
+	if code == "" {
+		code = source.Currency
+	}
+	if source.Pockets[pocketIndex(source, code)].Balance.LessThan(arg.Amount.Amount) {
+		return nil, repository.ErrInsufficientFunds
+	}
+	if arg.Audit != nil && t.auditErr != nil {
+		return nil, t.auditErr
+	}
+	for _, tx := range t.transfers {
+		if arg.TransferID != uuid.Nil && tx.ID == arg.TransferID {
+			return nil, repository.ErrTransferExists
+		}
+	}
+
+	var result domain.TransferTxResult
+	result.Transfer = t.insert(domain.Transfer{
+		ID:              arg.TransferID,
+		SourceAccountID: arg.SourceAccountID,
+		TargetAccountID: arg.TargetAccountID,
+		Amount:          arg.Amount,
+		Credited:        arg.Credit,
+	})
+
+	var err error
+	result.SourceAccount, err = t.addBalance(arg.SourceAccountID, arg.Amount.Neg(), &result.Transfer.ID, domain.EntryTransfer)
+	if err != nil {
+		return nil, err
+	}
+	result.TargetAccount, err = t.addBalance(arg.TargetAccountID, arg.Credit, &result.Transfer.ID, domain.EntryTransfer)
+	if err != nil {
+		return nil, err
+	}
+	if arg.Audit != nil {
+		arg.Audit.Resource = "transfers/" + result.Transfer.ID.String()
+		if arg.Audit.After, err = json.Marshal(result.Transfer); err != nil {
+			return nil, err
+		}
+		t.appendAudit(arg.Audit)
+	}
+
+	return &result, nil
+}
+
+func (t *TransferRepository) ValidateAccounts(ctx context.Context, sourceAccountID, targetAccountID uuid.UUID) ([]domain.Account, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	accounts := t.sortedAccounts(func(acc *domain.Account) bool {
+		return acc.ID == sourceAccountID || acc.ID == targetAccountID
+	})
+	if len(accounts) != 2 {
+		return nil, repository.ErrUnknownAccount
+	}
+	return accounts, nil
+}
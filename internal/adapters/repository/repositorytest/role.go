@@ -0,0 +1,114 @@
+package repositorytest
+
+import (
+	"context"
+	"sort"
+
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type RoleRepository struct {
+	*store
+}
+
+// role returns a copy of the stored role that shares nothing with it.
+func (s *store) role(name string) (domain.Role, bool) {
+	role, ok := s.roles[name]
+	if !ok {
+		return domain.Role{}, false
+	}
+	copied := *role
+	copied.Permissions = append([]domain.Permission(nil), role.Permissions...)
+	return copied, true
+}
+
+func (ro *RoleRepository) Insert(ctx context.Context, role *domain.Role) error {
+	ro.mu.Lock()
+	defer ro.mu.Unlock()
+
+	if _, ok := ro.roles[role.Name]; ok {
+		return repository.ErrDuplicateRole
+	}
+
+	role.CreatedAt = ro.now()
+	role.UpdatedAt = role.CreatedAt
+
+	stored := *role
+	stored.Permissions = append([]domain.Permission(nil), role.Permissions...)
+	ro.roles[role.Name] = &stored
+	return nil
+}
+
+func (ro *RoleRepository) Get(ctx context.Context, name string) (*domain.Role, error) {
+	ro.mu.Lock()
+	defer ro.mu.Unlock()
+
+	role, ok := ro.role(name)
+	if !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+	return &role, nil
+}
+
+func (ro *RoleRepository) GetAll(ctx context.Context) ([]domain.Role, error) {
+	ro.mu.Lock()
+	defer ro.mu.Unlock()
+
+	names := make([]string, 0, len(ro.roles))
+	for name := range ro.roles {
+		names = append(names, name)
+	}
+	return ro.sortedRoles(names), nil
+}
+
+func (ro *RoleRepository) GetByNames(ctx context.Context, names []string) ([]domain.Role, error) {
+	ro.mu.Lock()
+	defer ro.mu.Unlock()
+
+	return ro.sortedRoles(names), nil
+}
+
+// sortedRoles returns copies of the roles with the given names, ordered by
+// name like the Postgres adapter does.
+func (ro *RoleRepository) sortedRoles(names []string) []domain.Role {
+	var roles []domain.Role
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if role, ok := ro.role(name); ok && !seen[name] {
+			seen[name] = true
+			roles = append(roles, role)
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+	return roles
+}
+
+func (ro *RoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	ro.mu.Lock()
+	defer ro.mu.Unlock()
+
+	stored, ok := ro.roles[role.Name]
+	if !ok {
+		return repository.ErrRecordNotFound
+	}
+
+	role.CreatedAt = stored.CreatedAt
+	role.UpdatedAt = ro.now()
+	*stored = *role
+	stored.Permissions = append([]domain.Permission(nil), role.Permissions...)
+	return nil
+}
+
+func (ro *RoleRepository) Delete(ctx context.Context, name string) error {
+	ro.mu.Lock()
+	defer ro.mu.Unlock()
+
+	if _, ok := ro.roles[name]; !ok {
+		return repository.ErrRecordNotFound
+	}
+	delete(ro.roles, name)
+	return nil
+}
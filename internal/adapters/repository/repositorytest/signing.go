@@ -0,0 +1,121 @@
+package repositorytest
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type SigningRepository struct {
+	*store
+}
+
+type nonceKey struct {
+	client, nonce string
+}
+
+func (s *SigningRepository) Insert(ctx context.Context, secret *domain.SigningSecret) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret.ID = uuid.New()
+	secret.CreatedAt = s.now()
+
+	stored := *secret
+	s.secrets[secret.ID] = &stored
+	return nil
+}
+
+func (s *SigningRepository) Get(ctx context.Context, id uuid.UUID) (*domain.SigningSecret, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret, ok := s.secrets[id]
+	if !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+	copied := *secret
+	return &copied, nil
+}
+
+func (s *SigningRepository) GetAll(ctx context.Context) ([]domain.SigningSecret, error) {
+	return s.filter(func(*domain.SigningSecret) bool { return true }), nil
+}
+
+func (s *SigningRepository) GetByClient(ctx context.Context, client string) ([]domain.SigningSecret, error) {
+	return s.filter(func(secret *domain.SigningSecret) bool { return secret.Client == client }), nil
+}
+
+// filter returns copies of the secrets keep selects, oldest first.
+func (s *SigningRepository) filter(keep func(*domain.SigningSecret) bool) []domain.SigningSecret {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var secrets []domain.SigningSecret
+	for _, secret := range s.secrets {
+		if keep(secret) {
+			secrets = append(secrets, *secret)
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		if !secrets[i].CreatedAt.Equal(secrets[j].CreatedAt) {
+			return secrets[i].CreatedAt.Before(secrets[j].CreatedAt)
+		}
+		return secrets[i].ID.String() < secrets[j].ID.String()
+	})
+	return secrets
+}
+
+func (s *SigningRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return s.update(id, func(secret *domain.SigningSecret) {
+		if secret.RevokedAt == nil {
+			secret.RevokedAt = &at
+		}
+	})
+}
+
+func (s *SigningRepository) Expire(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return s.update(id, func(secret *domain.SigningSecret) {
+		secret.ExpiresAt = &at
+	})
+}
+
+func (s *SigningRepository) update(id uuid.UUID, change func(*domain.SigningSecret)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret, ok := s.secrets[id]
+	if !ok {
+		return repository.ErrRecordNotFound
+	}
+	change(secret)
+	return nil
+}
+
+func (s *SigningRepository) UseNonce(ctx context.Context, client, nonce string, now, expires time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := nonceKey{client, nonce}
+	if until, ok := s.nonces[key]; ok && now.Before(until) {
+		return false, nil
+	}
+	s.nonces[key] = expires
+	return true, nil
+}
+
+func (s *SigningRepository) PurgeNonces(ctx context.Context, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, until := range s.nonces {
+		if until.Before(at) {
+			delete(s.nonces, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package repositorytest
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/shopspring/decimal"
+)
+
+type WalletRepository struct {
+	*store
+}
+
+func (w *WalletRepository) GetPockets(ctx context.Context, accountID uuid.UUID) ([]domain.Pocket, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	acc, ok := w.account(accountID)
+	if !ok {
+		return nil, nil
+	}
+	return acc.Pockets, nil
+}
+
+func (w *WalletRepository) OpenPocket(ctx context.Context, accountID uuid.UUID, code string) (*domain.Pocket, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	acc, ok := w.accounts[accountID]
+	if !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+	if pocketIndex(acc, code) >= 0 {
+		return nil, repository.ErrPocketExists
+	}
+
+	pocket := domain.Pocket{Currency: code, Balance: decimal.Zero, CreatedAt: w.now()}
+	acc.Pockets = append(acc.Pockets, pocket)
+	return &pocket, nil
+}
+
+// Convert moves value between two pockets of an account. Nothing is moved
+// when the source pocket holds less than the amount or the target pocket does
+// not exist.
+func (w *WalletRepository) Convert(ctx context.Context, conversion *domain.Conversion) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	acc, ok := w.accounts[conversion.AccountID]
+	if !ok {
+		return repository.ErrConversionFailed
+	}
+	from := pocketIndex(acc, conversion.Amount.Currency)
+	to := pocketIndex(acc, conversion.Converted.Currency)
+	if from < 0 || to < 0 || acc.Pockets[from].Balance.LessThan(conversion.Amount.Amount) {
+		return repository.ErrConversionFailed
+	}
+
+	acc.Pockets[from].Balance = acc.Pockets[from].Balance.Sub(conversion.Amount.Amount)
+	acc.Pockets[to].Balance = acc.Pockets[to].Balance.Add(conversion.Converted.Amount)
+	if base := pocketIndex(acc, acc.Currency); base >= 0 {
+		acc.Balance = acc.Pockets[base].Balance
+	}
+	acc.Version++
+
+	w.addEntry(acc.ID, conversion.Amount.Currency, nil, domain.EntryConversion, conversion.Amount.Amount.Neg())
+	w.addEntry(acc.ID, conversion.Converted.Currency, nil, domain.EntryConversion, conversion.Converted.Amount)
+
+	conversion.From, conversion.To = acc.Pockets[from], acc.Pockets[to]
+	return nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+var ErrDuplicateRole = errors.New("duplicate role")
+
+type RoleRepository struct {
+	DB *sql.DB
+}
+
+const roleColumns = `name, description, permissions, created_at, updated_at`
+
+func scanRole(row interface{ Scan(...any) error }, role *domain.Role) error {
+	var permissions []string
+	err := row.Scan(
+		&role.Name,
+		&role.Description,
+		pq.Array(&permissions),
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	role.Permissions = make([]domain.Permission, len(permissions))
+	for i, permission := range permissions {
+		role.Permissions[i] = domain.Permission(permission)
+	}
+	return nil
+}
+
+func permissionArray(permissions []domain.Permission) any {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = string(permission)
+	}
+	return pq.Array(names)
+}
+
+func (ro *RoleRepository) Insert(ctx context.Context, role *domain.Role) error {
+	query := `
+		INSERT INTO roles (name, description, permissions)
+		VALUES ($1, $2, $3)
+		RETURNING created_at, updated_at`
+
+	args := []any{role.Name, role.Description, permissionArray(role.Permissions)}
+
+	err := ro.DB.QueryRowContext(ctx, query, args...).Scan(&role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "roles_pkey"`:
+			return ErrDuplicateRole
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func (ro *RoleRepository) Get(ctx context.Context, name string) (*domain.Role, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles WHERE name = $1`
+
+	var role domain.Role
+	err := scanRole(ro.DB.QueryRowContext(ctx, query, name), &role)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &role, nil
+}
+
+func (ro *RoleRepository) GetAll(ctx context.Context) ([]domain.Role, error) {
+	return ro.query(ctx, `SELECT `+roleColumns+` FROM roles ORDER BY name`)
+}
+
+// GetByNames returns the roles with the given names, skipping the names no
+// role has.
+func (ro *RoleRepository) GetByNames(ctx context.Context, names []string) ([]domain.Role, error) {
+	return ro.query(ctx, `SELECT `+roleColumns+` FROM roles WHERE name = ANY($1) ORDER BY name`, pq.Array(names))
+}
+
+func (ro *RoleRepository) query(ctx context.Context, query string, args ...any) ([]domain.Role, error) {
+	rows, err := ro.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []domain.Role
+	for rows.Next() {
+		var role domain.Role
+		if err := scanRole(rows, &role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+func (ro *RoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	query := `
+		UPDATE roles
+		SET description = $1, permissions = $2, updated_at = now()
+		WHERE name = $3
+		RETURNING created_at, updated_at`
+
+	args := []any{role.Description, permissionArray(role.Permissions), role.Name}
+
+	err := ro.DB.QueryRowContext(ctx, query, args...).Scan(&role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func (ro *RoleRepository) Delete(ctx context.Context, name string) error {
+	query := `
+		DELETE FROM roles
+		WHERE name = $1`
+
+	result, err := ro.DB.ExecContext(ctx, query, name)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// SchemaVersion is the migration in db/migration the code expects the
+// database to be at.
+const SchemaVersion = 18
+
+type SchemaRepository struct {
+	DB *sql.DB
+}
+
+// Version returns the migration the database was last migrated to, as
+// recorded by migrate, and whether that migration failed part way. A database
+// that was never migrated is at 0.
+func (s *SchemaRepository) Version(ctx context.Context) (int, bool, error) {
+	var (
+		version int
+		dirty   bool
+	)
+	err := s.DB.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, false, nil
+	case errors.As(err, &pqErr) && pqErr.Code == "42P01": // undefined_table
+		return 0, false, nil
+	case err != nil:
+		return 0, false, err
+	}
+	return version, dirty, nil
+}
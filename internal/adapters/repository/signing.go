@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type SigningRepository struct {
+	DB *sql.DB
+}
+
+const signingSecretColumns = `id, client, secret, created_at, expires_at, revoked_at`
+
+func scanSigningSecret(row interface{ Scan(...any) error }, secret *domain.SigningSecret) error {
+	return row.Scan(
+		&secret.ID,
+		&secret.Client,
+		&secret.Secret,
+		&secret.CreatedAt,
+		&secret.ExpiresAt,
+		&secret.RevokedAt,
+	)
+}
+
+func (s *SigningRepository) Insert(ctx context.Context, secret *domain.SigningSecret) error {
+	query := `
+		INSERT INTO signing_secrets (client, secret)
+		VALUES ($1, $2)
+		RETURNING id, created_at`
+
+	return s.DB.QueryRowContext(ctx, query, secret.Client, secret.Secret).Scan(&secret.ID, &secret.CreatedAt)
+}
+
+func (s *SigningRepository) Get(ctx context.Context, id uuid.UUID) (*domain.SigningSecret, error) {
+	query := `SELECT ` + signingSecretColumns + ` FROM signing_secrets WHERE id = $1`
+
+	var secret domain.SigningSecret
+	err := scanSigningSecret(s.DB.QueryRowContext(ctx, query, id), &secret)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &secret, nil
+}
+
+func (s *SigningRepository) GetAll(ctx context.Context) ([]domain.SigningSecret, error) {
+	query := `SELECT ` + signingSecretColumns + ` FROM signing_secrets ORDER BY created_at, id`
+
+	return s.query(ctx, query)
+}
+
+func (s *SigningRepository) GetByClient(ctx context.Context, client string) ([]domain.SigningSecret, error) {
+	query := `SELECT ` + signingSecretColumns + ` FROM signing_secrets WHERE client = $1 ORDER BY created_at, id`
+
+	return s.query(ctx, query, client)
+}
+
+func (s *SigningRepository) query(ctx context.Context, query string, args ...any) ([]domain.SigningSecret, error) {
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var secrets []domain.SigningSecret
+	for rows.Next() {
+		var secret domain.SigningSecret
+		if err := scanSigningSecret(rows, &secret); err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, rows.Err()
+}
+
+// Revoke marks the secret as revoked at the given time, unless it already is.
+func (s *SigningRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `
+		UPDATE signing_secrets
+		SET revoked_at = COALESCE(revoked_at, $1)
+		WHERE id = $2`
+
+	return s.exec(ctx, query, at.UTC(), id)
+}
+
+// Expire sets the time the secret stops working.
+func (s *SigningRepository) Expire(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `
+		UPDATE signing_secrets
+		SET expires_at = $1
+		WHERE id = $2`
+
+	return s.exec(ctx, query, at.UTC(), id)
+}
+
+func (s *SigningRepository) exec(ctx context.Context, query string, args ...any) error {
+	result, err := s.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// UseNonce inserts the nonce, or takes over the row of the same nonce once
+// it has expired. No row comes back when the nonce is still remembered.
+func (s *SigningRepository) UseNonce(ctx context.Context, client, nonce string, now, expires time.Time) (bool, error) {
+	query := `
+		INSERT INTO signature_nonces (client, nonce, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (client, nonce) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		WHERE signature_nonces.expires_at <= $4
+		RETURNING true`
+
+	var fresh bool
+	err := s.DB.QueryRowContext(ctx, query, client, nonce, expires.UTC(), now.UTC()).Scan(&fresh)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return fresh, err
+}
+
+func (s *SigningRepository) PurgeNonces(ctx context.Context, at time.Time) error {
+	_, err := s.DB.ExecContext(ctx, `DELETE FROM signature_nonces WHERE expires_at < $1`, at.UTC())
+	return err
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+type WalletRepository struct {
+	DB *sql.DB
+}
+
+func (w *WalletRepository) GetPockets(ctx context.Context, accountID uuid.UUID) ([]domain.Pocket, error) {
+	query := `
+		SELECT currency, balance, created_at
+		FROM pockets
+		WHERE account_id = $1
+		ORDER BY created_at, currency`
+
+	rows, err := w.DB.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pockets []domain.Pocket
+	for rows.Next() {
+		var pocket domain.Pocket
+		if err := rows.Scan(&pocket.Currency, &pocket.Balance, &pocket.CreatedAt); err != nil {
+			return nil, err
+		}
+		pockets = append(pockets, pocket)
+	}
+
+	return pockets, rows.Err()
+}
+
+func (w *WalletRepository) OpenPocket(ctx context.Context, accountID uuid.UUID, currency string) (*domain.Pocket, error) {
+	query := `
+		INSERT INTO pockets (account_id, currency)
+		VALUES ($1, $2)
+		RETURNING currency, balance, created_at`
+
+	var pocket domain.Pocket
+	err := w.DB.QueryRowContext(ctx, query, accountID, currency).Scan(&pocket.Currency, &pocket.Balance, &pocket.CreatedAt)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "pockets_pkey"`:
+			return nil, ErrPocketExists
+		case err.Error() == `pq: insert or update on table "pockets" violates foreign key constraint "pockets_account_id_fkey"`:
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &pocket, nil
+}
+
+// Convert moves value between two pockets of an account in a single
+// statement, so the debit, the credit and their ledger entries are applied
+// together or not at all. Nothing is moved when the source pocket holds less
+// than the amount or the target pocket does not exist.
+func (w *WalletRepository) Convert(ctx context.Context, conversion *domain.Conversion) error {
+	query := `
+		WITH debit AS (
+			UPDATE pockets
+			SET balance = balance - $3
+			WHERE account_id = $1 AND currency = $2 AND balance >= $3
+				AND EXISTS (SELECT 1 FROM pockets WHERE account_id = $1 AND currency = $4)
+			RETURNING account_id, currency, balance, created_at
+		), credit AS (
+			UPDATE pockets
+			SET balance = balance + $5
+			WHERE account_id = $1 AND currency = $4 AND EXISTS (SELECT 1 FROM debit)
+			RETURNING account_id, currency, balance, created_at
+		), account AS (
+			UPDATE accounts a
+			SET balance = CASE a.currency
+				WHEN debit.currency THEN debit.balance
+				WHEN credit.currency THEN credit.balance
+				ELSE a.balance END,
+				version = a.version + 1
+			FROM debit, credit
+			WHERE a.id = debit.account_id
+		), entries AS (
+			INSERT INTO entries (account_id, kind, amount, currency)
+			SELECT account_id, $6::varchar, -$3::decimal, currency FROM debit
+			UNION ALL
+			SELECT account_id, $6::varchar, $5::decimal, currency FROM credit
+		)
+		SELECT debit.currency, debit.balance, debit.created_at, credit.currency, credit.balance, credit.created_at
+		FROM debit, credit`
+
+	args := []any{
+		conversion.AccountID,
+		conversion.Amount.Currency,
+		conversion.Amount.Amount,
+		conversion.Converted.Currency,
+		conversion.Converted.Amount,
+		domain.EntryConversion,
+	}
+
+	err := w.DB.QueryRowContext(ctx, query, args...).Scan(
+		&conversion.From.Currency,
+		&conversion.From.Balance,
+		&conversion.From.CreatedAt,
+		&conversion.To.Currency,
+		&conversion.To.Balance,
+		&conversion.To.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrConversionFailed
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// loadPockets replaces the pockets of the given accounts with the stored
+// ones, using a single query.
+func loadPockets(ctx context.Context, db *sql.DB, accounts []domain.Account) error {
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(accounts))
+	index := make(map[uuid.UUID]int, len(accounts))
+	for i, account := range accounts {
+		ids = append(ids, account.ID.String())
+		index[account.ID] = i
+		accounts[i].Pockets = nil
+	}
+
+	query := `
+		SELECT account_id, currency, balance, created_at
+		FROM pockets
+		WHERE account_id = ANY($1::uuid[])
+		ORDER BY created_at, currency`
+
+	rows, err := db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var accountID uuid.UUID
+		var pocket domain.Pocket
+		if err := rows.Scan(&accountID, &pocket.Currency, &pocket.Balance, &pocket.CreatedAt); err != nil {
+			return err
+		}
+		i := index[accountID]
+		accounts[i].Pockets = append(accounts[i].Pockets, pocket)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,149 @@
+package rpc
+
+import (
+	"context"
+
+	pb "github.com/petrostrak/agile-transfer/api/proto/agiletransfer/v1"
+	"github.com/petrostrak/agile-transfer/internal/config"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+type AccountServer struct {
+	pb.UnimplementedAccountServiceServer
+	service   services.AccountService
+	approvals services.ApprovalService
+	timeouts  config.Handlers
+}
+
+func NewAccountServer(accountService services.AccountService, approvalService services.ApprovalService, timeouts config.Handlers) *AccountServer {
+	return &AccountServer{
+		service:   accountService,
+		approvals: approvalService,
+		timeouts:  timeouts,
+	}
+}
+
+func (a *AccountServer) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.Account, error) {
+	v := validator.New()
+	account := &domain.Account{
+		Balance:  readDecimal(v, "balance", req.GetBalance()),
+		Currency: req.GetCurrency(),
+	}
+	if req.CustomerId != nil {
+		id := readID(v, "customer_id", req.GetCustomerId())
+		account.CustomerID = &id
+	}
+
+	if domain.ValidateAccount(v, account); !v.Valid() {
+		return nil, failedValidation(v.Errors)
+	}
+
+	if err := a.service.Insert(account); err != nil {
+		return nil, statusError(err)
+	}
+
+	return toAccount(account), nil
+}
+
+func (a *AccountServer) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.Account, error) {
+	v := validator.New()
+	id := readID(v, "id", req.GetId())
+	if !v.Valid() {
+		return nil, failedValidation(v.Errors)
+	}
+
+	account, err := a.service.Get(id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return toAccount(account), nil
+}
+
+func (a *AccountServer) UpdateAccount(ctx context.Context, req *pb.UpdateAccountRequest) (*pb.Account, error) {
+	v := validator.New()
+	id := readID(v, "id", req.GetId())
+	if !v.Valid() {
+		return nil, failedValidation(v.Errors)
+	}
+
+	account, err := a.service.Get(id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	before := *account
+
+	if req.Balance != nil {
+		account.Balance = readDecimal(v, "balance", req.GetBalance())
+	}
+	if req.Currency != nil {
+		account.Currency = req.GetCurrency()
+	}
+	if req.CustomerId != nil {
+		customerID := readID(v, "customer_id", req.GetCustomerId())
+		account.CustomerID = &customerID
+	}
+
+	if domain.ValidateAccount(v, account); !v.Valid() {
+		return nil, failedValidation(v.Errors)
+	}
+
+	// Approvals can only be asked for over REST.
+	change := &domain.AccountChange{Balance: account.Balance, Currency: account.Currency}
+	if a.approvals.Policy().ChangeNeedsApproval(&before, change) {
+		return nil, statusError(utils.ErrApprovalRequired)
+	}
+
+	if err := a.service.Update(account); err != nil {
+		return nil, statusError(err)
+	}
+
+	return toAccount(account), nil
+}
+
+func (a *AccountServer) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	v := validator.New()
+	id := readID(v, "id", req.GetId())
+	if !v.Valid() {
+		return nil, failedValidation(v.Errors)
+	}
+
+	account, err := a.service.Get(id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if a.approvals.Policy().Closures {
+		return nil, statusError(utils.ErrApprovalRequired)
+	}
+	if err := a.service.Delete(id, account.Version); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &pb.DeleteAccountResponse{}, nil
+}
+
+func (a *AccountServer) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.timeouts.ListTimeout)
+	defer cancel()
+
+	v := validator.New()
+	page := readPage(v, req.GetPageSize(), req.GetPageToken())
+	if !v.Valid() {
+		return nil, failedValidation(v.Errors)
+	}
+
+	accounts, err := a.service.GetAll(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	accounts, metadata := utils.Paginate(accounts, page)
+
+	resp := &pb.ListAccountsResponse{NextPageToken: nextPageToken(metadata)}
+	for i := range accounts {
+		resp.Accounts = append(resp.Accounts, toAccount(&accounts[i]))
+	}
+	return resp, nil
+}
@@ -0,0 +1,102 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pb "github.com/petrostrak/agile-transfer/api/proto/agiletransfer/v1"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// auditedMethods are the methods that change state, with the action the
+// audit log records them as.
+var auditedMethods = map[string]domain.AuditAction{
+	pb.AccountService_CreateAccount_FullMethodName:   domain.AuditAccountCreated,
+	pb.AccountService_UpdateAccount_FullMethodName:   domain.AuditAccountUpdated,
+	pb.AccountService_DeleteAccount_FullMethodName:   domain.AuditAccountDeleted,
+	pb.TransferService_CreateTransfer_FullMethodName: domain.AuditTransferCreated,
+}
+
+// auditor records the calls of auditedMethods that succeed in the audit log,
+// as the REST API records its requests, failing those it cannot record.
+type auditor struct {
+	service services.AuditService
+}
+
+func (a *auditor) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	action, ok := auditedMethods[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	entry := &domain.AuditEntry{
+		Action:   action,
+		Resource: auditResource(req, resp),
+	}
+	if key := apiClient(ctx); key != nil {
+		entry.Actor = "key:" + key.Client
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("x-request-id")) > 0 {
+		entry.RequestID = md.Get("x-request-id")[0]
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		entry.ClientIP = p.Addr.String()
+	}
+	if action != domain.AuditAccountDeleted {
+		entry.After = snapshot(resp)
+	}
+
+	// The change is made whether or not the client is still waiting.
+	recordCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.service.Record(recordCtx, entry); err != nil {
+		return nil, statusError(fmt.Errorf("recording %s %s in the audit log: %w", entry.Action, entry.Resource, err))
+	}
+	return resp, nil
+}
+
+// auditResource names the resource a call changed as the REST API does.
+func auditResource(req, resp any) string {
+	switch r := resp.(type) {
+	case *pb.Account:
+		return "accounts/" + r.GetId()
+	case *pb.CreateTransferResponse:
+		return "transfers/" + r.GetTransfer().GetId()
+	}
+	if r, ok := req.(*pb.DeleteAccountRequest); ok {
+		return "accounts/" + r.GetId()
+	}
+	return ""
+}
+
+// snapshot is the JSON encoding of a response for the audit log, compacted
+// as protojson varies its spacing.
+func snapshot(resp any) json.RawMessage {
+	m, ok := resp.(proto.Message)
+	if !ok {
+		return nil
+	}
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return nil
+	}
+	return compact.Bytes()
+}
@@ -0,0 +1,118 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	pb "github.com/petrostrak/agile-transfer/api/proto/agiletransfer/v1"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/tlsconfig"
+	"github.com/petrostrak/agile-transfer/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// methodScopes is the scope an API key needs to call each method, as for the
+// matching REST routes. Methods that are not listed need an admin key,
+// except those of the reflection service, which anyone may call.
+var methodScopes = map[string]domain.Scope{
+	pb.AccountService_CreateAccount_FullMethodName:   domain.ScopeAccountsWrite,
+	pb.AccountService_GetAccount_FullMethodName:      domain.ScopeAccountsRead,
+	pb.AccountService_UpdateAccount_FullMethodName:   domain.ScopeAccountsWrite,
+	pb.AccountService_DeleteAccount_FullMethodName:   domain.ScopeAccountsWrite,
+	pb.AccountService_ListAccounts_FullMethodName:    domain.ScopeAccountsRead,
+	pb.TransferService_CreateTransfer_FullMethodName: domain.ScopeTransfersCreate,
+	pb.TransferService_ListTransfers_FullMethodName:  domain.ScopeAccountsRead,
+	pb.TransferService_WatchTransfers_FullMethodName: domain.ScopeAccountsRead,
+}
+
+// authenticator checks the API key sent in the x-api-key metadata of every
+// call, and that the client certificate of the connection is one the
+// subjects bind to the key's client.
+type authenticator struct {
+	service  services.APIKeyService
+	subjects *tlsconfig.ClientSubjects
+}
+
+func (a *authenticator) authorize(ctx context.Context, method string) (*domain.APIKey, error) {
+	if strings.HasPrefix(method, "/grpc.reflection.") {
+		return nil, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("x-api-key")
+	if len(values) == 0 || values[0] == "" {
+		return nil, statusError(utils.ErrMissingAPIKey)
+	}
+
+	key, err := a.service.Authenticate(ctx, values[0])
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if !a.subjects.Allows(connectionState(ctx), key.Client) {
+		return nil, statusError(utils.ErrCertificateMismatch)
+	}
+
+	scope, ok := methodScopes[method]
+	if !ok {
+		scope = domain.ScopeAdmin
+	}
+	if !key.Allows(scope) {
+		return nil, statusError(fmt.Errorf("%w: %s", utils.ErrInsufficientScope, scope))
+	}
+	return key, nil
+}
+
+// connectionState returns the TLS state of the connection of the call, nil
+// for calls over plaintext connections.
+func connectionState(ctx context.Context) *tls.ConnectionState {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+	return &info.State
+}
+
+type apiKeyContextKey struct{}
+
+// apiClient returns the API key the call was authenticated with, nil for
+// calls that need none.
+func apiClient(ctx context.Context) *domain.APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*domain.APIKey)
+	return key
+}
+
+func (a *authenticator) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	key, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(context.WithValue(ctx, apiKeyContextKey{}, key), req)
+}
+
+func (a *authenticator) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	key, err := a.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ss, context.WithValue(ss.Context(), apiKeyContextKey{}, key)})
+}
+
+// authenticatedStream carries the API key of the call in its context.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
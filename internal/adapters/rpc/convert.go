@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"strconv"
+
+	"github.com/google/uuid"
+	pb "github.com/petrostrak/agile-transfer/api/proto/agiletransfer/v1"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+	"github.com/petrostrak/agile-transfer/utils"
+	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toAccount(account *domain.Account) *pb.Account {
+	out := &pb.Account{
+		Id:        account.ID.String(),
+		Balance:   domain.NewMoney(account.Balance, account.Currency).AmountString(),
+		Currency:  account.Currency,
+		CreatedAt: timestamppb.New(account.CreatedAt),
+	}
+	if account.CustomerID != nil {
+		id := account.CustomerID.String()
+		out.CustomerId = &id
+	}
+	for _, pocket := range account.Pockets {
+		out.Pockets = append(out.Pockets, &pb.Pocket{
+			Currency:  pocket.Currency,
+			Balance:   domain.NewMoney(pocket.Balance, pocket.Currency).AmountString(),
+			CreatedAt: timestamppb.New(pocket.CreatedAt),
+		})
+	}
+	return out
+}
+
+func toTransfer(tx domain.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:              tx.ID.String(),
+		SourceAccountId: tx.SourceAccountID.String(),
+		TargetAccountId: tx.TargetAccountID.String(),
+		Amount:          toMoney(tx.Amount),
+		Credited:        toMoney(tx.Credited),
+		CreatedAt:       timestamppb.New(tx.CreatedAt),
+	}
+}
+
+func toMoney(m domain.Money) *pb.Money {
+	return &pb.Money{Amount: m.AmountString(), Currency: m.Currency}
+}
+
+// readID parses the id in a request field, recording in v when it is not one.
+func readID(v *validator.Validator, key, value string) uuid.UUID {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		v.AddError(key, "must be a valid id")
+	}
+	return id
+}
+
+// readDecimal parses the decimal number in a request field, recording in v
+// when it is not one.
+func readDecimal(v *validator.Validator, key, value string) decimal.Decimal {
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		v.AddError(key, "must be a decimal number")
+	}
+	return d
+}
+
+// readPage turns the page_size and page_token of a list request into the
+// page to return. Page tokens are the number of the page.
+func readPage(v *validator.Validator, size int32, token string) utils.Page {
+	page := utils.Page{Number: 1, Size: int(size)}
+	if page.Size == 0 {
+		page.Size = utils.MaxPageSize
+	}
+	if token != "" {
+		n, err := strconv.Atoi(token)
+		if err != nil || n < 1 {
+			v.AddError("page_token", "must be the next_page_token of a previous page")
+		}
+		page.Number = n
+	}
+
+	v.Check(page.Size > 0, "page_size", "must be greater than zero")
+	v.Check(page.Size <= utils.MaxPageSize, "page_size", "must be a maximum of 100")
+
+	return page
+}
+
+// nextPageToken is the token of the page after the one described by
+// metadata, or empty when it is the last one.
+func nextPageToken(metadata utils.Metadata) string {
+	if metadata.CurrentPage >= metadata.LastPage {
+		return ""
+	}
+	return strconv.Itoa(metadata.CurrentPage + 1)
+}
@@ -0,0 +1,112 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/utils"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain is the domain of the google.rpc.ErrorInfo attached to every
+// error. Its reason is the same stable code the REST API reports, listed in
+// docs/errors.md.
+const errorDomain = "agile-transfer"
+
+type statusCode struct {
+	err    error
+	code   codes.Code
+	reason string
+}
+
+var statusCodes = []statusCode{
+	{utils.ErrMissingAPIKey, codes.Unauthenticated, "unauthenticated"},
+	{utils.ErrInvalidAPIKey, codes.Unauthenticated, "unauthenticated"},
+	{utils.ErrInsufficientScope, codes.PermissionDenied, "insufficient_scope"},
+	{utils.ErrCertificateMismatch, codes.Unauthenticated, "certificate_mismatch"},
+	{utils.ErrSignatureRequired, codes.Unauthenticated, "signature_required"},
+	{utils.ErrInvalidSignature, codes.Unauthenticated, "invalid_signature"},
+	{utils.ErrStaleSignature, codes.Unauthenticated, "stale_signature"},
+	{utils.ErrReplayedSignature, codes.Unauthenticated, "replayed_signature"},
+
+	{repository.ErrRecordNotFound, codes.NotFound, "not_found"},
+	{repository.ErrUnknownAccount, codes.NotFound, "unknown_account"},
+	{repository.ErrUnknownCustomer, codes.NotFound, "unknown_customer"},
+	{repository.ErrEditConflict, codes.Aborted, "precondition_failed"},
+	{repository.ErrCurrencyInUse, codes.FailedPrecondition, "currency_in_use"},
+
+	{utils.ErrApprovalRequired, codes.FailedPrecondition, "approval_required"},
+	{utils.ErrInsufficientBalance, codes.FailedPrecondition, "insufficient_balance"},
+	{repository.ErrInsufficientFunds, codes.FailedPrecondition, "insufficient_balance"},
+	{utils.ErrIdenticalAccount, codes.InvalidArgument, "identical_account"},
+	{utils.ErrInvalidAmount, codes.InvalidArgument, "invalid_amount"},
+	{utils.ErrPocketNotFound, codes.FailedPrecondition, "pocket_not_found"},
+	{repository.ErrPocketNotFound, codes.FailedPrecondition, "pocket_not_found"},
+	{utils.ErrCustomerNotActive, codes.FailedPrecondition, "customer_not_active"},
+	{utils.ErrAccountFrozen, codes.FailedPrecondition, "account_frozen"},
+	{utils.ErrTargetNotAllowed, codes.PermissionDenied, "target_not_allowed"},
+	{domain.ErrCurrencyMismatch, codes.InvalidArgument, "currency_mismatch"},
+	{currency.ErrUnknownCurrency, codes.InvalidArgument, "unknown_currency"},
+	{currency.ErrCurrencyDisabled, codes.FailedPrecondition, "currency_disabled"},
+	{currency.ErrInvalidPrecision, codes.InvalidArgument, "invalid_precision"},
+
+	{utils.ErrCurrencyConvertion, codes.Unavailable, "currency_conversion_failed"},
+}
+
+// statusError turns an error from the services into a gRPC status. Errors it
+// does not know are logged and reported as internal.
+func statusError(err error) error {
+	for _, sc := range statusCodes {
+		if errors.Is(err, sc.err) {
+			return newStatus(sc.code, sc.reason, err.Error())
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	utils.LogError(err)
+	return newStatus(codes.Internal, "internal_error", "the server encountered a problem and could not process your request")
+}
+
+func newStatus(code codes.Code, reason, message string, details ...*errdetails.BadRequest_FieldViolation) error {
+	st, err := status.New(code, message).WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: errorDomain})
+	if err != nil {
+		return status.Error(code, message)
+	}
+	if len(details) > 0 {
+		if withFields, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: details}); err == nil {
+			st = withFields
+		}
+	}
+	return st.Err()
+}
+
+// failedValidation reports the problems found in a request, keyed by field,
+// as INVALID_ARGUMENT with a google.rpc.BadRequest detail.
+func failedValidation(errors map[string][]string) error {
+	fields := make([]string, 0, len(errors))
+	for field := range errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, field := range fields {
+		for _, message := range errors[field] {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: field, Description: message})
+		}
+	}
+
+	return newStatus(codes.InvalidArgument, "validation_failed", "the request has invalid fields", violations...)
+}
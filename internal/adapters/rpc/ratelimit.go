@@ -0,0 +1,108 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/petrostrak/agile-transfer/api/proto/agiletransfer/v1"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/utils"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// limiter counts every call towards the api limit of its API client, and
+// CreateTransfer towards the transfer limits of the client and the source
+// account too. The buckets are those of the REST routes of the same name, so
+// a client cannot get around its limits by switching APIs.
+type limiter struct {
+	service  services.RateLimitService
+	api      domain.RouteLimit
+	transfer domain.RouteLimit
+}
+
+// take refuses calls over the limits of route with RESOURCE_EXHAUSTED and a
+// google.rpc.RetryInfo. Otherwise it returns the ratelimit-* metadata of the
+// bucket with the fewest calls left, as the REST API sends its headers.
+func (l *limiter) take(ctx context.Context, route domain.RouteLimit, accountID uuid.UUID) (metadata.MD, error) {
+	key := apiClient(ctx)
+	if key == nil {
+		return nil, nil
+	}
+
+	tightest, err := l.service.Limit(ctx, route, "key:"+key.Client, accountID)
+	if err != nil {
+		utils.LogError(err)
+	}
+	if tightest == nil {
+		return nil, nil
+	}
+
+	md := metadata.Pairs(
+		"ratelimit-limit", strconv.Itoa(tightest.Limit.Limit),
+		"ratelimit-remaining", strconv.Itoa(tightest.Remaining),
+		"ratelimit-reset", seconds(tightest.Reset),
+		"ratelimit-policy", tightest.Limit.Policy(),
+	)
+	if !tightest.Allowed {
+		return md, rateLimited(tightest.RetryAfter)
+	}
+	return md, nil
+}
+
+func (l *limiter) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, err := l.take(ctx, l.api, uuid.Nil)
+	if r, ok := req.(*pb.CreateTransferRequest); ok && err == nil {
+		// An id that does not parse has no bucket; the call fails validation.
+		accountID, _ := uuid.Parse(r.GetSourceAccountId())
+		var transferMD metadata.MD
+		transferMD, err = l.take(ctx, l.transfer, accountID)
+		if transferMD != nil {
+			md = transferMD
+		}
+	}
+	if md != nil {
+		grpc.SetHeader(ctx, md)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (l *limiter) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	md, err := l.take(ss.Context(), l.api, uuid.Nil)
+	if md != nil {
+		ss.SetHeader(md)
+	}
+	if err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func rateLimited(retryAfter time.Duration) error {
+	message := fmt.Sprintf("too many requests, retry in %s seconds", seconds(retryAfter))
+	st, err := status.New(codes.ResourceExhausted, message).WithDetails(
+		&errdetails.ErrorInfo{Reason: "rate_limited", Domain: errorDomain},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)},
+	)
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, message)
+	}
+	return st.Err()
+}
+
+// seconds rounds d up to whole seconds, as the metadata gives them.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
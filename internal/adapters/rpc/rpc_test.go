@@ -0,0 +1,599 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/petrostrak/agile-transfer/api/proto/agiletransfer/v1"
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository/repositorytest"
+	"github.com/petrostrak/agile-transfer/internal/config"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/tlsconfig"
+	"github.com/shopspring/decimal"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+type testServer struct {
+	accounts  pb.AccountServiceClient
+	transfers pb.TransferServiceClient
+	service   *services.TransferService
+	apiKeys   *services.APIKeyService
+	signing   *services.SigningService
+	audit     *repositorytest.AuditRepository
+	listener  *bufconn.Listener
+	creds     credentials.TransportCredentials
+}
+
+// testOptions are how startTestServer serves the API.
+type testOptions struct {
+	policy             domain.ApprovalPolicy
+	apiLimit           domain.RouteLimit
+	transferLimit      domain.RouteLimit
+	certificateClients map[string]string
+	serverCreds        credentials.TransportCredentials
+	clientCreds        credentials.TransportCredentials
+}
+
+type testOption func(*testOptions)
+
+// withApprovalPolicy holds the actions policy says need approval.
+func withApprovalPolicy(policy domain.ApprovalPolicy) testOption {
+	return func(o *testOptions) { o.policy = policy }
+}
+
+// withRateLimits limits the calls to apiLimit and the transfers to
+// transferLimit.
+func withRateLimits(apiLimit, transferLimit domain.RouteLimit) testOption {
+	return func(o *testOptions) { o.apiLimit, o.transferLimit = apiLimit, transferLimit }
+}
+
+// withTLS serves the API with the server credentials, to clients that
+// connect with the client ones, binding the API clients of
+// certificateClients to client certificates.
+func withTLS(server, client credentials.TransportCredentials, certificateClients map[string]string) testOption {
+	return func(o *testOptions) {
+		o.serverCreds, o.clientCreds, o.certificateClients = server, client, certificateClients
+	}
+}
+
+// startTestServer serves the API in memory to clients that call it with an
+// admin key. Unless opts say otherwise no action needs approval, calls are
+// not limited and connections are not encrypted.
+func startTestServer(t *testing.T, opts ...testOption) *testServer {
+	o := testOptions{clientCreds: insecure.NewCredentials()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var serverOpts []grpc.ServerOption
+	if o.serverCreds != nil {
+		serverOpts = append(serverOpts, grpc.Creds(o.serverCreds))
+	}
+
+	store := repositorytest.NewRepository()
+	accountService := services.NewAccountService(store.AccountRepository)
+	// No transfer converts currencies.
+	transferService := services.NewTransferService(store.TransferRepository, store.CustomerRepository, nil, o.policy)
+	apiKeyService := services.NewAPIKeyService(store.APIKeyRepository)
+	auditService := services.NewAuditService(store.AuditRepository)
+	approvalService := services.NewApprovalService(store.ApprovalRepository, o.policy, accountService, transferService, auditService)
+	rateLimitService := services.NewRateLimitService(store.RateLimitRepository, store.AccountRepository)
+	signingService := services.NewSigningService(store.SigningRepository)
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(*accountService, *transferService, *apiKeyService, *approvalService, *auditService, *rateLimitService, o.apiLimit, o.transferLimit, *signingService, o.certificateClients, config.Default().Handlers, serverOpts...)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	s := &testServer{service: transferService, apiKeys: apiKeyService, signing: signingService, audit: store.AuditRepository, listener: listener, creds: o.clientCreds}
+	conn := s.dial(t, s.issueKey(t, domain.ScopeAdmin))
+	s.accounts = pb.NewAccountServiceClient(conn)
+	s.transfers = pb.NewTransferServiceClient(conn)
+	return s
+}
+
+// dial connects to the server, sending key with every call unless it is
+// empty.
+func (s *testServer) dial(t *testing.T, key string) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return s.listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(s.creds),
+		grpc.WithPerRPCCredentials(apiKeyCredentials(key)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func (s *testServer) issueKey(t *testing.T, scopes ...domain.Scope) string {
+	t.Helper()
+	return s.issueClientKey(t, "test", scopes...)
+}
+
+func (s *testServer) issueClientKey(t *testing.T, client string, scopes ...domain.Scope) string {
+	t.Helper()
+	key, err := s.apiKeys.Issue(context.Background(), &domain.APIKey{Client: client, Scopes: scopes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// apiKeyCredentials sends an API key as x-api-key metadata.
+type apiKeyCredentials string
+
+func (c apiKeyCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	if c == "" {
+		return nil, nil
+	}
+	return map[string]string{"x-api-key": string(c)}, nil
+}
+
+func (c apiKeyCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+func (s *testServer) createAccount(t *testing.T, balance string) *pb.Account {
+	t.Helper()
+	account, err := s.accounts.CreateAccount(context.Background(), &pb.CreateAccountRequest{Balance: balance, Currency: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return account
+}
+
+// expectStatus checks that err is a status with the given code and the
+// reason of its ErrorInfo, and returns its field violations.
+func expectStatus(t *testing.T, err error, code codes.Code, reason string) []*errdetails.BadRequest_FieldViolation {
+	t.Helper()
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != code {
+		t.Fatalf("expected %s but got %v", code, err)
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	found := ""
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			found = d.Reason
+		case *errdetails.BadRequest:
+			violations = d.FieldViolations
+		}
+	}
+	if found != reason {
+		t.Errorf("expected reason %q but got %q", reason, found)
+	}
+	return violations
+}
+
+func TestAccounts(t *testing.T) {
+	s := startTestServer(t)
+	ctx := context.Background()
+
+	account := s.createAccount(t, "100.5")
+	if account.Balance != "100.50" || account.Currency != "EUR" || len(account.Pockets) != 1 {
+		t.Errorf("unexpected account %v", account)
+	}
+
+	got, err := s.accounts.GetAccount(ctx, &pb.GetAccountRequest{Id: account.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Id != account.Id || got.Balance != "100.50" {
+		t.Errorf("expected %v but got %v", account, got)
+	}
+
+	balance := "20"
+	updated, err := s.accounts.UpdateAccount(ctx, &pb.UpdateAccountRequest{Id: account.Id, Balance: &balance})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Balance != "20.00" {
+		t.Errorf("expected the balance to be 20.00 but got %s", updated.Balance)
+	}
+
+	for i := 0; i < 2; i++ {
+		s.createAccount(t, "0")
+	}
+	list, err := s.accounts.ListAccounts(ctx, &pb.ListAccountsRequest{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Accounts) != 2 || list.NextPageToken != "2" {
+		t.Errorf("expected 2 accounts and a next page but got %d and %q", len(list.Accounts), list.NextPageToken)
+	}
+	list, err = s.accounts.ListAccounts(ctx, &pb.ListAccountsRequest{PageSize: 2, PageToken: list.NextPageToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Accounts) != 1 || list.NextPageToken != "" {
+		t.Errorf("expected the last account but got %d and %q", len(list.Accounts), list.NextPageToken)
+	}
+
+	if _, err := s.accounts.DeleteAccount(ctx, &pb.DeleteAccountRequest{Id: account.Id}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.accounts.GetAccount(ctx, &pb.GetAccountRequest{Id: account.Id})
+	expectStatus(t, err, codes.NotFound, "not_found")
+}
+
+func TestAudit(t *testing.T) {
+	s := startTestServer(t)
+	ctx := context.Background()
+
+	account := s.createAccount(t, "10")
+	if _, err := s.accounts.GetAccount(ctx, &pb.GetAccountRequest{Id: account.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.accounts.DeleteAccount(ctx, &pb.DeleteAccountRequest{Id: account.Id}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := s.accounts.DeleteAccount(ctx, &pb.DeleteAccountRequest{Id: account.Id})
+	expectStatus(t, err, codes.NotFound, "not_found")
+
+	// Only the calls that changed something are recorded.
+	entries := s.audit.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected the creation and deletion to be recorded but got %+v", entries)
+	}
+	created, deleted := entries[0], entries[1]
+	if created.Action != domain.AuditAccountCreated || created.Resource != "accounts/"+account.Id || created.Actor != "key:test" || len(created.After) == 0 {
+		t.Errorf("unexpected entry for the creation %+v", created)
+	}
+	if deleted.Action != domain.AuditAccountDeleted || deleted.Resource != "accounts/"+account.Id || deleted.After != nil {
+		t.Errorf("unexpected entry for the deletion %+v", deleted)
+	}
+	if deleted.PrevHash != created.Hash {
+		t.Errorf("expected the entries to be chained")
+	}
+
+	// Calls whose change cannot be recorded fail.
+	s.audit.FailWith(errors.New("disk full"))
+	_, err = s.accounts.CreateAccount(ctx, &pb.CreateAccountRequest{Balance: "10", Currency: "EUR"})
+	expectStatus(t, err, codes.Internal, "internal_error")
+}
+
+func TestAccountValidation(t *testing.T) {
+	s := startTestServer(t)
+
+	_, err := s.accounts.CreateAccount(context.Background(), &pb.CreateAccountRequest{Balance: "-1", Currency: "EUR"})
+	violations := expectStatus(t, err, codes.InvalidArgument, "validation_failed")
+	if len(violations) != 1 || violations[0].Field != "balance" {
+		t.Errorf("expected a violation for the balance but got %v", violations)
+	}
+
+	_, err = s.accounts.GetAccount(context.Background(), &pb.GetAccountRequest{Id: "nope"})
+	violations = expectStatus(t, err, codes.InvalidArgument, "validation_failed")
+	if len(violations) != 1 || violations[0].Field != "id" {
+		t.Errorf("expected a violation for the id but got %v", violations)
+	}
+
+	_, err = s.accounts.ListAccounts(context.Background(), &pb.ListAccountsRequest{PageSize: 101})
+	expectStatus(t, err, codes.InvalidArgument, "validation_failed")
+}
+
+func TestTransfers(t *testing.T) {
+	s := startTestServer(t)
+	ctx := context.Background()
+	source := s.createAccount(t, "100")
+	target := s.createAccount(t, "0")
+
+	result, err := s.transfers.CreateTransfer(ctx, &pb.CreateTransferRequest{SourceAccountId: source.Id, TargetAccountId: target.Id, Amount: "40"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SourceAccount.Balance != "60.00" || result.TargetAccount.Balance != "40.00" {
+		t.Errorf("expected balances 60.00 and 40.00 but got %s and %s", result.SourceAccount.Balance, result.TargetAccount.Balance)
+	}
+	if result.Transfer.Amount.Amount != "40.00" || result.Transfer.Amount.Currency != "EUR" {
+		t.Errorf("unexpected amount %v", result.Transfer.Amount)
+	}
+
+	_, err = s.transfers.CreateTransfer(ctx, &pb.CreateTransferRequest{SourceAccountId: source.Id, TargetAccountId: target.Id, Amount: "61"})
+	expectStatus(t, err, codes.FailedPrecondition, "insufficient_balance")
+
+	_, err = s.transfers.CreateTransfer(ctx, &pb.CreateTransferRequest{SourceAccountId: source.Id, TargetAccountId: source.Id, Amount: "0"})
+	violations := expectStatus(t, err, codes.InvalidArgument, "validation_failed")
+	if len(violations) != 2 {
+		t.Errorf("expected violations for the amount and target account but got %v", violations)
+	}
+
+	list, err := s.transfers.ListTransfers(ctx, &pb.ListTransfersRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Transfers) != 1 || list.Transfers[0].Id != result.Transfer.Id {
+		t.Errorf("expected the transfer to be listed but got %v", list.Transfers)
+	}
+}
+
+func TestApprovalRequired(t *testing.T) {
+	s := startTestServer(t, withApprovalPolicy(domain.ApprovalPolicy{
+		TransferThresholds: map[string]decimal.Decimal{"EUR": decimal.NewFromInt(50)},
+		Adjustments:        true,
+		Closures:           true,
+		TTL:                time.Hour,
+	}))
+	ctx := context.Background()
+	source := s.createAccount(t, "100")
+	target := s.createAccount(t, "0")
+
+	_, err := s.transfers.CreateTransfer(ctx, &pb.CreateTransferRequest{SourceAccountId: source.Id, TargetAccountId: target.Id, Amount: "60"})
+	expectStatus(t, err, codes.FailedPrecondition, "approval_required")
+	if _, err := s.transfers.CreateTransfer(ctx, &pb.CreateTransferRequest{SourceAccountId: source.Id, TargetAccountId: target.Id, Amount: "50"}); err != nil {
+		t.Errorf("expected transfers up to the threshold to be made but got %v", err)
+	}
+
+	balance := "20"
+	_, err = s.accounts.UpdateAccount(ctx, &pb.UpdateAccountRequest{Id: source.Id, Balance: &balance})
+	expectStatus(t, err, codes.FailedPrecondition, "approval_required")
+
+	_, err = s.accounts.DeleteAccount(ctx, &pb.DeleteAccountRequest{Id: target.Id})
+	expectStatus(t, err, codes.FailedPrecondition, "approval_required")
+}
+
+// TestDefaultApprovals checks that the actions the gRPC API cannot ask
+// approval for are carried out with the default settings.
+func TestDefaultApprovals(t *testing.T) {
+	s := startTestServer(t, withApprovalPolicy(config.Default().Approval.Policy()))
+	ctx := context.Background()
+	account := s.createAccount(t, "100")
+
+	balance := "20"
+	if _, err := s.accounts.UpdateAccount(ctx, &pb.UpdateAccountRequest{Id: account.Id, Balance: &balance}); err != nil {
+		t.Errorf("expected the balance to be adjusted but got %v", err)
+	}
+	if _, err := s.accounts.DeleteAccount(ctx, &pb.DeleteAccountRequest{Id: account.Id}); err != nil {
+		t.Errorf("expected the account to be closed but got %v", err)
+	}
+}
+
+func TestRateLimits(t *testing.T) {
+	s := startTestServer(t, withRateLimits(
+		domain.RouteLimit{Name: "api", Client: domain.RateLimit{Limit: 100, Period: time.Hour}},
+		domain.RouteLimit{Name: "transfer", Account: domain.RateLimit{Limit: 1, Period: time.Hour}},
+	))
+	ctx := context.Background()
+	source := s.createAccount(t, "100")
+	target := s.createAccount(t, "0")
+	transfer := &pb.CreateTransferRequest{SourceAccountId: source.Id, TargetAccountId: target.Id, Amount: "1"}
+
+	var header metadata.MD
+	if _, err := s.transfers.CreateTransfer(ctx, transfer, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+	if header.Get("ratelimit-policy")[0] != "1;w=3600" || header.Get("ratelimit-remaining")[0] != "0" {
+		t.Errorf("expected the transfer limit of the account to be reported but got %v", header)
+	}
+	_, err := s.transfers.CreateTransfer(ctx, transfer)
+	expectStatus(t, err, codes.ResourceExhausted, "rate_limited")
+
+	// Accounts that do not exist have no bucket to run out of.
+	for i := 0; i < 2; i++ {
+		_, err = s.transfers.CreateTransfer(ctx, &pb.CreateTransferRequest{SourceAccountId: uuid.NewString(), TargetAccountId: target.Id, Amount: "1"})
+		expectStatus(t, err, codes.NotFound, "unknown_account")
+	}
+
+	header = nil
+	if _, err := s.accounts.ListAccounts(ctx, &pb.ListAccountsRequest{}, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+	if header.Get("ratelimit-remaining")[0] != "93" {
+		t.Errorf("expected every call to count towards the api limit but got %v", header)
+	}
+}
+
+func TestSignedCalls(t *testing.T) {
+	s := startTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	source := s.createAccount(t, "100")
+	target := s.createAccount(t, "0")
+
+	secret, err := s.signing.Issue(ctx, &domain.SigningSecret{Client: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfer := &pb.CreateTransferRequest{SourceAccountId: source.Id, TargetAccountId: target.Id, Amount: "1"}
+	sign := func(method string, req proto.Message, nonce string) context.Context {
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		timestamp := time.Now().Unix()
+		return metadata.AppendToOutgoingContext(ctx,
+			"x-signature", "v1="+domain.Signature(secret, "POST", method, timestamp, nonce, body),
+			"x-signature-timestamp", strconv.FormatInt(timestamp, 10),
+			"x-signature-nonce", nonce,
+		)
+	}
+
+	_, err = s.transfers.CreateTransfer(ctx, transfer)
+	expectStatus(t, err, codes.Unauthenticated, "signature_required")
+
+	signed := sign(pb.TransferService_CreateTransfer_FullMethodName, transfer, "n-1")
+	if _, err := s.transfers.CreateTransfer(signed, transfer); err != nil {
+		t.Fatalf("expected the signed call to be made but got %v", err)
+	}
+	_, err = s.transfers.CreateTransfer(signed, transfer)
+	expectStatus(t, err, codes.Unauthenticated, "replayed_signature")
+
+	other := &pb.CreateTransferRequest{SourceAccountId: source.Id, TargetAccountId: target.Id, Amount: "99"}
+	_, err = s.transfers.CreateTransfer(sign(pb.TransferService_CreateTransfer_FullMethodName, transfer, "n-2"), other)
+	expectStatus(t, err, codes.Unauthenticated, "invalid_signature")
+
+	stream, err := s.transfers.WatchTransfers(ctx, &pb.WatchTransfersRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	expectStatus(t, err, codes.Unauthenticated, "signature_required")
+
+	watch := &pb.WatchTransfersRequest{}
+	stream, err = s.transfers.WatchTransfers(sign(pb.TransferService_WatchTransfers_FullMethodName, watch, "n-3"), watch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Header(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.transfers.CreateTransfer(sign(pb.TransferService_CreateTransfer_FullMethodName, transfer, "n-4"), transfer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Errorf("expected the signed stream to receive the transfer but got %v", err)
+	}
+}
+
+func TestWatchTransfers(t *testing.T) {
+	s := startTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first := s.createAccount(t, "100")
+	second := s.createAccount(t, "0")
+	third := s.createAccount(t, "0")
+
+	stream, err := s.transfers.WatchTransfers(ctx, &pb.WatchTransfersRequest{AccountId: &third.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Header(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the second transfer involves the watched account.
+	for _, target := range []string{second.Id, third.Id} {
+		_, err := s.transfers.CreateTransfer(ctx, &pb.CreateTransferRequest{SourceAccountId: first.Id, TargetAccountId: target, Amount: "10"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tx, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.TargetAccountId != third.Id || tx.Amount.Amount != "10.00" {
+		t.Errorf("expected the transfer to %s but got %v", third.Id, tx)
+	}
+
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("expected the stream to be canceled but got %v", err)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	s := startTestServer(t)
+	ctx := context.Background()
+	account := s.createAccount(t, "100")
+
+	anonymous := pb.NewAccountServiceClient(s.dial(t, ""))
+	_, err := anonymous.GetAccount(ctx, &pb.GetAccountRequest{Id: account.Id})
+	expectStatus(t, err, codes.Unauthenticated, "unauthenticated")
+
+	unknown := pb.NewAccountServiceClient(s.dial(t, "agt_0000_secret"))
+	_, err = unknown.GetAccount(ctx, &pb.GetAccountRequest{Id: account.Id})
+	expectStatus(t, err, codes.Unauthenticated, "unauthenticated")
+
+	conn := s.dial(t, s.issueKey(t, domain.ScopeAccountsRead))
+	reader := pb.NewAccountServiceClient(conn)
+	if _, err := reader.GetAccount(ctx, &pb.GetAccountRequest{Id: account.Id}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = reader.DeleteAccount(ctx, &pb.DeleteAccountRequest{Id: account.Id})
+	expectStatus(t, err, codes.PermissionDenied, "insufficient_scope")
+
+	stream, err := pb.NewTransferServiceClient(s.dial(t, "")).WatchTransfers(ctx, &pb.WatchTransfersRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	expectStatus(t, err, codes.Unauthenticated, "unauthenticated")
+}
+
+func TestClientCertificates(t *testing.T) {
+	ca, err := tlsconfig.NewCA("test CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := ca.Issue(pkix.Name{CommonName: "localhost"}, "localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	partner, err := ca.Issue(pkix.Name{CommonName: "partner", Organization: []string{"Acme"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	options := tlsconfig.Options{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}
+	if err := server.WriteFiles(options.CertFile, options.KeyFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := ca.WriteFiles(options.ClientCAFile, filepath.Join(dir, "ca.key")); err != nil {
+		t.Fatal(err)
+	}
+	reloader, err := tlsconfig.NewReloader(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	clientTLS := func(with bool) credentials.TransportCredentials {
+		config := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+		if with {
+			config.Certificates = []tls.Certificate{{Certificate: [][]byte{partner.Cert.Raw}, PrivateKey: partner.Key}}
+		}
+		return credentials.NewTLS(config)
+	}
+
+	s := startTestServer(t, withTLS(credentials.NewTLS(reloader.Config("h2")), clientTLS(true),
+		map[string]string{"CN=partner,O=Acme": "partner"}))
+	partnerKey := s.issueClientKey(t, "partner", domain.ScopeAccountsRead)
+	otherKey := s.issueClientKey(t, "other", domain.ScopeAccountsRead)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	list := func(key string) error {
+		_, err := pb.NewAccountServiceClient(s.dial(t, key)).ListAccounts(ctx, &pb.ListAccountsRequest{})
+		return err
+	}
+	if err := list(partnerKey); err != nil {
+		t.Errorf("expected the partner to call with its certificate but got %v", err)
+	}
+	expectStatus(t, list(otherKey), codes.Unauthenticated, "certificate_mismatch")
+
+	s.creds = clientTLS(false)
+	expectStatus(t, list(partnerKey), codes.Unauthenticated, "certificate_mismatch")
+	if err := list(otherKey); err != nil {
+		t.Errorf("expected an unbound client to call without a certificate but got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+// Package rpc serves the account and transfer operations over gRPC, as
+// defined in api/proto/agiletransfer/v1. It calls the same services as the
+// REST handlers.
+package rpc
+
+import (
+	pb "github.com/petrostrak/agile-transfer/api/proto/agiletransfer/v1"
+	"github.com/petrostrak/agile-transfer/internal/config"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/tlsconfig"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer returns a gRPC server offering the account and transfer services,
+// and reflection so that tools such as grpcurl can discover them. Calls need
+// an API key, sent as x-api-key metadata. The actions approvalService says
+// need approval are refused, as approvals can only be asked for over REST.
+// The calls that change state are recorded with auditService. Calls count
+// towards apiLimit, and transfers towards transferLimit too, in the buckets
+// of rateLimitService that the REST API takes from. API clients holding a
+// secret of signingService must sign their calls. The API keys of the
+// clients certificateClients maps client certificate subjects to only work
+// over connections with one of their certificates, as for the REST API.
+// Listing accounts and making transfers are bounded by timeouts.
+func NewServer(accountService services.AccountService, transferService services.TransferService, apiKeyService services.APIKeyService, approvalService services.ApprovalService, auditService services.AuditService, rateLimitService services.RateLimitService, apiLimit, transferLimit domain.RouteLimit, signingService services.SigningService, certificateClients map[string]string, timeouts config.Handlers, opts ...grpc.ServerOption) *grpc.Server {
+	auth := &authenticator{apiKeyService, tlsconfig.NewClientSubjects(certificateClients)}
+	audit := &auditor{auditService}
+	limit := &limiter{rateLimitService, apiLimit, transferLimit}
+	sign := &signer{signingService}
+	opts = append(opts, grpc.ChainUnaryInterceptor(auth.unary, limit.unary, sign.unary, audit.unary), grpc.ChainStreamInterceptor(auth.stream, limit.stream, sign.stream))
+	s := grpc.NewServer(opts...)
+	pb.RegisterAccountServiceServer(s, NewAccountServer(accountService, approvalService, timeouts))
+	pb.RegisterTransferServiceServer(s, NewTransferServer(transferService, timeouts))
+	reflection.Register(s)
+	return s
+}
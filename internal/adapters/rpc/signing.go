@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// signer checks the signature of the calls of API clients holding a signing
+// secret, sent as x-signature, x-signature-timestamp and x-signature-nonce
+// metadata. A call is signed as a POST of its full method name whose body
+// is the deterministic serialization of the request message.
+type signer struct {
+	service services.SigningService
+}
+
+func (s *signer) verify(ctx context.Context, method string, req any) error {
+	key := apiClient(ctx)
+	if key == nil {
+		return nil
+	}
+
+	var body []byte
+	if m, ok := req.(proto.Message); ok {
+		var err error
+		body, err = proto.MarshalOptions{Deterministic: true}.Marshal(m)
+		if err != nil {
+			return statusError(err)
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	value := func(header string) string {
+		if values := md.Get(strings.ToLower(header)); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	err := s.service.Verify(ctx, services.SignedRequest{
+		Client:    key.Client,
+		Method:    http.MethodPost,
+		URI:       method,
+		Body:      body,
+		Signature: value(domain.SignatureHeader),
+		Timestamp: value(domain.SignatureTimestampHeader),
+		Nonce:     value(domain.SignatureNonceHeader),
+	})
+	if err != nil {
+		return statusError(err)
+	}
+	return nil
+}
+
+func (s *signer) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := s.verify(ctx, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// stream checks the signature of the first message of streaming calls, the
+// request of those streaming from the server.
+func (s *signer) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &signedStream{ServerStream: ss, signer: s, method: info.FullMethod})
+}
+
+type signedStream struct {
+	grpc.ServerStream
+	signer   *signer
+	method   string
+	verified bool
+}
+
+func (s *signedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.verified {
+		return nil
+	}
+	s.verified = true
+	return s.signer.verify(s.Context(), s.method, m)
+}
@@ -0,0 +1,124 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	pb "github.com/petrostrak/agile-transfer/api/proto/agiletransfer/v1"
+	"github.com/petrostrak/agile-transfer/internal/config"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+	"github.com/petrostrak/agile-transfer/utils"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// watchBuffer is how many transfers a WatchTransfers stream may fall behind
+// before it is ended.
+const watchBuffer = 256
+
+type TransferServer struct {
+	pb.UnimplementedTransferServiceServer
+	service  services.TransferService
+	timeouts config.Handlers
+}
+
+func NewTransferServer(transferService services.TransferService, timeouts config.Handlers) *TransferServer {
+	return &TransferServer{
+		service:  transferService,
+		timeouts: timeouts,
+	}
+}
+
+func (t *TransferServer) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeouts.TransferTimeout)
+	defer cancel()
+
+	v := validator.New()
+	sourceID := readID(v, "source_account_id", req.GetSourceAccountId())
+	targetID := readID(v, "target_account_id", req.GetTargetAccountId())
+	amount := readDecimal(v, "amount", req.GetAmount())
+	v.Check(sourceID != targetID, "target_account_id", "must differ from source_account_id")
+	domain.ValidateAmount(v, "amount", amount)
+	if req.GetSourceCurrency() != "" {
+		domain.ValidateCurrency(v, "source_currency", req.GetSourceCurrency(), "amount", amount)
+	}
+	if req.GetTargetCurrency() != "" {
+		domain.ValidateCurrency(v, "target_currency", req.GetTargetCurrency(), "", decimal.Zero)
+	}
+	if !v.Valid() {
+		return nil, failedValidation(v.Errors)
+	}
+
+	result, err := t.service.Transfer(ctx, sourceID, targetID, amount, req.GetSourceCurrency(), req.GetTargetCurrency())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &pb.CreateTransferResponse{
+		Transfer:      toTransfer(result.Transfer),
+		SourceAccount: toAccount(&result.SourceAccount),
+		TargetAccount: toAccount(&result.TargetAccount),
+	}, nil
+}
+
+func (t *TransferServer) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	v := validator.New()
+	page := readPage(v, req.GetPageSize(), req.GetPageToken())
+	if !v.Valid() {
+		return nil, failedValidation(v.Errors)
+	}
+
+	transfers, err := t.service.GetAll()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	transfers, metadata := utils.Paginate(transfers, page)
+
+	resp := &pb.ListTransfersResponse{NextPageToken: nextPageToken(metadata)}
+	for _, tx := range transfers {
+		resp.Transfers = append(resp.Transfers, toTransfer(tx))
+	}
+	return resp, nil
+}
+
+// WatchTransfers sends the response headers as soon as it is subscribed, so
+// that a client that waits for them knows it will not miss a transfer made
+// after that.
+func (t *TransferServer) WatchTransfers(req *pb.WatchTransfersRequest, stream pb.TransferService_WatchTransfersServer) error {
+	var accountID *uuid.UUID
+	if req.AccountId != nil {
+		v := validator.New()
+		id := readID(v, "account_id", req.GetAccountId())
+		if !v.Valid() {
+			return failedValidation(v.Errors)
+		}
+		accountID = &id
+	}
+
+	transfers, unsubscribe := t.service.Subscribe(watchBuffer)
+	defer unsubscribe()
+
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return statusError(stream.Context().Err())
+		case tx, ok := <-transfers:
+			if !ok {
+				return newStatus(codes.ResourceExhausted, "watch_fell_behind", "the stream fell too far behind and was ended")
+			}
+			if accountID != nil && tx.SourceAccountID != *accountID && tx.TargetAccountID != *accountID {
+				continue
+			}
+			if err := stream.Send(toTransfer(tx)); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Package currency is the registry of the ISO 4217 currencies the service
+// knows about, with the number of minor units each of them is counted in.
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyDisabled = errors.New("currency is not enabled")
+	ErrInvalidPrecision = errors.New("amount has more decimal places than the currency allows")
+)
+
+// Currency is an ISO 4217 currency. MinorUnit is the number of decimal places
+// amounts in the currency are expressed in, 2 for EUR, 0 for JPY and 3 for KWD.
+type Currency struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	MinorUnit int32  `json:"minor_unit"`
+	Enabled   bool   `json:"enabled"`
+}
+
+// Round rounds an amount to the minor unit of the currency, half to even.
+func (c Currency) Round(amount decimal.Decimal) decimal.Decimal {
+	return amount.RoundBank(c.MinorUnit)
+}
+
+// Validate reports whether the amount can be expressed in the currency
+// without rounding.
+func (c Currency) Validate(amount decimal.Decimal) error {
+	if !amount.Equal(amount.Truncate(c.MinorUnit)) {
+		return fmt.Errorf("%w: %s has %d", ErrInvalidPrecision, c.Code, c.MinorUnit)
+	}
+	return nil
+}
+
+// Registry holds the known currencies and whether each of them is enabled.
+// It is safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	currencies map[string]Currency
+}
+
+func NewRegistry(currencies []Currency) *Registry {
+	r := &Registry{currencies: make(map[string]Currency, len(currencies))}
+	for _, c := range currencies {
+		r.currencies[c.Code] = c
+	}
+	return r
+}
+
+// Get returns the currency with the given code, enabled or not.
+func (r *Registry) Get(code string) (Currency, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.currencies[Normalize(code)]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return c, nil
+}
+
+// Lookup returns the currency with the given code if it is enabled.
+func (r *Registry) Lookup(code string) (Currency, error) {
+	c, err := r.Get(code)
+	if err != nil {
+		return Currency{}, err
+	}
+	if !c.Enabled {
+		return Currency{}, fmt.Errorf("%w: %s", ErrCurrencyDisabled, c.Code)
+	}
+	return c, nil
+}
+
+// SetEnabled enables or disables a currency.
+func (r *Registry) SetEnabled(code string, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.currencies[Normalize(code)]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	c.Enabled = enabled
+	r.currencies[c.Code] = c
+
+	return nil
+}
+
+// All returns every known currency ordered by code.
+func (r *Registry) All() []Currency {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]Currency, 0, len(r.currencies))
+	for _, c := range r.currencies {
+		all = append(all, c)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Code < all[j].Code })
+
+	return all
+}
+
+// Default is the registry of the ISO 4217 currencies in circulation.
+var Default = NewRegistry(iso4217)
+
+// Normalize turns user input such as " eur" into the form codes are stored in.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Lookup returns the enabled currency with the given code from the default
+// registry.
+func Lookup(code string) (Currency, error) {
+	return Default.Lookup(code)
+}
+
+// Round rounds an amount to the minor unit of the given currency.
+func Round(code string, amount decimal.Decimal) (decimal.Decimal, error) {
+	c, err := Default.Get(code)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	return c.Round(amount), nil
+}
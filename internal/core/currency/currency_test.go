@@ -0,0 +1,87 @@
+package currency
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func Test_Lookup(t *testing.T) {
+	testCases := []struct {
+		code         string
+		expectedCode string
+		expectedErr  error
+	}{
+		{"EUR", "EUR", nil},
+		{" eur ", "EUR", nil},
+		{"EURO", "", ErrUnknownCurrency},
+		{"XYZ", "", ErrUnknownCurrency},
+		{"", "", ErrUnknownCurrency},
+		{"CLF", "", ErrCurrencyDisabled},
+	}
+
+	for _, tt := range testCases {
+		c, err := Lookup(tt.code)
+		if !errors.Is(err, tt.expectedErr) {
+			t.Errorf("%q: expected error %v but got %v", tt.code, tt.expectedErr, err)
+		}
+		if c.Code != tt.expectedCode {
+			t.Errorf("%q: expected code %q but got %q", tt.code, tt.expectedCode, c.Code)
+		}
+	}
+}
+
+func Test_RoundAndValidate(t *testing.T) {
+	testCases := []struct {
+		code     string
+		amount   string
+		rounded  string
+		validErr error
+	}{
+		{"EUR", "10.125", "10.12", ErrInvalidPrecision},
+		{"EUR", "10.10", "10.1", nil},
+		{"JPY", "1000.5", "1000", ErrInvalidPrecision},
+		{"JPY", "1000", "1000", nil},
+		{"KWD", "1.2345", "1.234", ErrInvalidPrecision},
+		{"KWD", "1.234", "1.234", nil},
+	}
+
+	for _, tt := range testCases {
+		c, _ := Lookup(tt.code)
+		amount := decimal.RequireFromString(tt.amount)
+
+		rounded := c.Round(amount)
+		if !rounded.Equal(decimal.RequireFromString(tt.rounded)) {
+			t.Errorf("%s %s: expected %s but got %v", tt.code, tt.amount, tt.rounded, rounded)
+		}
+
+		err := c.Validate(amount)
+		if !errors.Is(err, tt.validErr) {
+			t.Errorf("%s %s: expected error %v but got %v", tt.code, tt.amount, tt.validErr, err)
+		}
+	}
+}
+
+func Test_RegistrySetEnabled(t *testing.T) {
+	registry := NewRegistry([]Currency{{Code: "EUR", MinorUnit: 2, Enabled: true}})
+
+	err := registry.SetEnabled("eur", false)
+	if err != nil {
+		t.Errorf("error disabling currency: %s", err)
+	}
+
+	_, err = registry.Lookup("EUR")
+	if !errors.Is(err, ErrCurrencyDisabled) {
+		t.Errorf("expected disabled currency error but got %v", err)
+	}
+
+	if _, err := registry.Get("EUR"); err != nil {
+		t.Errorf("expected disabled currency to still be known but got %v", err)
+	}
+
+	err = registry.SetEnabled("USD", true)
+	if !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("expected unknown currency error but got %v", err)
+	}
+}
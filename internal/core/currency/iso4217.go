@@ -0,0 +1,172 @@
+package currency
+
+// iso4217 lists the active ISO 4217 currencies. Fund codes, which are units
+// of account rather than money, are known but disabled.
+var iso4217 = []Currency{
+	{Code: "AED", Name: "United Arab Emirates dirham", MinorUnit: 2, Enabled: true},
+	{Code: "AFN", Name: "Afghan afghani", MinorUnit: 2, Enabled: true},
+	{Code: "ALL", Name: "Albanian lek", MinorUnit: 2, Enabled: true},
+	{Code: "AMD", Name: "Armenian dram", MinorUnit: 2, Enabled: true},
+	{Code: "AOA", Name: "Angolan kwanza", MinorUnit: 2, Enabled: true},
+	{Code: "ARS", Name: "Argentine peso", MinorUnit: 2, Enabled: true},
+	{Code: "AUD", Name: "Australian dollar", MinorUnit: 2, Enabled: true},
+	{Code: "AWG", Name: "Aruban florin", MinorUnit: 2, Enabled: true},
+	{Code: "AZN", Name: "Azerbaijani manat", MinorUnit: 2, Enabled: true},
+	{Code: "BAM", Name: "Bosnia and Herzegovina convertible mark", MinorUnit: 2, Enabled: true},
+	{Code: "BBD", Name: "Barbados dollar", MinorUnit: 2, Enabled: true},
+	{Code: "BDT", Name: "Bangladeshi taka", MinorUnit: 2, Enabled: true},
+	{Code: "BGN", Name: "Bulgarian lev", MinorUnit: 2, Enabled: true},
+	{Code: "BHD", Name: "Bahraini dinar", MinorUnit: 3, Enabled: true},
+	{Code: "BIF", Name: "Burundian franc", MinorUnit: 0, Enabled: true},
+	{Code: "BMD", Name: "Bermudian dollar", MinorUnit: 2, Enabled: true},
+	{Code: "BND", Name: "Brunei dollar", MinorUnit: 2, Enabled: true},
+	{Code: "BOB", Name: "Boliviano", MinorUnit: 2, Enabled: true},
+	{Code: "BRL", Name: "Brazilian real", MinorUnit: 2, Enabled: true},
+	{Code: "BSD", Name: "Bahamian dollar", MinorUnit: 2, Enabled: true},
+	{Code: "BTN", Name: "Bhutanese ngultrum", MinorUnit: 2, Enabled: true},
+	{Code: "BWP", Name: "Botswana pula", MinorUnit: 2, Enabled: true},
+	{Code: "BYN", Name: "Belarusian ruble", MinorUnit: 2, Enabled: true},
+	{Code: "BZD", Name: "Belize dollar", MinorUnit: 2, Enabled: true},
+	{Code: "CAD", Name: "Canadian dollar", MinorUnit: 2, Enabled: true},
+	{Code: "CDF", Name: "Congolese franc", MinorUnit: 2, Enabled: true},
+	{Code: "CHF", Name: "Swiss franc", MinorUnit: 2, Enabled: true},
+	{Code: "CLP", Name: "Chilean peso", MinorUnit: 0, Enabled: true},
+	{Code: "CNY", Name: "Renminbi", MinorUnit: 2, Enabled: true},
+	{Code: "COP", Name: "Colombian peso", MinorUnit: 2, Enabled: true},
+	{Code: "CRC", Name: "Costa Rican colon", MinorUnit: 2, Enabled: true},
+	{Code: "CUP", Name: "Cuban peso", MinorUnit: 2, Enabled: true},
+	{Code: "CVE", Name: "Cape Verdean escudo", MinorUnit: 2, Enabled: true},
+	{Code: "CZK", Name: "Czech koruna", MinorUnit: 2, Enabled: true},
+	{Code: "DJF", Name: "Djiboutian franc", MinorUnit: 0, Enabled: true},
+	{Code: "DKK", Name: "Danish krone", MinorUnit: 2, Enabled: true},
+	{Code: "DOP", Name: "Dominican peso", MinorUnit: 2, Enabled: true},
+	{Code: "DZD", Name: "Algerian dinar", MinorUnit: 2, Enabled: true},
+	{Code: "EGP", Name: "Egyptian pound", MinorUnit: 2, Enabled: true},
+	{Code: "ERN", Name: "Eritrean nakfa", MinorUnit: 2, Enabled: true},
+	{Code: "ETB", Name: "Ethiopian birr", MinorUnit: 2, Enabled: true},
+	{Code: "EUR", Name: "Euro", MinorUnit: 2, Enabled: true},
+	{Code: "FJD", Name: "Fiji dollar", MinorUnit: 2, Enabled: true},
+	{Code: "FKP", Name: "Falkland Islands pound", MinorUnit: 2, Enabled: true},
+	{Code: "GBP", Name: "Pound sterling", MinorUnit: 2, Enabled: true},
+	{Code: "GEL", Name: "Georgian lari", MinorUnit: 2, Enabled: true},
+	{Code: "GHS", Name: "Ghanaian cedi", MinorUnit: 2, Enabled: true},
+	{Code: "GIP", Name: "Gibraltar pound", MinorUnit: 2, Enabled: true},
+	{Code: "GMD", Name: "Gambian dalasi", MinorUnit: 2, Enabled: true},
+	{Code: "GNF", Name: "Guinean franc", MinorUnit: 0, Enabled: true},
+	{Code: "GTQ", Name: "Guatemalan quetzal", MinorUnit: 2, Enabled: true},
+	{Code: "GYD", Name: "Guyanese dollar", MinorUnit: 2, Enabled: true},
+	{Code: "HKD", Name: "Hong Kong dollar", MinorUnit: 2, Enabled: true},
+	{Code: "HNL", Name: "Honduran lempira", MinorUnit: 2, Enabled: true},
+	{Code: "HTG", Name: "Haitian gourde", MinorUnit: 2, Enabled: true},
+	{Code: "HUF", Name: "Hungarian forint", MinorUnit: 2, Enabled: true},
+	{Code: "IDR", Name: "Indonesian rupiah", MinorUnit: 2, Enabled: true},
+	{Code: "ILS", Name: "Israeli new shekel", MinorUnit: 2, Enabled: true},
+	{Code: "INR", Name: "Indian rupee", MinorUnit: 2, Enabled: true},
+	{Code: "IQD", Name: "Iraqi dinar", MinorUnit: 3, Enabled: true},
+	{Code: "IRR", Name: "Iranian rial", MinorUnit: 2, Enabled: true},
+	{Code: "ISK", Name: "Icelandic krona", MinorUnit: 0, Enabled: true},
+	{Code: "JMD", Name: "Jamaican dollar", MinorUnit: 2, Enabled: true},
+	{Code: "JOD", Name: "Jordanian dinar", MinorUnit: 3, Enabled: true},
+	{Code: "JPY", Name: "Japanese yen", MinorUnit: 0, Enabled: true},
+	{Code: "KES", Name: "Kenyan shilling", MinorUnit: 2, Enabled: true},
+	{Code: "KGS", Name: "Kyrgyzstani som", MinorUnit: 2, Enabled: true},
+	{Code: "KHR", Name: "Cambodian riel", MinorUnit: 2, Enabled: true},
+	{Code: "KMF", Name: "Comoro franc", MinorUnit: 0, Enabled: true},
+	{Code: "KPW", Name: "North Korean won", MinorUnit: 2, Enabled: true},
+	{Code: "KRW", Name: "South Korean won", MinorUnit: 0, Enabled: true},
+	{Code: "KWD", Name: "Kuwaiti dinar", MinorUnit: 3, Enabled: true},
+	{Code: "KYD", Name: "Cayman Islands dollar", MinorUnit: 2, Enabled: true},
+	{Code: "KZT", Name: "Kazakhstani tenge", MinorUnit: 2, Enabled: true},
+	{Code: "LAK", Name: "Lao kip", MinorUnit: 2, Enabled: true},
+	{Code: "LBP", Name: "Lebanese pound", MinorUnit: 2, Enabled: true},
+	{Code: "LKR", Name: "Sri Lankan rupee", MinorUnit: 2, Enabled: true},
+	{Code: "LRD", Name: "Liberian dollar", MinorUnit: 2, Enabled: true},
+	{Code: "LSL", Name: "Lesotho loti", MinorUnit: 2, Enabled: true},
+	{Code: "LYD", Name: "Libyan dinar", MinorUnit: 3, Enabled: true},
+	{Code: "MAD", Name: "Moroccan dirham", MinorUnit: 2, Enabled: true},
+	{Code: "MDL", Name: "Moldovan leu", MinorUnit: 2, Enabled: true},
+	{Code: "MGA", Name: "Malagasy ariary", MinorUnit: 2, Enabled: true},
+	{Code: "MKD", Name: "Macedonian denar", MinorUnit: 2, Enabled: true},
+	{Code: "MMK", Name: "Myanmar kyat", MinorUnit: 2, Enabled: true},
+	{Code: "MNT", Name: "Mongolian togrog", MinorUnit: 2, Enabled: true},
+	{Code: "MOP", Name: "Macanese pataca", MinorUnit: 2, Enabled: true},
+	{Code: "MRU", Name: "Mauritanian ouguiya", MinorUnit: 2, Enabled: true},
+	{Code: "MUR", Name: "Mauritian rupee", MinorUnit: 2, Enabled: true},
+	{Code: "MVR", Name: "Maldivian rufiyaa", MinorUnit: 2, Enabled: true},
+	{Code: "MWK", Name: "Malawian kwacha", MinorUnit: 2, Enabled: true},
+	{Code: "MXN", Name: "Mexican peso", MinorUnit: 2, Enabled: true},
+	{Code: "MYR", Name: "Malaysian ringgit", MinorUnit: 2, Enabled: true},
+	{Code: "MZN", Name: "Mozambican metical", MinorUnit: 2, Enabled: true},
+	{Code: "NAD", Name: "Namibian dollar", MinorUnit: 2, Enabled: true},
+	{Code: "NGN", Name: "Nigerian naira", MinorUnit: 2, Enabled: true},
+	{Code: "NIO", Name: "Nicaraguan cordoba", MinorUnit: 2, Enabled: true},
+	{Code: "NOK", Name: "Norwegian krone", MinorUnit: 2, Enabled: true},
+	{Code: "NPR", Name: "Nepalese rupee", MinorUnit: 2, Enabled: true},
+	{Code: "NZD", Name: "New Zealand dollar", MinorUnit: 2, Enabled: true},
+	{Code: "OMR", Name: "Omani rial", MinorUnit: 3, Enabled: true},
+	{Code: "PAB", Name: "Panamanian balboa", MinorUnit: 2, Enabled: true},
+	{Code: "PEN", Name: "Peruvian sol", MinorUnit: 2, Enabled: true},
+	{Code: "PGK", Name: "Papua New Guinean kina", MinorUnit: 2, Enabled: true},
+	{Code: "PHP", Name: "Philippine peso", MinorUnit: 2, Enabled: true},
+	{Code: "PKR", Name: "Pakistani rupee", MinorUnit: 2, Enabled: true},
+	{Code: "PLN", Name: "Polish zloty", MinorUnit: 2, Enabled: true},
+	{Code: "PYG", Name: "Paraguayan guarani", MinorUnit: 0, Enabled: true},
+	{Code: "QAR", Name: "Qatari riyal", MinorUnit: 2, Enabled: true},
+	{Code: "RON", Name: "Romanian leu", MinorUnit: 2, Enabled: true},
+	{Code: "RSD", Name: "Serbian dinar", MinorUnit: 2, Enabled: true},
+	{Code: "RUB", Name: "Russian ruble", MinorUnit: 2, Enabled: true},
+	{Code: "RWF", Name: "Rwandan franc", MinorUnit: 0, Enabled: true},
+	{Code: "SAR", Name: "Saudi riyal", MinorUnit: 2, Enabled: true},
+	{Code: "SBD", Name: "Solomon Islands dollar", MinorUnit: 2, Enabled: true},
+	{Code: "SCR", Name: "Seychelles rupee", MinorUnit: 2, Enabled: true},
+	{Code: "SDG", Name: "Sudanese pound", MinorUnit: 2, Enabled: true},
+	{Code: "SEK", Name: "Swedish krona", MinorUnit: 2, Enabled: true},
+	{Code: "SGD", Name: "Singapore dollar", MinorUnit: 2, Enabled: true},
+	{Code: "SHP", Name: "Saint Helena pound", MinorUnit: 2, Enabled: true},
+	{Code: "SLE", Name: "Sierra Leonean leone", MinorUnit: 2, Enabled: true},
+	{Code: "SOS", Name: "Somali shilling", MinorUnit: 2, Enabled: true},
+	{Code: "SRD", Name: "Surinamese dollar", MinorUnit: 2, Enabled: true},
+	{Code: "SSP", Name: "South Sudanese pound", MinorUnit: 2, Enabled: true},
+	{Code: "STN", Name: "Sao Tome and Principe dobra", MinorUnit: 2, Enabled: true},
+	{Code: "SVC", Name: "Salvadoran colon", MinorUnit: 2, Enabled: true},
+	{Code: "SYP", Name: "Syrian pound", MinorUnit: 2, Enabled: true},
+	{Code: "SZL", Name: "Swazi lilangeni", MinorUnit: 2, Enabled: true},
+	{Code: "THB", Name: "Thai baht", MinorUnit: 2, Enabled: true},
+	{Code: "TJS", Name: "Tajikistani somoni", MinorUnit: 2, Enabled: true},
+	{Code: "TMT", Name: "Turkmenistan manat", MinorUnit: 2, Enabled: true},
+	{Code: "TND", Name: "Tunisian dinar", MinorUnit: 3, Enabled: true},
+	{Code: "TOP", Name: "Tongan pa'anga", MinorUnit: 2, Enabled: true},
+	{Code: "TRY", Name: "Turkish lira", MinorUnit: 2, Enabled: true},
+	{Code: "TTD", Name: "Trinidad and Tobago dollar", MinorUnit: 2, Enabled: true},
+	{Code: "TWD", Name: "New Taiwan dollar", MinorUnit: 2, Enabled: true},
+	{Code: "TZS", Name: "Tanzanian shilling", MinorUnit: 2, Enabled: true},
+	{Code: "UAH", Name: "Ukrainian hryvnia", MinorUnit: 2, Enabled: true},
+	{Code: "UGX", Name: "Ugandan shilling", MinorUnit: 0, Enabled: true},
+	{Code: "USD", Name: "United States dollar", MinorUnit: 2, Enabled: true},
+	{Code: "UYU", Name: "Uruguayan peso", MinorUnit: 2, Enabled: true},
+	{Code: "UZS", Name: "Uzbekistani sum", MinorUnit: 2, Enabled: true},
+	{Code: "VED", Name: "Venezuelan digital bolivar", MinorUnit: 2, Enabled: true},
+	{Code: "VES", Name: "Venezuelan sovereign bolivar", MinorUnit: 2, Enabled: true},
+	{Code: "VND", Name: "Vietnamese dong", MinorUnit: 0, Enabled: true},
+	{Code: "VUV", Name: "Vanuatu vatu", MinorUnit: 0, Enabled: true},
+	{Code: "WST", Name: "Samoan tala", MinorUnit: 2, Enabled: true},
+	{Code: "XAF", Name: "Central African CFA franc", MinorUnit: 0, Enabled: true},
+	{Code: "XCD", Name: "East Caribbean dollar", MinorUnit: 2, Enabled: true},
+	{Code: "XCG", Name: "Caribbean guilder", MinorUnit: 2, Enabled: true},
+	{Code: "XOF", Name: "West African CFA franc", MinorUnit: 0, Enabled: true},
+	{Code: "XPF", Name: "CFP franc", MinorUnit: 0, Enabled: true},
+	{Code: "YER", Name: "Yemeni rial", MinorUnit: 2, Enabled: true},
+	{Code: "ZAR", Name: "South African rand", MinorUnit: 2, Enabled: true},
+	{Code: "ZMW", Name: "Zambian kwacha", MinorUnit: 2, Enabled: true},
+	{Code: "ZWG", Name: "Zimbabwe Gold", MinorUnit: 2, Enabled: true},
+
+	{Code: "BOV", Name: "Bolivian Mvdol", MinorUnit: 2, Enabled: false},
+	{Code: "CHE", Name: "WIR euro", MinorUnit: 2, Enabled: false},
+	{Code: "CHW", Name: "WIR franc", MinorUnit: 2, Enabled: false},
+	{Code: "CLF", Name: "Unidad de Fomento", MinorUnit: 4, Enabled: false},
+	{Code: "COU", Name: "Unidad de Valor Real", MinorUnit: 2, Enabled: false},
+	{Code: "MXV", Name: "Mexican Unidad de Inversion", MinorUnit: 2, Enabled: false},
+	{Code: "USN", Name: "United States dollar next day", MinorUnit: 2, Enabled: false},
+	{Code: "UYI", Name: "Uruguay Peso en Unidades Indexadas", MinorUnit: 0, Enabled: false},
+	{Code: "UYW", Name: "Unidad previsional", MinorUnit: 4, Enabled: false},
+}
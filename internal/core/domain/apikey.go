@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+)
+
+// Scope is a permission granted to an API key.
+type Scope string
+
+const (
+	ScopeAccountsRead    Scope = "accounts:read"
+	ScopeAccountsWrite   Scope = "accounts:write"
+	ScopeTransfersCreate Scope = "transfers:create"
+	// ScopeAdmin allows everything, including managing the API keys.
+	ScopeAdmin Scope = "admin"
+)
+
+// APIKey identifies a client of the API. Only a hash of the secret is kept:
+// the key itself is shown once, when it is issued. Prefix is the public part
+// of the key, which it is looked up by.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	Client     string     `json:"client"`
+	Prefix     string     `json:"prefix"`
+	Hash       []byte     `json:"-"`
+	Scopes     []Scope    `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// Active reports whether the key may be used at the given time.
+func (k *APIKey) Active(at time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || at.Before(*k.ExpiresAt)
+}
+
+// Allows reports whether the key grants scope. Admin keys grant every scope.
+func (k *APIKey) Allows(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidateAPIKey(v *validator.Validator, k *APIKey) {
+	v.Check(k.Client != "", "client", "must be provided")
+	v.Check(len(k.Client) <= 100, "client", "must not be more than 100 bytes long")
+	v.Check(len(k.Scopes) > 0, "scopes", "must contain at least one scope")
+	v.Check(validator.Unique(k.Scopes), "scopes", "must not contain duplicate scopes")
+	for _, s := range k.Scopes {
+		if !validator.In(s, ScopeAccountsRead, ScopeAccountsWrite, ScopeTransfersCreate, ScopeAdmin) {
+			v.AddError("scopes", "must be accounts:read, accounts:write, transfers:create or admin")
+			break
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_APIKeyAllows(t *testing.T) {
+	reader := APIKey{Scopes: []Scope{ScopeAccountsRead}}
+	if !reader.Allows(ScopeAccountsRead) || reader.Allows(ScopeAccountsWrite) || reader.Allows(ScopeAdmin) {
+		t.Errorf("expected %v to only allow reading accounts", reader.Scopes)
+	}
+
+	admin := APIKey{Scopes: []Scope{ScopeAdmin}}
+	for _, scope := range []Scope{ScopeAccountsRead, ScopeAccountsWrite, ScopeTransfersCreate, ScopeAdmin} {
+		if !admin.Allows(scope) {
+			t.Errorf("expected an admin key to allow %s", scope)
+		}
+	}
+}
+
+func Test_APIKeyActive(t *testing.T) {
+	now := time.Date(2023, time.June, 30, 12, 0, 0, 0, time.UTC)
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+
+	testCases := []struct {
+		name     string
+		key      APIKey
+		expected bool
+	}{
+		{"new", APIKey{}, true},
+		{"rotated", APIKey{ExpiresAt: &later}, true},
+		{"expired", APIKey{ExpiresAt: &earlier}, false},
+		{"expiring now", APIKey{ExpiresAt: &now}, false},
+		{"revoked", APIKey{RevokedAt: &earlier}, false},
+	}
+
+	for _, tt := range testCases {
+		if active := tt.key.Active(now); active != tt.expected {
+			t.Errorf("%s: expected active to be %t but got %t", tt.name, tt.expected, active)
+		}
+	}
+}
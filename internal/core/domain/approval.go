@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
+	"github.com/shopspring/decimal"
+)
+
+// ApprovalAction is an action that needs a second person's approval.
+type ApprovalAction string
+
+const (
+	ApprovalTransfer          ApprovalAction = "transfer"
+	ApprovalBalanceAdjustment ApprovalAction = "balance_adjustment"
+	ApprovalAccountClosure    ApprovalAction = "account_closure"
+)
+
+// Permission is the permission needed to request the action, and so to
+// approve it.
+func (a ApprovalAction) Permission() Permission {
+	switch a {
+	case ApprovalTransfer:
+		return PermissionTransfersCreate
+	case ApprovalBalanceAdjustment:
+		return PermissionBalancesAdjust
+	default:
+		return PermissionAccountsManage
+	}
+}
+
+type ApprovalStatus string
+
+const (
+	// ApprovalPending approvals wait for a decision until they expire.
+	ApprovalPending ApprovalStatus = "pending"
+	// ApprovalApproved approvals were approved and their action carried out.
+	ApprovalApproved ApprovalStatus = "approved"
+	ApprovalRejected ApprovalStatus = "rejected"
+	ApprovalExpired  ApprovalStatus = "expired"
+	// ApprovalFailed approvals were approved but their action failed, for
+	// instance because the account changed in the meantime.
+	ApprovalFailed ApprovalStatus = "failed"
+)
+
+// Approval is an action held until someone other than who asked for it
+// approves it. Transfer is set for transfers, Change for the other actions.
+type Approval struct {
+	ID     uuid.UUID      `json:"id"`
+	Action ApprovalAction `json:"action"`
+	Status ApprovalStatus `json:"status"`
+	// AccountID is the account the action changes, the source account of
+	// transfers.
+	AccountID uuid.UUID      `json:"account_id"`
+	Transfer  *TransferOrder `json:"transfer"`
+	Change    *AccountChange `json:"change"`
+	// RequestedBy and DecidedBy name the callers, as the audit trail does.
+	RequestedBy string  `json:"requested_by"`
+	DecidedBy   *string `json:"decided_by"`
+	// Reason is why the approval was rejected or failed.
+	Reason string `json:"reason"`
+	// TransferID is the transfer made when a transfer was approved.
+	TransferID *uuid.UUID `json:"transfer_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	DecidedAt  *time.Time `json:"decided_at"`
+}
+
+// TransferOrder is a transfer waiting to be made.
+type TransferOrder struct {
+	TargetAccountID uuid.UUID       `json:"target_account_id"`
+	Amount          decimal.Decimal `json:"amount"`
+	SourceCurrency  string          `json:"source_currency"`
+	TargetCurrency  string          `json:"target_currency"`
+}
+
+// AccountChange is the state an account is to be brought to, or closed in,
+// from the version it was at when the change was asked for.
+type AccountChange struct {
+	Version    int             `json:"version"`
+	Balance    decimal.Decimal `json:"balance"`
+	Currency   string          `json:"currency"`
+	CustomerID *uuid.UUID      `json:"customer_id"`
+}
+
+// ApprovalPolicy says which actions need approval. The zero policy needs
+// none.
+type ApprovalPolicy struct {
+	// TransferThresholds are, by currency, the amounts above which
+	// transfers need approval. Once there is any, transfers in other
+	// currencies need approval whatever their amount.
+	TransferThresholds map[string]decimal.Decimal
+	// Adjustments is whether changing the balance or currency of an account
+	// needs approval.
+	Adjustments bool
+	// Closures is whether deleting an account needs approval.
+	Closures bool
+	// TTL is how long approvals wait for a decision.
+	TTL time.Duration
+}
+
+// TransferNeedsApproval reports whether a transfer of amount needs approval.
+// A currency left out of the thresholds fails closed.
+func (p ApprovalPolicy) TransferNeedsApproval(amount Money) bool {
+	if len(p.TransferThresholds) == 0 {
+		return false
+	}
+	threshold, ok := p.TransferThresholds[currency.Normalize(amount.Currency)]
+	return !ok || amount.Amount.GreaterThan(threshold)
+}
+
+// ChangeNeedsApproval reports whether bringing account to change needs
+// approval.
+func (p ApprovalPolicy) ChangeNeedsApproval(account *Account, change *AccountChange) bool {
+	return p.Adjustments && (!change.Balance.Equal(account.Balance) || currency.Normalize(change.Currency) != account.Currency)
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func Test_ApprovalPolicy(t *testing.T) {
+	policy := ApprovalPolicy{
+		TransferThresholds: map[string]decimal.Decimal{"EUR": decimal.NewFromInt(1000)},
+		Adjustments:        true,
+	}
+
+	transfers := []struct {
+		amount   Money
+		expected bool
+	}{
+		{NewMoney(decimal.NewFromInt(1000), "EUR"), false},
+		{NewMoney(decimal.RequireFromString("1000.01"), "eur"), true},
+		{NewMoney(decimal.NewFromInt(1), "USD"), true},
+	}
+	for _, tt := range transfers {
+		if needs := policy.TransferNeedsApproval(tt.amount); needs != tt.expected {
+			t.Errorf("%s: expected needing approval to be %t but got %t", tt.amount, tt.expected, needs)
+		}
+	}
+
+	account := &Account{Balance: decimal.NewFromInt(10), Currency: "EUR"}
+	changes := []struct {
+		name     string
+		change   AccountChange
+		expected bool
+	}{
+		{"unchanged", AccountChange{Balance: decimal.RequireFromString("10.00"), Currency: "eur"}, false},
+		{"balance", AccountChange{Balance: decimal.NewFromInt(20), Currency: "EUR"}, true},
+		{"currency", AccountChange{Balance: decimal.NewFromInt(10), Currency: "USD"}, true},
+	}
+	for _, tt := range changes {
+		if needs := policy.ChangeNeedsApproval(account, &tt.change); needs != tt.expected {
+			t.Errorf("%s: expected needing approval to be %t but got %t", tt.name, tt.expected, needs)
+		}
+	}
+
+	var none ApprovalPolicy
+	if none.TransferNeedsApproval(NewMoney(decimal.NewFromInt(1e9), "EUR")) || none.ChangeNeedsApproval(account, &changes[1].change) {
+		t.Error("expected the zero policy to need no approvals")
+	}
+}
@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditAction names what an audit entry records.
+type AuditAction string
+
+const (
+	// AuditAccessDenied records a request refused for lack of permission.
+	AuditAccessDenied AuditAction = "access.denied"
+
+	AuditAccountCreated     AuditAction = "account.created"
+	AuditAccountUpdated     AuditAction = "account.updated"
+	AuditAccountDeleted     AuditAction = "account.deleted"
+	AuditAccountFrozen      AuditAction = "account.frozen"
+	AuditAccountUnfrozen    AuditAction = "account.unfrozen"
+	AuditPocketOpened       AuditAction = "pocket.opened"
+	AuditPocketsConverted   AuditAction = "pockets.converted"
+	AuditInterestSet        AuditAction = "interest.set"
+	AuditInterestRun        AuditAction = "interest.run"
+	AuditCustomerCreated    AuditAction = "customer.created"
+	AuditCustomerUpdated    AuditAction = "customer.updated"
+	AuditCustomerDeleted    AuditAction = "customer.deleted"
+	AuditBeneficiaryAdded   AuditAction = "beneficiary.added"
+	AuditBeneficiaryRemoved AuditAction = "beneficiary.removed"
+	AuditTransferCreated    AuditAction = "transfer.created"
+	AuditTransferReversed   AuditAction = "transfer.reversed"
+	AuditAPIKeyIssued       AuditAction = "api_key.issued"
+	AuditAPIKeyRotated      AuditAction = "api_key.rotated"
+	AuditAPIKeyRevoked      AuditAction = "api_key.revoked"
+	AuditSecretIssued       AuditAction = "signing_secret.issued"
+	AuditSecretRotated      AuditAction = "signing_secret.rotated"
+	AuditSecretRevoked      AuditAction = "signing_secret.revoked"
+	AuditRoleCreated        AuditAction = "role.created"
+	AuditRoleUpdated        AuditAction = "role.updated"
+	AuditRoleDeleted        AuditAction = "role.deleted"
+	AuditApprovalRequested  AuditAction = "approval.requested"
+	AuditApprovalApproved   AuditAction = "approval.approved"
+	AuditApprovalRejected   AuditAction = "approval.rejected"
+	// AuditRequest records a state-changing request whose handler did not
+	// describe the change.
+	AuditRequest AuditAction = "request"
+)
+
+// AuditActorInterest is the actor of the transfers the interest job makes.
+const AuditActorInterest = "job:interest"
+
+// AuditEntry records who did, or tried to do, what to which resource. Actor
+// is "key:" followed by the client of an API key, "user:" followed by the
+// subject of a bearer token, or AuditActorInterest for the interest job.
+//
+// Entries are append-only and chained: Hash covers the entry and the hash of
+// the entry before it, PrevHash, so that changing or removing an entry
+// breaks the chain from there on.
+type AuditEntry struct {
+	ID         int64       `json:"id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Actor      string      `json:"actor"`
+	Action     AuditAction `json:"action"`
+	Resource   string      `json:"resource"`
+	RequestID  string      `json:"request_id"`
+	ClientIP   string      `json:"client_ip"`
+	Detail     string      `json:"detail"`
+	// Before and After are JSON snapshots of the resource, nil when it did
+	// not exist before or after the change, or was not described.
+	Before   json.RawMessage `json:"before"`
+	After    json.RawMessage `json:"after"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash"`
+}
+
+// Seal chains the entry to the one before it, whose hash is prev. Entries
+// are stored to the microsecond, so OccurredAt is truncated to it first.
+func (e *AuditEntry) Seal(prev string) {
+	e.OccurredAt = e.OccurredAt.UTC().Truncate(time.Microsecond)
+	e.PrevHash = prev
+	e.Hash = e.ComputeHash()
+}
+
+// ComputeHash is the SHA-256 of the fields of the entry but its id and hash,
+// each prefixed with its length so that no two entries share an input.
+func (e *AuditEntry) ComputeHash() string {
+	h := sha256.New()
+	fields := []string{
+		e.PrevHash,
+		e.OccurredAt.UTC().Format(time.RFC3339Nano),
+		e.Actor,
+		string(e.Action),
+		e.Resource,
+		e.RequestID,
+		e.ClientIP,
+		e.Detail,
+		string(e.Before),
+		string(e.After),
+	}
+	for _, field := range fields {
+		fmt.Fprintf(h, "%d:%s\n", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AuditFilter selects audit entries. Empty fields select every entry.
+type AuditFilter struct {
+	Actor    string
+	Action   AuditAction
+	Resource string
+	From, To time.Time
+}
+
+// AuditVerification is the outcome of checking the audit chain.
+type AuditVerification struct {
+	// Entries is how many entries were checked, and Unsealed how many of
+	// them were recorded before entries were chained.
+	Entries  int `json:"entries"`
+	Unsealed int `json:"unsealed"`
+	// BrokenAt is the id of the first entry that does not match its hash or
+	// the entry before it, nil when the chain is intact.
+	BrokenAt *int64 `json:"broken_at"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+func (v *AuditVerification) Intact() bool {
+	return v.BrokenAt == nil
+}
+
+// AuditChain checks entries, ordered by id, one batch at a time. The hash of
+// the last entry checked is carried from one batch to the next.
+type AuditChain struct {
+	result AuditVerification
+	prev   string
+	sealed bool
+}
+
+// Check checks the next entries of the chain, returning false once it is
+// broken.
+func (c *AuditChain) Check(entries []AuditEntry) bool {
+	for i := range entries {
+		if c.result.BrokenAt != nil {
+			return false
+		}
+		e := &entries[i]
+		c.result.Entries++
+
+		switch {
+		case e.Hash == "" && !c.sealed:
+			// Entries recorded before the log was chained.
+			c.result.Unsealed++
+			continue
+		case e.Hash == "":
+			c.broken(e, "entry is not sealed")
+		case e.PrevHash != c.prev:
+			c.broken(e, "previous hash does not match the entry before")
+		case e.ComputeHash() != e.Hash:
+			c.broken(e, "hash does not match the entry")
+		}
+		c.sealed = true
+		c.prev = e.Hash
+	}
+	return c.result.BrokenAt == nil
+}
+
+func (c *AuditChain) broken(e *AuditEntry, reason string) {
+	id := e.ID
+	c.result.BrokenAt, c.result.Reason = &id, reason
+}
+
+// Result is the outcome of the entries checked so far.
+func (c *AuditChain) Result() AuditVerification {
+	return c.result
+}
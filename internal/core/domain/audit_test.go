@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func Test_AuditChain(t *testing.T) {
+	at := time.Date(2026, time.October, 19, 12, 0, 0, 123456789, time.FixedZone("EEST", 3*60*60))
+
+	var entries []AuditEntry
+	prev := ""
+	for i, action := range []AuditAction{AuditAccountCreated, AuditAccountUpdated, AuditAccountDeleted} {
+		e := AuditEntry{ID: int64(i + 1), OccurredAt: at, Actor: "key:test", Action: action, Resource: "accounts/1", After: json.RawMessage(`{"balance":"10.00"}`)}
+		e.Seal(prev)
+		prev = e.Hash
+		entries = append(entries, e)
+	}
+
+	if got := entries[0].OccurredAt; got.Location() != time.UTC || got.Nanosecond() != 123456000 {
+		t.Errorf("expected the time to be sealed in UTC to the microsecond but got %s", got)
+	}
+	if entries[1].PrevHash != entries[0].Hash || entries[0].Hash == entries[1].Hash {
+		t.Errorf("expected the entries to be chained")
+	}
+
+	// Moving bytes from one field to the next must change the hash.
+	a := AuditEntry{Actor: "key:ab", Resource: "c"}
+	b := AuditEntry{Actor: "key:a", Resource: "bc"}
+	if a.ComputeHash() == b.ComputeHash() {
+		t.Errorf("expected different fields to hash differently")
+	}
+
+	var chain AuditChain
+	if !chain.Check(entries[:2]) || !chain.Check(entries[2:]) {
+		t.Fatalf("expected the chain to be intact but got %+v", chain.Result())
+	}
+	if result := chain.Result(); !result.Intact() || result.Entries != 3 {
+		t.Errorf("expected 3 intact entries but got %+v", result)
+	}
+
+	legacy := append([]AuditEntry{{ID: 0, Actor: "user:jane"}}, entries...)
+	chain = AuditChain{}
+	if !chain.Check(legacy) || chain.Result().Unsealed != 1 {
+		t.Errorf("expected the entry from before chaining to be allowed but got %+v", chain.Result())
+	}
+
+	tests := []struct {
+		name   string
+		change func([]AuditEntry) []AuditEntry
+		broken int64
+	}{
+		{"changed", func(e []AuditEntry) []AuditEntry { e[1].Actor = "key:other"; return e }, 2},
+		{"removed", func(e []AuditEntry) []AuditEntry { return append(e[:1], e[2:]...) }, 3},
+		{"unsealed", func(e []AuditEntry) []AuditEntry { e[2].Hash = ""; return e }, 3},
+	}
+	for _, tt := range tests {
+		tampered := tt.change(append([]AuditEntry(nil), entries...))
+		chain := AuditChain{}
+		if chain.Check(tampered) {
+			t.Errorf("%s: expected the chain to be broken", tt.name)
+			continue
+		}
+		if result := chain.Result(); *result.BrokenAt != tt.broken || result.Reason == "" {
+			t.Errorf("%s: expected the chain to break at %d but got %+v", tt.name, tt.broken, result)
+		}
+	}
+}
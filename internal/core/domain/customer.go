@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type CustomerType string
+
+const (
+	CustomerIndividual CustomerType = "individual"
+	CustomerBusiness   CustomerType = "business"
+)
+
+type CustomerStatus string
+
+const (
+	CustomerActive    CustomerStatus = "active"
+	CustomerSuspended CustomerStatus = "suspended"
+	CustomerClosed    CustomerStatus = "closed"
+)
+
+// Customer is the holder of one or more accounts. When RestrictTransfers is
+// set, the customer's accounts can only send money to each other or to the
+// customer's beneficiaries. Subject is the subject of the customer's user at
+// the identity provider, which bearer tokens are mapped to the customer by.
+type Customer struct {
+	ID                uuid.UUID      `json:"id"`
+	Name              string         `json:"name"`
+	Email             string         `json:"email"`
+	Type              CustomerType   `json:"type"`
+	Status            CustomerStatus `json:"status"`
+	RestrictTransfers bool           `json:"restrict_transfers"`
+	Subject           *string        `json:"subject"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+}
+
+func (c Customer) Valid() bool {
+	if c.Name == "" || c.Email == "" {
+		return false
+	}
+	switch c.Type {
+	case CustomerIndividual, CustomerBusiness:
+	default:
+		return false
+	}
+	switch c.Status {
+	case CustomerActive, CustomerSuspended, CustomerClosed:
+	default:
+		return false
+	}
+	return true
+}
+
+// CurrencyBalance is the total balance a customer holds in one currency.
+type CurrencyBalance struct {
+	Currency string          `json:"currency"`
+	Balance  decimal.Decimal `json:"balance"`
+	Accounts int             `json:"accounts"`
+}
+
+type Beneficiary struct {
+	CustomerID uuid.UUID `json:"customer_id"`
+	AccountID  uuid.UUID `json:"account_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
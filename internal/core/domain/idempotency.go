@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// IdempotencyTimeout is how long the key of a request that is still being
+// processed stays reserved. Keys of requests whose instance stopped before
+// answering them are freed after it.
+const IdempotencyTimeout = time.Minute
+
+// IdempotentResponse is kept under the Idempotency-Key of a POST request, so
+// that repeating the request gets its response again instead of processing
+// it twice.
+type IdempotentResponse struct {
+	// Key is the key the client chose, prefixed with the caller.
+	Key string
+	// Fingerprint is the hash of the method, URL and body of the request,
+	// which repeats must match.
+	Fingerprint []byte
+	// Done is false while the request is being processed, when there is no
+	// response to replay yet.
+	Done      bool
+	Status    int
+	Header    map[string][]string
+	Body      []byte
+	ExpiresAt time.Time
+}
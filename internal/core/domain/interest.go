@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// DayCountConvention decides which fraction of the annual rate a single
+// calendar day is worth.
+type DayCountConvention string
+
+const (
+	DayCountActual365    DayCountConvention = "ACT/365"
+	DayCountActual360    DayCountConvention = "ACT/360"
+	DayCountActualActual DayCountConvention = "ACT/ACT"
+	DayCount30360        DayCountConvention = "30/360"
+)
+
+// Compounding decides whether interest that has been accrued but not yet
+// posted earns interest itself.
+type Compounding string
+
+const (
+	CompoundingSimple Compounding = "simple"
+	CompoundingDaily  Compounding = "daily"
+)
+
+// PostingFrequency decides how often accrued interest is paid into the account.
+type PostingFrequency string
+
+const (
+	PostingDaily     PostingFrequency = "daily"
+	PostingMonthly   PostingFrequency = "monthly"
+	PostingQuarterly PostingFrequency = "quarterly"
+	PostingAnnually  PostingFrequency = "annually"
+)
+
+type InterestConfig struct {
+	AccountID        uuid.UUID          `json:"account_id"`
+	AnnualRate       decimal.Decimal    `json:"annual_rate"`
+	DayCount         DayCountConvention `json:"day_count"`
+	Compounding      Compounding        `json:"compounding"`
+	PostingFrequency PostingFrequency   `json:"posting_frequency"`
+	Enabled          bool               `json:"enabled"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+type InterestAccrual struct {
+	ID          uuid.UUID       `json:"id"`
+	AccountID   uuid.UUID       `json:"account_id"`
+	AccrualDate time.Time       `json:"accrual_date"`
+	Balance     decimal.Decimal `json:"balance"`
+	AnnualRate  decimal.Decimal `json:"annual_rate"`
+	Amount      decimal.Decimal `json:"amount"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type InterestPosting struct {
+	ID            uuid.UUID       `json:"id"`
+	AccountID     uuid.UUID       `json:"account_id"`
+	PostingDate   time.Time       `json:"posting_date"`
+	AccruedAmount decimal.Decimal `json:"accrued_amount"`
+	PostedAmount  decimal.Decimal `json:"posted_amount"`
+	TransferID    *uuid.UUID      `json:"transfer_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// InterestRun summarises one execution of the daily interest job.
+type InterestRun struct {
+	Date     time.Time `json:"date"`
+	Accrued  int       `json:"accrued"`
+	Posted   int       `json:"posted"`
+	Failures []string  `json:"failures"`
+}
+
+// dayCount returns the days the given day counts for and the days in the year.
+func (c DayCountConvention) dayCount(day time.Time) (days, basis int64) {
+	switch c {
+	case DayCountActual360:
+		return 1, 360
+	case DayCountActualActual:
+		if isLeapYear(day.Year()) {
+			return 1, 366
+		}
+		return 1, 365
+	case DayCount30360:
+		return days30360(day, day.AddDate(0, 0, 1)), 360
+	default:
+		return 1, 365
+	}
+}
+
+// DailyAccrual returns the interest earned by base over the given day.
+// The result is not rounded to the currency; rounding happens once, when
+// interest is posted.
+func (c InterestConfig) DailyAccrual(base decimal.Decimal, day time.Time) decimal.Decimal {
+	if !base.IsPositive() {
+		return decimal.Zero
+	}
+
+	days, basis := c.DayCount.dayCount(day)
+	return base.Mul(c.AnnualRate).Mul(decimal.NewFromInt(days)).Div(decimal.NewFromInt(basis))
+}
+
+// PostingDue reports whether the given day closes a posting period.
+func (c InterestConfig) PostingDue(day time.Time) bool {
+	lastOfMonth := day.AddDate(0, 0, 1).Day() == 1
+
+	switch c.PostingFrequency {
+	case PostingDaily:
+		return true
+	case PostingMonthly:
+		return lastOfMonth
+	case PostingQuarterly:
+		return lastOfMonth && day.Month()%3 == 0
+	case PostingAnnually:
+		return lastOfMonth && day.Month() == time.December
+	default:
+		return false
+	}
+}
+
+func (c InterestConfig) Valid() bool {
+	switch c.DayCount {
+	case DayCountActual365, DayCountActual360, DayCountActualActual, DayCount30360:
+	default:
+		return false
+	}
+	switch c.Compounding {
+	case CompoundingSimple, CompoundingDaily:
+	default:
+		return false
+	}
+	switch c.PostingFrequency {
+	case PostingDaily, PostingMonthly, PostingQuarterly, PostingAnnually:
+	default:
+		return false
+	}
+	return !c.AnnualRate.IsNegative()
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// days30360 counts the days between start and end using the 30/360 bond basis.
+func days30360(start, end time.Time) int64 {
+	d1, d2 := start.Day(), end.Day()
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 && d1 == 30 {
+		d2 = 30
+	}
+
+	return int64(360*(end.Year()-start.Year()) + 30*(int(end.Month())-int(start.Month())) + (d2 - d1))
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func Test_DayCount(t *testing.T) {
+	testCases := []struct {
+		convention DayCountConvention
+		day        time.Time
+		days       int64
+		basis      int64
+	}{
+		{DayCountActual365, date(2024, time.March, 1), 1, 365},
+		{DayCountActual360, date(2024, time.March, 1), 1, 360},
+		{DayCountActualActual, date(2024, time.March, 1), 1, 366},
+		{DayCountActualActual, date(2023, time.March, 1), 1, 365},
+		{DayCount30360, date(2023, time.March, 30), 0, 360},
+		{DayCount30360, date(2023, time.March, 31), 1, 360},
+		{DayCount30360, date(2023, time.February, 28), 3, 360},
+		{DayCount30360, date(2024, time.February, 29), 2, 360},
+	}
+
+	for _, tt := range testCases {
+		days, basis := tt.convention.dayCount(tt.day)
+		if days != tt.days || basis != tt.basis {
+			t.Errorf("%s on %s: expected %d/%d but got %d/%d", tt.convention, tt.day.Format(time.DateOnly), tt.days, tt.basis, days, basis)
+		}
+	}
+}
+
+func Test_DayCount30360SumsToMonth(t *testing.T) {
+	for _, month := range []time.Month{time.January, time.February, time.April} {
+		var total int64
+		for day := date(2023, month, 1); day.Month() == month; day = day.AddDate(0, 0, 1) {
+			days, _ := DayCount30360.dayCount(day)
+			total += days
+		}
+
+		if total != 30 {
+			t.Errorf("%s: expected 30 days of interest but got %d", month, total)
+		}
+	}
+}
+
+func Test_DailyAccrual(t *testing.T) {
+	cfg := InterestConfig{
+		AnnualRate: decimal.RequireFromString("0.0365"),
+		DayCount:   DayCountActual365,
+	}
+
+	result := cfg.DailyAccrual(decimal.NewFromInt(10000), date(2023, time.June, 1))
+	if !result.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("expected accrual of 1 but got %v", result)
+	}
+
+	result = cfg.DailyAccrual(decimal.NewFromInt(-10000), date(2023, time.June, 1))
+	if !result.IsZero() {
+		t.Errorf("expected no accrual on a negative balance but got %v", result)
+	}
+}
+
+func Test_PostingDue(t *testing.T) {
+	testCases := []struct {
+		frequency PostingFrequency
+		day       time.Time
+		expected  bool
+	}{
+		{PostingDaily, date(2023, time.June, 14), true},
+		{PostingMonthly, date(2023, time.June, 14), false},
+		{PostingMonthly, date(2023, time.June, 30), true},
+		{PostingMonthly, date(2024, time.February, 29), true},
+		{PostingQuarterly, date(2023, time.May, 31), false},
+		{PostingQuarterly, date(2023, time.June, 30), true},
+		{PostingAnnually, date(2023, time.June, 30), false},
+		{PostingAnnually, date(2023, time.December, 31), true},
+	}
+
+	for _, tt := range testCases {
+		cfg := InterestConfig{PostingFrequency: tt.frequency}
+		if result := cfg.PostingDue(tt.day); result != tt.expected {
+			t.Errorf("%s on %s: expected %v but got %v", tt.frequency, tt.day.Format(time.DateOnly), tt.expected, result)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// EntryKind tells why an account balance changed.
+type EntryKind string
+
+const (
+	EntryOpening    EntryKind = "opening"
+	EntryTransfer   EntryKind = "transfer"
+	EntryAdjustment EntryKind = "adjustment"
+	EntryConversion EntryKind = "conversion"
+)
+
+// Entry is a single change to the balance of an account pocket. The balance
+// of a pocket is always the sum of its entries.
+type Entry struct {
+	ID             uuid.UUID       `json:"id"`
+	AccountID      uuid.UUID       `json:"account_id"`
+	Currency       string          `json:"currency"`
+	TransferID     *uuid.UUID      `json:"transfer_id,omitempty"`
+	CounterpartyID *uuid.UUID      `json:"counterparty_id,omitempty"`
+	Kind           EntryKind       `json:"kind"`
+	Amount         decimal.Decimal `json:"amount"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type StatementLine struct {
+	Entry
+	Balance decimal.Decimal `json:"balance"`
+}
+
+type Statement struct {
+	Account        Account         `json:"account"`
+	Currency       string          `json:"currency"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance decimal.Decimal `json:"opening_balance"`
+	ClosingBalance decimal.Decimal `json:"closing_balance"`
+	Lines          []StatementLine `json:"lines"`
+}
+
+// Balance is the balance of an account at a point in time.
+type Balance struct {
+	AccountID uuid.UUID       `json:"account_id"`
+	Currency  string          `json:"currency"`
+	Balance   decimal.Decimal `json:"balance"`
+	AsOf      time.Time       `json:"as_of"`
+}
@@ -7,28 +7,79 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// Account is a wallet holding a balance in one or more currencies. Balance
+// and Currency describe its base pocket, the one used when a currency is not
+// given explicitly. Version goes up by one whenever the account changes,
+// including its balance. Frozen accounts neither send nor receive transfers.
 type Account struct {
-	ID        uuid.UUID       `json:"id"`
-	Balance   decimal.Decimal `json:"balance"`
+	ID         uuid.UUID       `json:"id"`
+	CustomerID *uuid.UUID      `json:"customer_id"`
+	Balance    decimal.Decimal `json:"balance"`
+	Currency   string          `json:"currency"`
+	Frozen     bool            `json:"frozen"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Version    int             `json:"version"`
+	Pockets    []Pocket        `json:"pockets,omitempty"`
+}
+
+// Pocket returns the pocket of the account holding the given currency.
+func (a Account) Pocket(currency string) (Pocket, bool) {
+	for _, pocket := range a.Pockets {
+		if pocket.Currency == currency {
+			return pocket, true
+		}
+	}
+	return Pocket{}, false
+}
+
+// Pocket is the balance an account holds in a single currency.
+type Pocket struct {
 	Currency  string          `json:"currency"`
+	Balance   decimal.Decimal `json:"balance"`
 	CreatedAt time.Time       `json:"created_at"`
 }
 
+// Conversion moves value between two pockets of the same account. Amount is
+// taken out of the pocket in its currency and Converted is added to the
+// pocket in its currency.
+type Conversion struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Amount    Money     `json:"amount"`
+	Converted Money     `json:"converted"`
+	From      Pocket    `json:"from"`
+	To        Pocket    `json:"to"`
+}
+
+// Transfer records money moving between two accounts. Amount is what left
+// the source account and Credited what reached the target account, which
+// differ when the transfer crossed currencies.
 type Transfer struct {
-	ID              uuid.UUID       `json:"id"`
-	SourceAccountID uuid.UUID       `json:"source_account_id"`
-	TargetAccountID uuid.UUID       `json:"target_account_id"`
-	Amount          decimal.Decimal `json:"amount"`
-	Currency        string          `json:"currency"`
+	ID              uuid.UUID `json:"id"`
+	SourceAccountID uuid.UUID `json:"source_account_id"`
+	TargetAccountID uuid.UUID `json:"target_account_id"`
+	Amount          Money     `json:"amount"`
+	Credited        Money     `json:"credited"`
+	CreatedAt       time.Time `json:"created_at"`
 }
 
+// TransferTxParams describes a transfer between two account pockets. Amount
+// is taken out of the source pocket of its currency and Credit, the same
+// value in TargetCurrency, is added to the target pocket. Credit is worked
+// out by the transfer service.
 type TransferTxParams struct {
-	SourceAccountID  uuid.UUID       `json:"source_account_id"`
-	TargetAccountID  uuid.UUID       `json:"target_account_id"`
-	SourceBalance    decimal.Decimal `json:"source_balance"`
-	AmountToTransfer decimal.Decimal `json:"amount_to_transfer"`
-	SourceCurrency   string          `json:"source_currency"`
-	TargetCurrency   string          `json:"target_currency"`
+	// TransferID, when set, is the id the transfer is recorded with, so that
+	// a transfer retried after a failure cannot be made twice.
+	TransferID      uuid.UUID `json:"transfer_id"`
+	SourceAccountID uuid.UUID `json:"source_account_id"`
+	TargetAccountID uuid.UUID `json:"target_account_id"`
+	SourceBalance   Money     `json:"source_balance"`
+	Amount          Money     `json:"amount"`
+	TargetCurrency  string    `json:"target_currency"`
+	Credit          Money     `json:"credit"`
+	// Audit, when set, is recorded in the audit log as the creation of the
+	// transfer, with its resource and snapshot, in the same transaction, so
+	// that neither is kept without the other.
+	Audit *AuditEntry `json:"-"`
 }
 
 type TransferTxResult struct {
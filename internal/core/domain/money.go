@@ -0,0 +1,223 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+	ErrInvalidRatios    = errors.New("allocation needs at least one positive ratio and no negative ones")
+)
+
+// Money is an amount in a given currency. Arithmetic between two Money
+// values fails instead of silently mixing currencies.
+type Money struct {
+	Amount   decimal.Decimal
+	Currency string
+}
+
+func NewMoney(amount decimal.Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return Money{Amount: m.Amount.Add(other.Amount), Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	return m.Add(other.Neg())
+}
+
+func (m Money) Neg() Money {
+	return Money{Amount: m.Amount.Neg(), Currency: m.Currency}
+}
+
+// Mul multiplies the amount by a factor, such as an interest rate, without
+// rounding the result.
+func (m Money) Mul(factor decimal.Decimal) Money {
+	return Money{Amount: m.Amount.Mul(factor), Currency: m.Currency}
+}
+
+// Cmp compares two amounts in the same currency, returning -1, 0 or +1.
+func (m Money) Cmp(other Money) (int, error) {
+	if m.Currency != other.Currency {
+		return 0, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return m.Amount.Cmp(other.Amount), nil
+}
+
+func (m Money) LessThan(other Money) (bool, error) {
+	cmp, err := m.Cmp(other)
+	return cmp < 0, err
+}
+
+// Equal reports whether both the amount and the currency are the same.
+func (m Money) Equal(other Money) bool {
+	return m.Currency == other.Currency && m.Amount.Equal(other.Amount)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount.IsPositive()
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount.IsNegative()
+}
+
+// Allocate splits the amount in proportion to the given ratios. The shares
+// are whole minor units of the currency and always add up to the original
+// amount; the units left over from rounding down go to the first shares.
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	c, err := currency.Default.Get(m.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Validate(m.Amount); err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, ErrInvalidRatios
+		}
+		total += ratio
+	}
+	if total == 0 {
+		return nil, ErrInvalidRatios
+	}
+
+	units := m.Amount.Abs().Shift(c.MinorUnit)
+	remainder := units
+	shares := make([]decimal.Decimal, len(ratios))
+	for i, ratio := range ratios {
+		shares[i] = units.Mul(decimal.NewFromInt(int64(ratio))).Div(decimal.NewFromInt(int64(total))).Floor()
+		remainder = remainder.Sub(shares[i])
+	}
+	for i := 0; remainder.IsPositive(); i = (i + 1) % len(shares) {
+		if ratios[i] == 0 {
+			continue
+		}
+		shares[i] = shares[i].Add(decimal.NewFromInt(1))
+		remainder = remainder.Sub(decimal.NewFromInt(1))
+	}
+
+	allocated := make([]Money, len(shares))
+	for i, share := range shares {
+		amount := share.Shift(-c.MinorUnit)
+		if m.Amount.IsNegative() {
+			amount = amount.Neg()
+		}
+		allocated[i] = Money{Amount: amount, Currency: m.Currency}
+	}
+
+	return allocated, nil
+}
+
+// Split divides the amount into n shares that differ by at most one minor
+// unit.
+func (m Money) Split(n int) ([]Money, error) {
+	ratios := make([]int, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// String formats the amount with the number of decimals of its currency,
+// e.g. "10.00 EUR".
+func (m Money) String() string {
+	return m.AmountString() + " " + m.Currency
+}
+
+// AmountString formats the amount alone with the number of decimals of its
+// currency, e.g. "10.00".
+func (m Money) AmountString() string {
+	c, err := currency.Default.Get(m.Currency)
+	if err != nil {
+		return m.Amount.String()
+	}
+	return m.Amount.StringFixed(c.MinorUnit)
+}
+
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as a string with the decimals of its
+// currency, as in {"amount":"10.00","currency":"EUR"}.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.AmountString(), m.Currency})
+}
+
+// UnmarshalJSON accepts the amount either as a string or as a number.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	var amount decimal.Decimal
+	if len(v.Amount) > 0 {
+		if err := amount.UnmarshalJSON(v.Amount); err != nil {
+			return err
+		}
+	}
+
+	m.Amount, m.Currency = amount, v.Currency
+	return nil
+}
+
+// Value stores Money as a row of amount and currency, "(10.00,EUR)", which
+// Postgres accepts wherever a record of the two is expected.
+func (m Money) Value() (driver.Value, error) {
+	return fmt.Sprintf("(%s,%s)", m.Amount.String(), m.Currency), nil
+}
+
+// Scan reads Money from a row of amount and currency, as returned by
+// SELECT (amount, currency).
+func (m *Money) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return fmt.Errorf("cannot scan %q into Money", s)
+	}
+	amount, code, found := strings.Cut(s[1:len(s)-1], ",")
+	if !found {
+		return fmt.Errorf("cannot scan %q into Money", s)
+	}
+
+	value, err := decimal.NewFromString(amount)
+	if err != nil {
+		return err
+	}
+
+	m.Amount, m.Currency = value, strings.Trim(code, `"`)
+	return nil
+}
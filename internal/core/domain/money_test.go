@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func eur(amount string) Money {
+	return NewMoney(decimal.RequireFromString(amount), "EUR")
+}
+
+func Test_MoneyArithmetic(t *testing.T) {
+	sum, err := eur("10.50").Add(eur("0.25"))
+	if err != nil || !sum.Equal(eur("10.75")) {
+		t.Errorf("expected 10.75 EUR but got %s (%v)", sum, err)
+	}
+
+	difference, err := eur("10.50").Sub(eur("11"))
+	if err != nil || !difference.Equal(eur("-0.50")) {
+		t.Errorf("expected -0.50 EUR but got %s (%v)", difference, err)
+	}
+
+	usd := NewMoney(decimal.NewFromInt(1), "USD")
+
+	if _, err := eur("1").Add(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected currency mismatch adding EUR and USD but got %v", err)
+	}
+
+	if _, err := eur("1").LessThan(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected currency mismatch comparing EUR and USD but got %v", err)
+	}
+}
+
+func Test_MoneyAllocate(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		ratios   []int
+		expected []string
+	}{
+		{eur("100"), []int{1, 1, 1}, []string{"33.34", "33.33", "33.33"}},
+		{eur("0.05"), []int{3, 7}, []string{"0.02", "0.03"}},
+		{eur("-10"), []int{1, 2}, []string{"-3.34", "-6.66"}},
+		{NewMoney(decimal.NewFromInt(100), "JPY"), []int{1, 1, 1}, []string{"34", "33", "33"}},
+		{NewMoney(decimal.RequireFromString("1.000"), "KWD"), []int{1, 0, 2}, []string{"0.334", "0", "0.666"}},
+	}
+
+	for _, tt := range testCases {
+		shares, err := tt.money.Allocate(tt.ratios...)
+		if err != nil {
+			t.Errorf("allocating %s: %s", tt.money, err)
+			continue
+		}
+
+		total := NewMoney(decimal.Zero, tt.money.Currency)
+		for i, share := range shares {
+			if !share.Amount.Equal(decimal.RequireFromString(tt.expected[i])) {
+				t.Errorf("allocating %s by %v: share %d expected %s but got %s", tt.money, tt.ratios, i, tt.expected[i], share)
+			}
+			total, _ = total.Add(share)
+		}
+
+		if !total.Equal(tt.money) {
+			t.Errorf("allocating %s: shares add up to %s", tt.money, total)
+		}
+	}
+
+	if _, err := eur("10").Allocate(0, 0); !errors.Is(err, ErrInvalidRatios) {
+		t.Errorf("expected invalid ratios error but got %v", err)
+	}
+}
+
+func Test_MoneySplit(t *testing.T) {
+	shares, err := eur("10").Split(3)
+	if err != nil || len(shares) != 3 || !shares[0].Equal(eur("3.34")) || !shares[2].Equal(eur("3.33")) {
+		t.Errorf("unexpected split of 10 EUR in 3: %v (%v)", shares, err)
+	}
+}
+
+func Test_MoneyJSON(t *testing.T) {
+	data, err := json.Marshal(eur("10"))
+	if err != nil || string(data) != `{"amount":"10.00","currency":"EUR"}` {
+		t.Errorf("unexpected JSON %s (%v)", data, err)
+	}
+
+	data, _ = json.Marshal(NewMoney(decimal.RequireFromString("1.5"), "KWD"))
+	if string(data) != `{"amount":"1.500","currency":"KWD"}` {
+		t.Errorf("unexpected JSON %s", data)
+	}
+
+	for _, input := range []string{`{"amount":"10.25","currency":"EUR"}`, `{"amount":10.25,"currency":"EUR"}`} {
+		var m Money
+		if err := json.Unmarshal([]byte(input), &m); err != nil || !m.Equal(eur("10.25")) {
+			t.Errorf("unmarshalling %s: got %s (%v)", input, m, err)
+		}
+	}
+}
+
+func Test_MoneySQL(t *testing.T) {
+	value, err := eur("10.5").Value()
+	if err != nil || value != "(10.5,EUR)" {
+		t.Errorf("unexpected SQL value %v (%v)", value, err)
+	}
+
+	var m Money
+	if err := m.Scan([]byte("(10.5,EUR)")); err != nil || !m.Equal(eur("10.5")) {
+		t.Errorf("unexpected scanned money %s (%v)", m, err)
+	}
+
+	if err := m.Scan("10.5"); err == nil {
+		t.Errorf("expected an error scanning a bare amount")
+	}
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit lets Limit requests through per Period. Its token bucket holds up
+// to Limit tokens and refills evenly over the period, so bursts of up to
+// Limit requests are let through after a quiet spell. The zero RateLimit
+// lets everything through.
+type RateLimit struct {
+	Limit  int
+	Period time.Duration
+}
+
+// RouteLimit is how many requests a route lets through per caller, an API
+// client or a user, and per source account. Limits that are not enabled are
+// not enforced. Routes sharing a name share their buckets, over REST and gRPC
+// alike.
+type RouteLimit struct {
+	Name    string
+	Client  RateLimit
+	Account RateLimit
+}
+
+// ParseRateLimit reads a limit written as requests/period, such as "10/1m"
+// or "100/1h". "off" is the zero RateLimit.
+func ParseRateLimit(s string) (RateLimit, error) {
+	if s == "off" {
+		return RateLimit{}, nil
+	}
+
+	count, period, ok := strings.Cut(s, "/")
+	limit, err := strconv.Atoi(count)
+	if !ok || err != nil || limit <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q must be a positive number of requests per period, as in 10/1m", s)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q must be a positive number of requests per period, as in 10/1m", s)
+	}
+	return RateLimit{limit, d}, nil
+}
+
+// String writes the limit as ParseRateLimit reads it.
+func (l RateLimit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Limit, l.Period)
+}
+
+func (l RateLimit) Enabled() bool {
+	return l.Limit > 0 && l.Period > 0
+}
+
+// Policy describes the limit as in the RateLimit-Policy header: the limit
+// and the window in seconds.
+func (l RateLimit) Policy() string {
+	return fmt.Sprintf("%d;w=%d", l.Limit, int64(math.Ceil(l.Period.Seconds())))
+}
+
+// RateBucket is the state of a token bucket. The zero RateBucket is full.
+type RateBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// RateDecision is whether a request was let through and what is left of its
+// bucket.
+type RateDecision struct {
+	Allowed   bool
+	Limit     RateLimit
+	Remaining int
+	// RetryAfter is how long until the next request would be let through,
+	// zero when this one was.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// Take refills the bucket for the time since it was last updated and takes a
+// token from it, if it holds one.
+func (b *RateBucket) Take(limit RateLimit, now time.Time) RateDecision {
+	capacity := float64(limit.Limit)
+	perToken := limit.Period / time.Duration(limit.Limit)
+
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = capacity
+	} else if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens += float64(elapsed) / float64(perToken)
+	}
+	// The limit may have been lowered since the bucket was last used.
+	b.Tokens = math.Min(capacity, b.Tokens)
+	b.UpdatedAt = now
+
+	decision := RateDecision{Limit: limit}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = time.Duration((1 - b.Tokens) * float64(perToken))
+	}
+	decision.Remaining = int(b.Tokens)
+	decision.Reset = time.Duration((capacity - b.Tokens) * float64(perToken))
+	return decision
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_ParseRateLimit(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected RateLimit
+		valid    bool
+	}{
+		{"10/1m", RateLimit{10, time.Minute}, true},
+		{"600/1h30m", RateLimit{600, 90 * time.Minute}, true},
+		{"off", RateLimit{}, true},
+		{"10", RateLimit{}, false},
+		{"0/1m", RateLimit{}, false},
+		{"10/minute", RateLimit{}, false},
+		{"10/-1s", RateLimit{}, false},
+	}
+	for _, tt := range tests {
+		limit, err := ParseRateLimit(tt.value)
+		if (err == nil) != tt.valid || limit != tt.expected {
+			t.Errorf("%s: expected %v (valid %t) but got %v (%v)", tt.value, tt.expected, tt.valid, limit, err)
+		}
+	}
+}
+
+func Test_RateBucket(t *testing.T) {
+	limit := RateLimit{Limit: 3, Period: 3 * time.Second}
+	start := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	var bucket RateBucket
+	for i := 2; i >= 0; i-- {
+		if d := bucket.Take(limit, start); !d.Allowed || d.Remaining != i {
+			t.Fatalf("expected a burst of 3 to be let through but got %+v", d)
+		}
+	}
+
+	d := bucket.Take(limit, start.Add(500*time.Millisecond))
+	if d.Allowed || d.RetryAfter != 500*time.Millisecond || d.Reset != 2500*time.Millisecond {
+		t.Errorf("expected the fourth request to wait half a second but got %+v", d)
+	}
+
+	// A token comes back every second.
+	if d := bucket.Take(limit, start.Add(time.Second)); !d.Allowed || d.Remaining != 0 {
+		t.Errorf("expected a token after a second but got %+v", d)
+	}
+	if d := bucket.Take(limit, start.Add(time.Hour)); !d.Allowed || d.Remaining != 2 {
+		t.Errorf("expected the bucket to refill no further than full but got %+v", d)
+	}
+
+	// Lowering the limit empties the bucket down to it.
+	if d := bucket.Take(RateLimit{Limit: 1, Period: time.Minute}, start.Add(time.Hour)); !d.Allowed || d.Remaining != 0 {
+		t.Errorf("expected the lowered limit to hold but got %+v", d)
+	}
+
+	if policy := (RateLimit{Limit: 10, Period: 90 * time.Second}).Policy(); policy != "10;w=90" {
+		t.Errorf("unexpected policy %q", policy)
+	}
+}
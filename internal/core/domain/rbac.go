@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/petrostrak/agile-transfer/internal/validator"
+)
+
+// Permission allows one kind of operation. Back-office users are granted
+// permissions through their roles; API keys through the scope each
+// permission belongs to.
+type Permission string
+
+const (
+	PermissionAccountsView     Permission = "accounts:view"
+	PermissionAccountsManage   Permission = "accounts:manage"
+	PermissionBalancesAdjust   Permission = "balances:adjust"
+	PermissionAccountsFreeze   Permission = "accounts:freeze"
+	PermissionCustomersView    Permission = "customers:view"
+	PermissionCustomersManage  Permission = "customers:manage"
+	PermissionTransfersView    Permission = "transfers:view"
+	PermissionTransfersCreate  Permission = "transfers:create"
+	PermissionTransfersReverse Permission = "transfers:reverse"
+	PermissionInterestRun      Permission = "interest:run"
+	PermissionAPIKeysManage    Permission = "api_keys:manage"
+	PermissionRolesView        Permission = "roles:view"
+	PermissionRolesManage      Permission = "roles:manage"
+	PermissionApprovalsView    Permission = "approvals:view"
+	PermissionApprovalsDecide  Permission = "approvals:decide"
+	PermissionAuditView        Permission = "audit:view"
+)
+
+// PermissionInfo describes a permission and names the API key scope that
+// grants it.
+type PermissionInfo struct {
+	Name        Permission `json:"name"`
+	Description string     `json:"description"`
+	Scope       Scope      `json:"scope"`
+}
+
+// Permissions are all the permissions, in the order they are listed.
+var Permissions = []PermissionInfo{
+	{PermissionAccountsView, "Read accounts, their pockets, interest, statements and balances.", ScopeAccountsRead},
+	{PermissionAccountsManage, "Open and delete accounts and pockets, convert between pockets and set interest.", ScopeAccountsWrite},
+	{PermissionBalancesAdjust, "Change the balance, currency or customer of an account.", ScopeAccountsWrite},
+	{PermissionAccountsFreeze, "Freeze and unfreeze accounts, stopping their transfers.", ScopeAccountsWrite},
+	{PermissionCustomersView, "Read customers, their accounts and beneficiaries.", ScopeAccountsRead},
+	{PermissionCustomersManage, "Create, change, suspend and delete customers and their beneficiaries.", ScopeAccountsWrite},
+	{PermissionTransfersView, "Read the transfers.", ScopeAccountsRead},
+	{PermissionTransfersCreate, "Make transfers.", ScopeTransfersCreate},
+	{PermissionTransfersReverse, "Send the money of a transfer back to its source account.", ScopeAdmin},
+	{PermissionInterestRun, "Run the interest job.", ScopeAdmin},
+	{PermissionAPIKeysManage, "Issue, rotate and revoke API keys and signing secrets.", ScopeAdmin},
+	{PermissionRolesView, "Read the roles and the permissions matrix.", ScopeAdmin},
+	{PermissionRolesManage, "Create, change and delete roles.", ScopeAdmin},
+	{PermissionApprovalsView, "Read the actions waiting for approval and those decided.", ScopeAccountsRead},
+	{PermissionApprovalsDecide, "Approve or reject the actions of others that the user could carry out.", ScopeAdmin},
+	{PermissionAuditView, "Read and verify the audit log.", ScopeAdmin},
+}
+
+// Scope is the API key scope that grants the permission.
+func (p Permission) Scope() Scope {
+	for _, info := range Permissions {
+		if info.Name == p {
+			return info.Scope
+		}
+	}
+	return ScopeAdmin
+}
+
+func (p Permission) Valid() bool {
+	for _, info := range Permissions {
+		if info.Name == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Role is a set of permissions given to back-office users. Users hold the
+// roles named in the roles claim of their token.
+type Role struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Grants reports whether the role has permission.
+func (r *Role) Grants(permission Permission) bool {
+	for _, p := range r.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleAdmin is the role whose users may act on every account, not only on
+// those of their own customer.
+const RoleAdmin = "admin"
+
+// DefaultRoles are the roles the service starts with when there are none.
+func DefaultRoles() []Role {
+	view := []Permission{PermissionAccountsView, PermissionCustomersView, PermissionTransfersView}
+
+	all := make([]Permission, 0, len(Permissions))
+	for _, info := range Permissions {
+		all = append(all, info.Name)
+	}
+
+	return []Role{
+		{Name: "support", Description: "Customer support: can look but not touch.", Permissions: view},
+		{Name: "finance", Description: "Finance: adjusts balances, makes and reverses transfers, freezes accounts, runs interest and approves the actions of others.", Permissions: append(view[:len(view):len(view)], PermissionBalancesAdjust, PermissionAccountsFreeze, PermissionTransfersCreate, PermissionTransfersReverse, PermissionInterestRun, PermissionApprovalsView, PermissionApprovalsDecide)},
+		{Name: "engineer", Description: "Engineering: manages API keys and reads the roles.", Permissions: append(view[:len(view):len(view)], PermissionAPIKeysManage, PermissionRolesView)},
+		{Name: RoleAdmin, Description: "Administrators: every permission.", Permissions: all},
+	}
+}
+
+// RoleNameRX is the form of role names, which appear in tokens and URLs.
+var RoleNameRX = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+func ValidateRole(v *validator.Validator, r *Role) {
+	v.Check(r.Name != "", "name", "must be provided")
+	v.Check(len(r.Name) <= 50, "name", "must not be more than 50 bytes long")
+	v.Check(r.Name == "" || validator.Matches(r.Name, RoleNameRX), "name", "must be lowercase letters, digits, - and _, starting with a letter")
+	v.Check(len(r.Description) <= 500, "description", "must not be more than 500 bytes long")
+	v.Check(validator.Unique(r.Permissions), "permissions", "must not contain duplicate permissions")
+	for _, p := range r.Permissions {
+		if !p.Valid() {
+			v.AddError("permissions", "must only contain known permissions")
+			break
+		}
+	}
+}
+
+// MatrixRow is a row of the permissions matrix: a permission, the scope that
+// grants it to API keys and the roles that grant it to users.
+type MatrixRow struct {
+	PermissionInfo
+	Roles []string `json:"roles"`
+}
+
+// Matrix lays out which of roles grant each permission.
+func Matrix(roles []Role) []MatrixRow {
+	rows := make([]MatrixRow, len(Permissions))
+	for i, info := range Permissions {
+		rows[i] = MatrixRow{PermissionInfo: info, Roles: []string{}}
+		for j := range roles {
+			if roles[j].Grants(info.Name) {
+				rows[i].Roles = append(rows[i].Roles, roles[j].Name)
+			}
+		}
+	}
+	return rows
+}
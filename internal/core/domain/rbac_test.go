@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Matrix(t *testing.T) {
+	roles := []Role{
+		{Name: "finance", Permissions: []Permission{PermissionAccountsView, PermissionBalancesAdjust}},
+		{Name: "support", Permissions: []Permission{PermissionAccountsView}},
+	}
+
+	rows := Matrix(roles)
+	if len(rows) != len(Permissions) {
+		t.Fatalf("expected a row for each of the %d permissions but got %d", len(Permissions), len(rows))
+	}
+
+	for _, row := range rows {
+		var expected []string
+		switch row.Name {
+		case PermissionAccountsView:
+			expected = []string{"finance", "support"}
+		case PermissionBalancesAdjust:
+			expected = []string{"finance"}
+		default:
+			expected = []string{}
+		}
+		if !reflect.DeepEqual(row.Roles, expected) {
+			t.Errorf("expected %s to be granted by %v but got %v", row.Name, expected, row.Roles)
+		}
+	}
+}
+
+func Test_PermissionScope(t *testing.T) {
+	if PermissionAccountsView.Scope() != ScopeAccountsRead || PermissionBalancesAdjust.Scope() != ScopeAccountsWrite || PermissionRolesManage.Scope() != ScopeAdmin {
+		t.Error("expected permissions to map to the scopes that grant them")
+	}
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+)
+
+// The headers a signed request carries. The signature is "v1=" followed by
+// the hex HMAC-SHA256 of the string Signature describes.
+const (
+	SignatureHeader          = "X-Signature"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureNonceHeader     = "X-Signature-Nonce"
+)
+
+// SignatureTolerance is how far the timestamp of a signed request may be
+// from the time it is received. Nonces are remembered for as long.
+const SignatureTolerance = 5 * time.Minute
+
+// SigningSecret is shared with a client that signs its requests. Unlike API
+// keys, the secret itself is kept, as checking a signature needs it; it is
+// shown to the client once, when it is issued.
+type SigningSecret struct {
+	ID        uuid.UUID  `json:"id"`
+	Client    string     `json:"client"`
+	Secret    string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// Active reports whether the secret may be used at the given time.
+func (s *SigningSecret) Active(at time.Time) bool {
+	if s.RevokedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || at.Before(*s.ExpiresAt)
+}
+
+func ValidateSigningSecret(v *validator.Validator, s *SigningSecret) {
+	v.Check(s.Client != "", "client", "must be provided")
+	v.Check(len(s.Client) <= 100, "client", "must not be more than 100 bytes long")
+}
+
+// Signature signs a request with secret: the HMAC-SHA256, in hex, of
+//
+//	v1
+//	<method>
+//	<path and query, as sent>
+//	<timestamp, in Unix seconds>
+//	<nonce>
+//	<SHA-256 of the body, in hex>
+//
+// joined by newlines.
+func Signature(secret, method, uri string, timestamp int64, nonce string, body []byte) string {
+	digest := sha256.Sum256(body)
+	message := strings.Join([]string{
+		"v1",
+		strings.ToUpper(method),
+		uri,
+		strconv.FormatInt(timestamp, 10),
+		nonce,
+		hex.EncodeToString(digest[:]),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+// The vectors were computed independently of this package, so that a
+// change to what is signed shows up here.
+func Test_Signature(t *testing.T) {
+	const secret = "ags_pMW0rLAnWHE3Xaj1xvWvrqvMN3G8d1CHPuYx0jTSVnM"
+
+	tests := []struct {
+		method, uri, nonce, body, expected string
+	}{
+		{
+			"POST", "/v1/transfer", "4f1c2a9e-6b3d-4c8a-9e7f-2d5b8a1c0e3f",
+			`{"source_account_id":"5b1f2a52-2f8e-4a0c-9d47-3c1a6a7a2f10","target_account_id":"0e2d6a1c-7f0b-4b8e-8c55-0f4b7d9e1a23","amount":"150.00"}`,
+			"ff1a01863bd103463b01d60f9879b1a24074d805c77df159f5808db58c50324f",
+		},
+		{
+			"get", "/v1/accounts?page=2&page_size=20", "n-1", "",
+			"dc6eacdd52062f370820ad4c8747f8a5c8ae7193b105d7a181d74d5dcbe77ee2",
+		},
+	}
+	for _, tt := range tests {
+		if signature := Signature(secret, tt.method, tt.uri, 1792400000, tt.nonce, []byte(tt.body)); signature != tt.expected {
+			t.Errorf("%s %s: expected %s but got %s", tt.method, tt.uri, tt.expected, signature)
+		}
+	}
+
+	if Signature(secret, "POST", "/v1/transfer", 1792400001, "n-1", nil) == Signature(secret, "POST", "/v1/transfer", 1792400000, "n-1", nil) {
+		t.Error("expected the timestamp to be signed")
+	}
+}
+
+func Test_SigningSecretActive(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		secret   SigningSecret
+		expected bool
+	}{
+		{"new", SigningSecret{}, true},
+		{"expiring", SigningSecret{ExpiresAt: &later}, true},
+		{"expired", SigningSecret{ExpiresAt: &now}, false},
+		{"revoked", SigningSecret{RevokedAt: &now}, false},
+	}
+	for _, tt := range tests {
+		if active := tt.secret.Active(now); active != tt.expected {
+			t.Errorf("%s: expected active %t but got %t", tt.name, tt.expected, active)
+		}
+	}
+}
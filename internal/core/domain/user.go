@@ -0,0 +1,57 @@
+package domain
+
+import "github.com/google/uuid"
+
+// User is a person signed in with a bearer token from the identity provider.
+// Back-office users hold roles, which grant them permissions; only those with
+// the admin role may act on every account. Users without roles are customers,
+// who can only see their own accounts and make transfers from them.
+type User struct {
+	Subject string
+	// CustomerID is the customer the subject belongs to, nil when it belongs
+	// to none.
+	CustomerID *uuid.UUID
+	// Roles are the names of the roles the user holds, of those in the roles
+	// claim of the token that exist.
+	Roles       []string
+	Permissions []Permission
+}
+
+// Staff reports whether the user is a back-office user.
+func (u *User) Staff() bool {
+	return len(u.Roles) > 0
+}
+
+// Admin reports whether the user holds the admin role.
+func (u *User) Admin() bool {
+	for _, role := range u.Roles {
+		if role == RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Owns reports whether the user may act on the account: admins may act on
+// every account, everyone else only on those of their own customer.
+func (u *User) Owns(account *Account) bool {
+	if u.Admin() {
+		return true
+	}
+	return u.CustomerID != nil && account.CustomerID != nil && *account.CustomerID == *u.CustomerID
+}
+
+// Can reports whether the user has permission. Back-office users have the
+// permissions of their roles, customers only viewing accounts and making
+// transfers.
+func (u *User) Can(permission Permission) bool {
+	if !u.Staff() {
+		return permission == PermissionAccountsView || permission == PermissionTransfersCreate
+	}
+	for _, p := range u.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
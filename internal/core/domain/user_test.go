@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func Test_UserOwns(t *testing.T) {
+	customer, other := uuid.New(), uuid.New()
+	account := &Account{CustomerID: &customer}
+
+	testCases := []struct {
+		name     string
+		user     User
+		account  *Account
+		expected bool
+	}{
+		{"holder", User{CustomerID: &customer}, account, true},
+		{"another customer", User{CustomerID: &other}, account, false},
+		{"no customer", User{}, account, false},
+		{"account without customer", User{CustomerID: &customer}, &Account{}, false},
+		{"staff", User{Roles: []string{"support"}}, account, false},
+		{"staff of the customer", User{CustomerID: &customer, Roles: []string{"finance"}}, account, true},
+		{"admin", User{Roles: []string{"support", RoleAdmin}}, account, true},
+	}
+
+	for _, tt := range testCases {
+		if owns := tt.user.Owns(tt.account); owns != tt.expected {
+			t.Errorf("%s: expected owns to be %t but got %t", tt.name, tt.expected, owns)
+		}
+	}
+}
+
+func Test_UserCan(t *testing.T) {
+	customer := User{CustomerID: &uuid.UUID{}}
+	if !customer.Can(PermissionAccountsView) || !customer.Can(PermissionTransfersCreate) || customer.Can(PermissionBalancesAdjust) || customer.Can(PermissionCustomersView) {
+		t.Error("expected customers to only view accounts and make transfers")
+	}
+
+	support := User{Roles: []string{"support"}, Permissions: []Permission{PermissionAccountsView, PermissionCustomersView}}
+	if !support.Can(PermissionCustomersView) {
+		t.Error("expected staff to have the permissions of their roles")
+	}
+	if support.Can(PermissionTransfersCreate) {
+		t.Error("expected staff not to have permissions their roles do not grant")
+	}
+}
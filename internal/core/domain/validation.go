@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
+	"github.com/petrostrak/agile-transfer/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+// ValidateCurrency checks that code names an enabled currency and, when it
+// does, that amount fits its minor unit. Amount errors are reported under
+// amountKey; pass an empty key when there is no amount to check.
+func ValidateCurrency(v *validator.Validator, key, code, amountKey string, amount decimal.Decimal) {
+	if code == "" {
+		v.AddError(key, "must be provided")
+		return
+	}
+
+	c, err := currency.Lookup(code)
+	switch {
+	case errors.Is(err, currency.ErrCurrencyDisabled):
+		v.AddError(key, "must be an enabled currency")
+		return
+	case err != nil:
+		v.AddError(key, "must be an ISO 4217 currency code")
+		return
+	}
+
+	if amountKey != "" && c.Validate(amount) != nil {
+		v.AddError(amountKey, "must not have more decimal places than the currency allows")
+	}
+}
+
+// ValidateAmount checks that an amount of money to move is positive.
+func ValidateAmount(v *validator.Validator, key string, amount decimal.Decimal) {
+	v.Check(amount.IsPositive(), key, "must be greater than zero")
+}
+
+// ValidateID checks that a referenced id was provided.
+func ValidateID(v *validator.Validator, key string, id uuid.UUID) {
+	v.Check(id != uuid.Nil, key, "must be provided")
+}
+
+func ValidateAccount(v *validator.Validator, acc *Account) {
+	v.Check(!acc.Balance.IsNegative(), "balance", "must not be negative")
+	ValidateCurrency(v, "currency", acc.Currency, "balance", acc.Balance)
+	if acc.CustomerID != nil {
+		ValidateID(v, "customer_id", *acc.CustomerID)
+	}
+}
+
+func ValidateCustomer(v *validator.Validator, c *Customer) {
+	v.Check(c.Name != "", "name", "must be provided")
+	v.Check(len(c.Name) <= 500, "name", "must not be more than 500 bytes long")
+	v.Check(c.Email != "", "email", "must be provided")
+	v.Check(c.Email == "" || validator.Matches(c.Email, validator.EmailRX), "email", "must be a valid email address")
+	v.Check(validator.In(c.Type, CustomerIndividual, CustomerBusiness), "type", "must be individual or business")
+	v.Check(validator.In(c.Status, CustomerActive, CustomerSuspended, CustomerClosed), "status", "must be active, suspended or closed")
+	if c.Subject != nil {
+		v.Check(*c.Subject != "", "subject", "must not be empty")
+		v.Check(len(*c.Subject) <= 255, "subject", "must not be more than 255 bytes long")
+	}
+}
+
+func ValidateInterestConfig(v *validator.Validator, c *InterestConfig) {
+	v.Check(!c.AnnualRate.IsNegative(), "annual_rate", "must not be negative")
+	v.Check(validator.In(c.DayCount, DayCountActual365, DayCountActual360, DayCountActualActual, DayCount30360), "day_count", "must be one of ACT/365, ACT/360, ACT/ACT or 30/360")
+	v.Check(validator.In(c.Compounding, CompoundingSimple, CompoundingDaily), "compounding", "must be simple or daily")
+	v.Check(validator.In(c.PostingFrequency, PostingDaily, PostingMonthly, PostingQuarterly, PostingAnnually), "posting_frequency", "must be daily, monthly, quarterly or annually")
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/petrostrak/agile-transfer/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+func Test_ValidateAccount(t *testing.T) {
+	testCases := []struct {
+		balance  string
+		currency string
+		expected []string
+	}{
+		{"100", "EUR", nil},
+		{"0", "eur", nil},
+		{"-1", "EUR", []string{"balance"}},
+		{"100", "", []string{"currency"}},
+		{"100", "EURO", []string{"currency"}},
+		{"1.001", "EUR", []string{"balance"}},
+		{"-1", "", []string{"balance", "currency"}},
+	}
+
+	for _, tt := range testCases {
+		v := validator.New()
+		ValidateAccount(v, &Account{Balance: decimal.RequireFromString(tt.balance), Currency: tt.currency})
+
+		if len(v.Errors) != len(tt.expected) {
+			t.Errorf("%s %q: expected errors for %v but got %v", tt.balance, tt.currency, tt.expected, v.Errors)
+			continue
+		}
+		for _, key := range tt.expected {
+			if _, ok := v.Errors[key]; !ok {
+				t.Errorf("%s %q: expected an error for %s but got %v", tt.balance, tt.currency, key, v.Errors)
+			}
+		}
+	}
+}
+
+func Test_ValidateCustomer(t *testing.T) {
+	v := validator.New()
+	ValidateCustomer(v, &Customer{Name: "Jane Doe", Email: "jane@example.com", Type: CustomerIndividual, Status: CustomerActive})
+	if !v.Valid() {
+		t.Errorf("expected a valid customer but got %v", v.Errors)
+	}
+
+	v = validator.New()
+	empty := ""
+	ValidateCustomer(v, &Customer{Email: "jane", Type: "partnership", Subject: &empty})
+	for _, key := range []string{"name", "email", "type", "status", "subject"} {
+		if _, ok := v.Errors[key]; !ok {
+			t.Errorf("expected an error for %s but got %v", key, v.Errors)
+		}
+	}
+}
+
+func Test_ValidateAPIKey(t *testing.T) {
+	v := validator.New()
+	ValidateAPIKey(v, &APIKey{Client: "reporting", Scopes: []Scope{ScopeAccountsRead, ScopeTransfersCreate}})
+	if !v.Valid() {
+		t.Errorf("expected a valid key but got %v", v.Errors)
+	}
+
+	testCases := []struct {
+		name   string
+		scopes []Scope
+	}{
+		{"no scopes", nil},
+		{"unknown scope", []Scope{ScopeAccountsRead, "accounts:delete"}},
+		{"duplicate scope", []Scope{ScopeAdmin, ScopeAdmin}},
+	}
+
+	for _, tt := range testCases {
+		v := validator.New()
+		ValidateAPIKey(v, &APIKey{Scopes: tt.scopes})
+		for _, key := range []string{"client", "scopes"} {
+			if _, ok := v.Errors[key]; !ok {
+				t.Errorf("%s: expected an error for %s but got %v", tt.name, key, v.Errors)
+			}
+		}
+	}
+}
+
+func Test_ValidateRole(t *testing.T) {
+	for _, role := range DefaultRoles() {
+		v := validator.New()
+		if ValidateRole(v, &role); !v.Valid() {
+			t.Errorf("expected default role %s to be valid but got %v", role.Name, v.Errors)
+		}
+	}
+
+	testCases := []struct {
+		name  string
+		role  Role
+		field string
+	}{
+		{"no name", Role{}, "name"},
+		{"uppercase name", Role{Name: "Support"}, "name"},
+		{"name with spaces", Role{Name: "back office"}, "name"},
+		{"unknown permission", Role{Name: "ops", Permissions: []Permission{PermissionAccountsView, "accounts:destroy"}}, "permissions"},
+		{"duplicate permission", Role{Name: "ops", Permissions: []Permission{PermissionAccountsView, PermissionAccountsView}}, "permissions"},
+	}
+
+	for _, tt := range testCases {
+		v := validator.New()
+		ValidateRole(v, &tt.role)
+		if _, ok := v.Errors[tt.field]; !ok {
+			t.Errorf("%s: expected an error for %s but got %v", tt.name, tt.field, v.Errors)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/petrostrak/agile-transfer/internal/core/domain"
@@ -12,7 +13,8 @@ type AccountRepository interface {
 	Insert(acc *domain.Account) error
 	Get(id uuid.UUID) (*domain.Account, error)
 	Update(account *domain.Account) error
-	Delete(id uuid.UUID) error
+	Delete(id uuid.UUID, version int) error
+	SetFrozen(id uuid.UUID, frozen bool) (*domain.Account, error)
 	GetAll(ctx context.Context) ([]domain.Account, error)
 }
 
@@ -20,9 +22,138 @@ type TransferRepository interface {
 	Insert(ctx context.Context, tx domain.Transfer) (domain.Transfer, error)
 	Get(id uuid.UUID) (*domain.Transfer, error)
 	GetAll() ([]domain.Transfer, error)
-	ExecTx(ctx context.Context, fn func() error) error
 	TransferTx(ctx context.Context, arg domain.TransferTxParams) (*domain.TransferTxResult, error)
 	AddAccountBalance(ctx context.Context, id uuid.UUID, amount decimal.Decimal) (domain.Account, error)
-	AddMoney(ctx context.Context, sourceAccountID uuid.UUID, sourceAccountAmount decimal.Decimal, targetAccountID uuid.UUID, targetAccountAmount decimal.Decimal) (sourceAccount, targetAccount domain.Account, err error)
 	ValidateAccounts(ctx context.Context, sourceAccountID, targetAccountID uuid.UUID) ([]domain.Account, error)
 }
+
+type InterestRepository interface {
+	GetConfig(ctx context.Context, accountID uuid.UUID) (*domain.InterestConfig, error)
+	UpsertConfig(ctx context.Context, cfg *domain.InterestConfig) error
+	GetEnabledConfigs(ctx context.Context) ([]domain.InterestConfig, error)
+	InsertAccrual(ctx context.Context, accrual *domain.InterestAccrual) (bool, error)
+	GetAccruals(ctx context.Context, accountID uuid.UUID) ([]domain.InterestAccrual, error)
+	UnpostedInterest(ctx context.Context, accountID uuid.UUID, upTo time.Time) (decimal.Decimal, error)
+	InsertPosting(ctx context.Context, posting *domain.InterestPosting) (bool, error)
+	CompletePosting(ctx context.Context, postingID, transferID uuid.UUID) error
+	IncompletePostings(ctx context.Context) ([]domain.InterestPosting, error)
+	// LastAccrualDate returns the zero time when nothing was accrued up to the
+	// given day.
+	LastAccrualDate(ctx context.Context, upTo time.Time) (time.Time, error)
+}
+
+type LedgerRepository interface {
+	BalanceAt(ctx context.Context, accountID uuid.UUID, currency string, at time.Time) (decimal.Decimal, error)
+	GetEntries(ctx context.Context, accountID uuid.UUID, currency string, from, to time.Time) ([]domain.Entry, error)
+	BalancesAt(ctx context.Context, at time.Time) ([]domain.Balance, error)
+	TakeSnapshots(ctx context.Context, at time.Time) (int64, error)
+}
+
+type CustomerRepository interface {
+	Insert(ctx context.Context, customer *domain.Customer) error
+	Get(ctx context.Context, id uuid.UUID) (*domain.Customer, error)
+	// GetBySubject returns nil, and no error, when no customer has the subject.
+	GetBySubject(ctx context.Context, subject string) (*domain.Customer, error)
+	Update(ctx context.Context, customer *domain.Customer) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetAll(ctx context.Context) ([]domain.Customer, error)
+	GetAccounts(ctx context.Context, id uuid.UUID) ([]domain.Account, error)
+	AddBeneficiary(ctx context.Context, beneficiary *domain.Beneficiary) error
+	RemoveBeneficiary(ctx context.Context, customerID, accountID uuid.UUID) error
+	GetBeneficiaries(ctx context.Context, customerID uuid.UUID) ([]domain.Beneficiary, error)
+	IsBeneficiary(ctx context.Context, customerID, accountID uuid.UUID) (bool, error)
+}
+
+type WalletRepository interface {
+	GetPockets(ctx context.Context, accountID uuid.UUID) ([]domain.Pocket, error)
+	OpenPocket(ctx context.Context, accountID uuid.UUID, currency string) (*domain.Pocket, error)
+	Convert(ctx context.Context, conversion *domain.Conversion) error
+}
+
+type APIKeyRepository interface {
+	Insert(ctx context.Context, key *domain.APIKey) error
+	Get(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	// GetByPrefix returns nil, and no error, when no key has the prefix.
+	GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	GetAll(ctx context.Context) ([]domain.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+	Expire(ctx context.Context, id uuid.UUID, at time.Time) error
+	Touch(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type RoleRepository interface {
+	Insert(ctx context.Context, role *domain.Role) error
+	Get(ctx context.Context, name string) (*domain.Role, error)
+	GetAll(ctx context.Context) ([]domain.Role, error)
+	// GetByNames skips the names no role has.
+	GetByNames(ctx context.Context, names []string) ([]domain.Role, error)
+	Update(ctx context.Context, role *domain.Role) error
+	Delete(ctx context.Context, name string) error
+}
+
+// AuditRepository only ever appends entries. Insert seals each entry to the
+// last one, one at a time.
+type AuditRepository interface {
+	Insert(ctx context.Context, entry *domain.AuditEntry) error
+	// GetAll returns the entries filter selects, newest first.
+	GetAll(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+	// GetChain returns up to limit entries with an id above afterID, oldest
+	// first.
+	GetChain(ctx context.Context, afterID int64, limit int) ([]domain.AuditEntry, error)
+}
+
+type ApprovalRepository interface {
+	Insert(ctx context.Context, approval *domain.Approval) error
+	Get(ctx context.Context, id uuid.UUID) (*domain.Approval, error)
+	// GetAll returns the approvals with the given status, all of them when
+	// it is empty, newest first.
+	GetAll(ctx context.Context, status domain.ApprovalStatus) ([]domain.Approval, error)
+	// Decide records the decision set on approval if it is still pending
+	// and has not expired by its DecidedAt, reporting whether it was.
+	Decide(ctx context.Context, approval *domain.Approval) (bool, error)
+	// Finish records how carrying out an approved action went.
+	Finish(ctx context.Context, approval *domain.Approval) error
+	// Expire marks the pending approvals that expired by at.
+	Expire(ctx context.Context, at time.Time) error
+}
+
+// RateLimitRepository keeps the token buckets of the rate limits. Instances
+// sharing a store share their limits.
+type RateLimitRepository interface {
+	// Take takes a token from the bucket under key, as RateBucket.Take
+	// does, with no other Take on the same key in between.
+	Take(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (domain.RateDecision, error)
+	// PurgeBuckets forgets the buckets last updated before at.
+	PurgeBuckets(ctx context.Context, at time.Time) error
+}
+
+// IdempotencyRepository keeps the responses to requests made with an
+// idempotency key. Instances sharing a store honour each other's keys.
+type IdempotencyRepository interface {
+	// Reserve keeps response, which is not done, under its key unless a
+	// response that has not expired by now is kept there already, which it
+	// returns. It returns nil when the key is reserved.
+	Reserve(ctx context.Context, response *domain.IdempotentResponse, now time.Time) (*domain.IdempotentResponse, error)
+	// Complete keeps the response of the request that reserved its key.
+	Complete(ctx context.Context, response *domain.IdempotentResponse) error
+	// Release frees the key of a request that is not done, so that it can
+	// be retried.
+	Release(ctx context.Context, key string) error
+	// PurgeIdempotencyKeys forgets the responses that expired before at.
+	PurgeIdempotencyKeys(ctx context.Context, at time.Time) error
+}
+
+type SigningRepository interface {
+	Insert(ctx context.Context, secret *domain.SigningSecret) error
+	Get(ctx context.Context, id uuid.UUID) (*domain.SigningSecret, error)
+	GetAll(ctx context.Context) ([]domain.SigningSecret, error)
+	// GetByClient returns the secrets of client, active or not.
+	GetByClient(ctx context.Context, client string) ([]domain.SigningSecret, error)
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+	Expire(ctx context.Context, id uuid.UUID, at time.Time) error
+	// UseNonce remembers the nonce of client until expires, reporting
+	// false when it is already remembered at now.
+	UseNonce(ctx context.Context, client, nonce string, now, expires time.Time) (bool, error)
+	// PurgeNonces forgets the nonces that expired before at.
+	PurgeNonces(ctx context.Context, at time.Time) error
+}
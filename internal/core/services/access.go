@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/ports"
+)
+
+// AccessService manages the roles of back-office users and records the
+// requests refused for lack of permission.
+type AccessService struct {
+	roles ports.RoleRepository
+	audit ports.AuditRepository
+	now   func() time.Time
+}
+
+func NewAccessService(roles ports.RoleRepository, audit ports.AuditRepository) *AccessService {
+	return &AccessService{
+		roles,
+		audit,
+		time.Now,
+	}
+}
+
+// SeedRoles creates the default roles when there are no roles at all, and
+// reports how many it created.
+func (a *AccessService) SeedRoles(ctx context.Context) (int, error) {
+	roles, err := a.roles.GetAll(ctx)
+	if err != nil || len(roles) > 0 {
+		return 0, err
+	}
+
+	defaults := domain.DefaultRoles()
+	for i := range defaults {
+		if err := a.roles.Insert(ctx, &defaults[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(defaults), nil
+}
+
+func (a *AccessService) Insert(ctx context.Context, role *domain.Role) error {
+	return a.roles.Insert(ctx, role)
+}
+
+func (a *AccessService) Get(ctx context.Context, name string) (*domain.Role, error) {
+	return a.roles.Get(ctx, name)
+}
+
+func (a *AccessService) GetAll(ctx context.Context) ([]domain.Role, error) {
+	return a.roles.GetAll(ctx)
+}
+
+func (a *AccessService) Update(ctx context.Context, role *domain.Role) error {
+	return a.roles.Update(ctx, role)
+}
+
+func (a *AccessService) Delete(ctx context.Context, name string) error {
+	return a.roles.Delete(ctx, name)
+}
+
+// Matrix lays out which roles grant each permission.
+func (a *AccessService) Matrix(ctx context.Context) ([]domain.MatrixRow, error) {
+	roles, err := a.roles.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return domain.Matrix(roles), nil
+}
+
+// Deny records in the audit trail that the request described by entry was
+// refused.
+func (a *AccessService) Deny(ctx context.Context, entry *domain.AuditEntry) error {
+	entry.OccurredAt = a.now().UTC()
+	entry.Action = domain.AuditAccessDenied
+	return a.audit.Insert(ctx, entry)
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/ports"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+// ApprovalService holds the actions its policy says need approval until
+// someone other than who asked for them approves them, and then carries them
+// out through the account and transfer services.
+type ApprovalService struct {
+	repo      ports.ApprovalRepository
+	policy    domain.ApprovalPolicy
+	accounts  *AccountService
+	transfers *TransferService
+	audit     *AuditService
+	now       func() time.Time
+}
+
+func NewApprovalService(repo ports.ApprovalRepository, policy domain.ApprovalPolicy, accounts *AccountService, transfers *TransferService, audit *AuditService) *ApprovalService {
+	return &ApprovalService{
+		repo,
+		policy,
+		accounts,
+		transfers,
+		audit,
+		time.Now,
+	}
+}
+
+func (a *ApprovalService) Policy() domain.ApprovalPolicy {
+	return a.policy
+}
+
+// Request holds the action until it is approved, rejected or expires. A
+// transfer from the base pocket is held with the currency the pocket has now.
+func (a *ApprovalService) Request(ctx context.Context, approval *domain.Approval) error {
+	if order := approval.Transfer; order != nil {
+		order.SourceCurrency = currency.Normalize(order.SourceCurrency)
+		if order.SourceCurrency == "" {
+			account, err := a.accounts.Get(approval.AccountID)
+			if err != nil {
+				return err
+			}
+			order.SourceCurrency = account.Currency
+		}
+	}
+
+	approval.Status = domain.ApprovalPending
+	approval.ExpiresAt = a.now().UTC().Add(a.policy.TTL)
+	return a.repo.Insert(ctx, approval)
+}
+
+func (a *ApprovalService) Get(ctx context.Context, id uuid.UUID) (*domain.Approval, error) {
+	if err := a.repo.Expire(ctx, a.now()); err != nil {
+		return nil, err
+	}
+	return a.repo.Get(ctx, id)
+}
+
+// GetAll returns the approvals with the given status, all of them when it is
+// empty, newest first.
+func (a *ApprovalService) GetAll(ctx context.Context, status domain.ApprovalStatus) ([]domain.Approval, error) {
+	if err := a.repo.Expire(ctx, a.now()); err != nil {
+		return nil, err
+	}
+	return a.repo.GetAll(ctx, status)
+}
+
+// Approve carries out the action of a pending approval for checker, who must
+// not be who asked for it. When the action fails the approval is marked as
+// failed and the error returned with it. The action is recorded in the audit
+// log as made by who asked for it, on the approval of checker: a transfer in
+// the transaction that makes it, other actions once made, returning the
+// error of recording them with the approval.
+func (a *ApprovalService) Approve(ctx context.Context, id uuid.UUID, checker string) (*domain.Approval, error) {
+	approval, err := a.decide(ctx, id, checker, domain.ApprovalApproved, "")
+	if err != nil {
+		return nil, err
+	}
+
+	audit := &domain.AuditEntry{
+		Actor:  approval.RequestedBy,
+		Detail: "approved by " + checker + " in approvals/" + approval.ID.String(),
+	}
+	err = a.execute(ctx, approval, audit)
+	if err != nil {
+		approval.Status, approval.Reason = domain.ApprovalFailed, err.Error()
+	}
+	if finishErr := a.repo.Finish(ctx, approval); finishErr != nil {
+		return nil, finishErr
+	}
+	if err != nil || approval.Action == domain.ApprovalTransfer {
+		return approval, err
+	}
+	return approval, a.audit.Record(ctx, audit)
+}
+
+// Reject drops the action of a pending approval. Unlike approving, whoever
+// asked for the action may reject it, to withdraw it.
+func (a *ApprovalService) Reject(ctx context.Context, id uuid.UUID, checker, reason string) (*domain.Approval, error) {
+	return a.decide(ctx, id, checker, domain.ApprovalRejected, reason)
+}
+
+func (a *ApprovalService) decide(ctx context.Context, id uuid.UUID, checker string, status domain.ApprovalStatus, reason string) (*domain.Approval, error) {
+	approval, err := a.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if status == domain.ApprovalApproved && approval.RequestedBy == checker {
+		return nil, utils.ErrSelfApproval
+	}
+	if approval.Status != domain.ApprovalPending {
+		return nil, utils.ErrApprovalNotPending
+	}
+
+	now := a.now().UTC()
+	approval.Status, approval.Reason = status, reason
+	approval.DecidedBy, approval.DecidedAt = &checker, &now
+
+	decided, err := a.repo.Decide(ctx, approval)
+	if err != nil {
+		return nil, err
+	}
+	if !decided {
+		return nil, utils.ErrApprovalNotPending
+	}
+	return approval, nil
+}
+
+// execute carries out the action of the approval, describing the change it
+// made in audit.
+func (a *ApprovalService) execute(ctx context.Context, approval *domain.Approval, audit *domain.AuditEntry) error {
+	if approval.Action == domain.ApprovalTransfer {
+		order := approval.Transfer
+		result, err := a.transfers.TransferApproved(ctx, approval.AccountID, order.TargetAccountID, order.Amount, order.SourceCurrency, order.TargetCurrency, audit)
+		if err != nil {
+			return err
+		}
+		approval.TransferID = &result.Transfer.ID
+		return nil
+	}
+
+	account, err := a.accounts.Get(approval.AccountID)
+	if err != nil {
+		return err
+	}
+	audit.Resource, audit.Before = "accounts/"+account.ID.String(), snapshot(account)
+
+	change := approval.Change
+	if approval.Action == domain.ApprovalAccountClosure {
+		audit.Action = domain.AuditAccountDeleted
+		return a.accounts.Delete(approval.AccountID, change.Version)
+	}
+
+	account.Version = change.Version
+	account.Balance, account.Currency, account.CustomerID = change.Balance, change.Currency, change.CustomerID
+	if err := a.accounts.Update(account); err != nil {
+		return err
+	}
+	audit.Action, audit.After = domain.AuditAccountUpdated, snapshot(account)
+	return nil
+}
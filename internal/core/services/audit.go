@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/ports"
+)
+
+// auditBatch is how many entries Verify reads at a time.
+const auditBatch = 1000
+
+// AuditService records the state-changing requests in the append-only audit
+// log and checks that the log has not been tampered with.
+type AuditService struct {
+	repo ports.AuditRepository
+	now  func() time.Time
+}
+
+func NewAuditService(repo ports.AuditRepository) *AuditService {
+	return &AuditService{
+		repo,
+		time.Now,
+	}
+}
+
+// Record appends entry to the log, chained to the entry before it.
+func (a *AuditService) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	entry.OccurredAt = a.now().UTC()
+	return a.repo.Insert(ctx, entry)
+}
+
+// snapshot is the JSON encoding of a resource for the audit log, nil for
+// nil.
+func snapshot(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+// GetAll returns the entries filter selects, newest first.
+func (a *AuditService) GetAll(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	return a.repo.GetAll(ctx, filter)
+}
+
+// Verify walks the whole log, oldest entry first, and reports the first
+// entry whose hash does not match it or the entry before it.
+func (a *AuditService) Verify(ctx context.Context) (domain.AuditVerification, error) {
+	var chain domain.AuditChain
+	var afterID int64
+
+	for {
+		entries, err := a.repo.GetChain(ctx, afterID, auditBatch)
+		if err != nil {
+			return domain.AuditVerification{}, err
+		}
+		if !chain.Check(entries) || len(entries) < auditBatch {
+			return chain.Result(), nil
+		}
+		afterID = entries[len(entries)-1].ID
+	}
+}
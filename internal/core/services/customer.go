@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/ports"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+type CustomerService struct {
+	repo     ports.CustomerRepository
+	accounts ports.AccountRepository
+}
+
+func NewCustomerService(repo ports.CustomerRepository, accounts ports.AccountRepository) *CustomerService {
+	return &CustomerService{
+		repo,
+		accounts,
+	}
+}
+
+func (c *CustomerService) Insert(ctx context.Context, customer *domain.Customer) error {
+	if customer.Status == "" {
+		customer.Status = domain.CustomerActive
+	}
+	if !customer.Valid() {
+		return utils.ErrInvalidCustomer
+	}
+	return c.repo.Insert(ctx, customer)
+}
+
+func (c *CustomerService) Get(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
+	return c.repo.Get(ctx, id)
+}
+
+func (c *CustomerService) Update(ctx context.Context, customer *domain.Customer) error {
+	if !customer.Valid() {
+		return utils.ErrInvalidCustomer
+	}
+	return c.repo.Update(ctx, customer)
+}
+
+// Delete removes a customer that no longer holds any account.
+func (c *CustomerService) Delete(ctx context.Context, id uuid.UUID) error {
+	accounts, err := c.repo.GetAccounts(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(accounts) > 0 {
+		return utils.ErrCustomerHasAccounts
+	}
+	return c.repo.Delete(ctx, id)
+}
+
+func (c *CustomerService) GetAll(ctx context.Context) ([]domain.Customer, error) {
+	return c.repo.GetAll(ctx)
+}
+
+// Accounts returns the accounts of a customer together with their total
+// balance per currency, summed over the pockets of every account.
+func (c *CustomerService) Accounts(ctx context.Context, id uuid.UUID) ([]domain.Account, []domain.CurrencyBalance, error) {
+	if _, err := c.repo.Get(ctx, id); err != nil {
+		return nil, nil, err
+	}
+
+	accounts, err := c.repo.GetAccounts(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	totals := make(map[string]*domain.CurrencyBalance)
+	for _, account := range accounts {
+		for _, pocket := range account.Pockets {
+			total, ok := totals[pocket.Currency]
+			if !ok {
+				total = &domain.CurrencyBalance{Currency: pocket.Currency}
+				totals[pocket.Currency] = total
+			}
+			total.Balance = total.Balance.Add(pocket.Balance)
+			total.Accounts++
+		}
+	}
+
+	balances := make([]domain.CurrencyBalance, 0, len(totals))
+	for _, total := range totals {
+		balances = append(balances, *total)
+	}
+	sort.Slice(balances, func(i, j int) bool { return balances[i].Currency < balances[j].Currency })
+
+	return accounts, balances, nil
+}
+
+func (c *CustomerService) AddBeneficiary(ctx context.Context, beneficiary *domain.Beneficiary) error {
+	if _, err := c.repo.Get(ctx, beneficiary.CustomerID); err != nil {
+		return err
+	}
+	if _, err := c.accounts.Get(beneficiary.AccountID); err != nil {
+		return err
+	}
+	return c.repo.AddBeneficiary(ctx, beneficiary)
+}
+
+func (c *CustomerService) RemoveBeneficiary(ctx context.Context, customerID, accountID uuid.UUID) error {
+	return c.repo.RemoveBeneficiary(ctx, customerID, accountID)
+}
+
+func (c *CustomerService) GetBeneficiaries(ctx context.Context, customerID uuid.UUID) ([]domain.Beneficiary, error) {
+	if _, err := c.repo.Get(ctx, customerID); err != nil {
+		return nil, err
+	}
+	return c.repo.GetBeneficiaries(ctx, customerID)
+}
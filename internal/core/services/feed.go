@@ -0,0 +1,51 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+)
+
+// transferFeed hands every transfer made to the subscribers listening at the
+// time. A subscriber that lets its buffer fill up is dropped and its channel
+// closed, so that a slow reader never holds up a transfer.
+type transferFeed struct {
+	mu          sync.Mutex
+	subscribers map[chan domain.Transfer]struct{}
+}
+
+func newTransferFeed() *transferFeed {
+	return &transferFeed{subscribers: make(map[chan domain.Transfer]struct{})}
+}
+
+func (f *transferFeed) subscribe(buffer int) (<-chan domain.Transfer, func()) {
+	ch := make(chan domain.Transfer, buffer)
+
+	f.mu.Lock()
+	f.subscribers[ch] = struct{}{}
+	f.mu.Unlock()
+
+	return ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if _, ok := f.subscribers[ch]; ok {
+			delete(f.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (f *transferFeed) publish(tx domain.Transfer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for ch := range f.subscribers {
+		select {
+		case ch <- tx:
+		default:
+			delete(f.subscribers, ch)
+			close(ch)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/ports"
+)
+
+// IdempotencyService keeps the responses to requests made with an
+// idempotency key in the database, so that every instance honours the keys
+// the others saw.
+type IdempotencyService struct {
+	repo ports.IdempotencyRepository
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+func NewIdempotencyService(repo ports.IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		repo,
+		ttl,
+		time.Now,
+	}
+}
+
+// Reserve reserves key for the request with fingerprint before it is
+// processed. It returns the response kept under the key instead when there
+// is one, done or not, and nil when the key was reserved.
+func (i *IdempotencyService) Reserve(ctx context.Context, key string, fingerprint []byte) (*domain.IdempotentResponse, error) {
+	now := i.now()
+	return i.repo.Reserve(ctx, &domain.IdempotentResponse{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(domain.IdempotencyTimeout),
+	}, now)
+}
+
+// Complete keeps the response to the request that reserved key for the ttl.
+func (i *IdempotencyService) Complete(ctx context.Context, key string, status int, header map[string][]string, body []byte) error {
+	return i.repo.Complete(ctx, &domain.IdempotentResponse{
+		Key:       key,
+		Done:      true,
+		Status:    status,
+		Header:    header,
+		Body:      body,
+		ExpiresAt: i.now().Add(i.ttl),
+	})
+}
+
+// Release frees key when its request failed, so that it can be retried.
+func (i *IdempotencyService) Release(ctx context.Context, key string) error {
+	return i.repo.Release(ctx, key)
+}
+
+// PurgeKeys forgets the responses that can no longer be replayed.
+func (i *IdempotencyService) PurgeKeys(ctx context.Context) error {
+	return i.repo.PurgeIdempotencyKeys(ctx, i.now())
+}
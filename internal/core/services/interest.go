@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/ports"
+	"github.com/petrostrak/agile-transfer/utils"
+	"github.com/shopspring/decimal"
+)
+
+type InterestService struct {
+	repo            ports.InterestRepository
+	accounts        ports.AccountRepository
+	ledger          ports.LedgerRepository
+	transfers       *TransferService
+	expenseAccounts map[string]uuid.UUID
+}
+
+// NewInterestService returns a service that pays interest out of the
+// expense account configured for each currency.
+func NewInterestService(repo ports.InterestRepository, accounts ports.AccountRepository, ledger ports.LedgerRepository, transfers *TransferService, expenseAccounts map[string]uuid.UUID) *InterestService {
+	return &InterestService{
+		repo,
+		accounts,
+		ledger,
+		transfers,
+		expenseAccounts,
+	}
+}
+
+func (i *InterestService) GetConfig(ctx context.Context, accountID uuid.UUID) (*domain.InterestConfig, error) {
+	return i.repo.GetConfig(ctx, accountID)
+}
+
+func (i *InterestService) SetConfig(ctx context.Context, cfg *domain.InterestConfig) error {
+	if !cfg.Valid() {
+		return utils.ErrInvalidInterest
+	}
+	if _, err := i.accounts.Get(cfg.AccountID); err != nil {
+		return err
+	}
+	return i.repo.UpsertConfig(ctx, cfg)
+}
+
+func (i *InterestService) GetAccruals(ctx context.Context, accountID uuid.UUID) ([]domain.InterestAccrual, error) {
+	return i.repo.GetAccruals(ctx, accountID)
+}
+
+// CatchUp runs every day from the one after the last accrual through the
+// given day, so that the days missed while the job did not run are accrued
+// too. When nothing was accrued yet only the given day is run. Accounts are
+// not accrued for the days before their interest was set up.
+func (i *InterestService) CatchUp(ctx context.Context, through time.Time) ([]domain.InterestRun, error) {
+	through = time.Date(through.Year(), through.Month(), through.Day(), 0, 0, 0, 0, time.UTC)
+
+	day, err := i.repo.LastAccrualDate(ctx, through)
+	if err != nil {
+		return nil, err
+	}
+	if day.IsZero() {
+		day = through.AddDate(0, 0, -1)
+	}
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+	var runs []domain.InterestRun
+	for day = day.AddDate(0, 0, 1); !day.After(through); day = day.AddDate(0, 0, 1) {
+		run, err := i.Run(ctx, day)
+		if err != nil {
+			return runs, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// Run accrues one day of interest for every account with interest enabled, on
+// its balance at the end of that day, and posts the accrued amount for
+// accounts whose posting period ends that day. Postings reserved by earlier
+// runs but never paid are paid first. Accruals and postings are keyed by day,
+// which makes running it twice for the same day a no-op.
+func (i *InterestService) Run(ctx context.Context, day time.Time) (domain.InterestRun, error) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	run := domain.InterestRun{Date: day, Failures: []string{}}
+
+	incomplete, err := i.repo.IncompletePostings(ctx)
+	if err != nil {
+		return run, err
+	}
+	for _, posting := range incomplete {
+		if err := i.complete(ctx, posting); err != nil {
+			utils.LogError(fmt.Errorf("interest posting of account %s on %s: %w", posting.AccountID, posting.PostingDate.Format(time.DateOnly), err))
+			run.Failures = append(run.Failures, posting.AccountID.String())
+			continue
+		}
+		run.Posted++
+	}
+
+	configs, err := i.repo.GetEnabledConfigs(ctx)
+	if err != nil {
+		return run, err
+	}
+
+	for _, cfg := range configs {
+		accrued, posted, err := i.runAccount(ctx, cfg, day)
+		if err != nil {
+			utils.LogError(fmt.Errorf("interest for account %s on %s: %w", cfg.AccountID, day.Format(time.DateOnly), err))
+			run.Failures = append(run.Failures, cfg.AccountID.String())
+		}
+		if accrued {
+			run.Accrued++
+		}
+		if posted {
+			run.Posted++
+		}
+	}
+
+	return run, nil
+}
+
+func (i *InterestService) runAccount(ctx context.Context, cfg domain.InterestConfig, day time.Time) (accrued, posted bool, err error) {
+	// Catching up must not accrue for the days before interest was set up.
+	if day.Before(time.Date(cfg.CreatedAt.Year(), cfg.CreatedAt.Month(), cfg.CreatedAt.Day(), 0, 0, 0, 0, time.UTC)) {
+		return
+	}
+
+	account, err := i.accounts.Get(cfg.AccountID)
+	if err != nil {
+		return
+	}
+
+	base, err := i.ledger.BalanceAt(ctx, account.ID, account.Currency, day.AddDate(0, 0, 1))
+	if err != nil {
+		return
+	}
+	if cfg.Compounding == domain.CompoundingDaily {
+		unposted, err := i.repo.UnpostedInterest(ctx, account.ID, day.AddDate(0, 0, -1))
+		if err != nil {
+			return false, false, err
+		}
+		base = base.Add(unposted)
+	}
+
+	accrual := &domain.InterestAccrual{
+		AccountID:   account.ID,
+		AccrualDate: day,
+		Balance:     base,
+		AnnualRate:  cfg.AnnualRate,
+		Amount:      cfg.DailyAccrual(base, day),
+	}
+
+	accrued, err = i.repo.InsertAccrual(ctx, accrual)
+	if err != nil {
+		return
+	}
+
+	if cfg.PostingDue(day) {
+		posted, err = i.post(ctx, account, day)
+	}
+
+	return
+}
+
+// post pays the interest accrued so far into the account through the regular
+// transfer path. Whatever is lost to rounding stays unposted and is paid with
+// the next posting. A posting whose transfer fails stays reserved and is paid
+// by the next run.
+func (i *InterestService) post(ctx context.Context, account *domain.Account, day time.Time) (bool, error) {
+	unposted, err := i.repo.UnpostedInterest(ctx, account.ID, day)
+	if err != nil {
+		return false, err
+	}
+
+	amount, err := currency.Round(account.Currency, unposted)
+	if err != nil {
+		return false, err
+	}
+	if !amount.IsPositive() {
+		return false, nil
+	}
+
+	expenseAccountID, ok := i.expenseAccounts[account.Currency]
+	if !ok {
+		return false, utils.ErrNoExpenseAccount
+	}
+
+	posting := &domain.InterestPosting{
+		AccountID:     account.ID,
+		PostingDate:   day,
+		AccruedAmount: unposted,
+		PostedAmount:  amount,
+	}
+
+	reserved, err := i.repo.InsertPosting(ctx, posting)
+	if err != nil || !reserved {
+		return false, err
+	}
+
+	if err := i.pay(ctx, expenseAccountID, *posting); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// complete finishes a posting an earlier run reserved: it is marked complete
+// if its transfer was made, and paid otherwise.
+func (i *InterestService) complete(ctx context.Context, posting domain.InterestPosting) error {
+	if posting.TransferID != nil {
+		return i.repo.CompletePosting(ctx, posting.ID, *posting.TransferID)
+	}
+
+	account, err := i.accounts.Get(posting.AccountID)
+	if err != nil {
+		return err
+	}
+	expenseAccountID, ok := i.expenseAccounts[account.Currency]
+	if !ok {
+		return utils.ErrNoExpenseAccount
+	}
+	return i.pay(ctx, expenseAccountID, posting)
+}
+
+// pay makes the transfer of a reserved posting and marks the posting
+// complete. The transfer has the id of the posting and is recorded with its
+// debit, its credit and its audit entry or not at all, so that it is made at
+// most once however often paying is retried.
+func (i *InterestService) pay(ctx context.Context, expenseAccountID uuid.UUID, posting domain.InterestPosting) error {
+	audit := &domain.AuditEntry{
+		Actor:  domain.AuditActorInterest,
+		Detail: "interest posted for " + posting.PostingDate.Format(time.DateOnly),
+	}
+	result, err := i.transfer(ctx, posting.ID, expenseAccountID, posting.AccountID, posting.PostedAmount, audit)
+	if err != nil {
+		return err
+	}
+	return i.repo.CompletePosting(ctx, posting.ID, result.Transfer.ID)
+}
+
+func (i *InterestService) transfer(ctx context.Context, id, sourceID, targetID uuid.UUID, amount decimal.Decimal, audit *domain.AuditEntry) (*domain.TransferTxResult, error) {
+	accounts, err := i.transfers.ValidateAccounts(ctx, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	source, target := accounts[0], accounts[1]
+
+	return i.transfers.TransferTx(ctx, domain.TransferTxParams{
+		TransferID:      id,
+		SourceAccountID: source.ID,
+		TargetAccountID: target.ID,
+		SourceBalance:   domain.NewMoney(source.Balance, source.Currency),
+		Amount:          domain.NewMoney(amount, source.Currency),
+		TargetCurrency:  target.Currency,
+		Audit:           audit,
+	})
+}
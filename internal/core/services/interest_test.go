@@ -0,0 +1,90 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository/repositorytest"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/shopspring/decimal"
+)
+
+func TestInterestPostings(t *testing.T) {
+	ctx := context.Background()
+	store := repositorytest.NewRepository()
+	accounts := services.NewAccountService(store.AccountRepository)
+	transfers := services.NewTransferService(store.TransferRepository, store.CustomerRepository, nil, domain.ApprovalPolicy{})
+
+	expense := &domain.Account{Balance: decimal.NewFromInt(1000), Currency: "EUR"}
+	saver := &domain.Account{Balance: decimal.NewFromInt(3650), Currency: "EUR"}
+	for _, acc := range []*domain.Account{expense, saver} {
+		if err := accounts.Insert(acc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	interest := services.NewInterestService(store.InterestRepository, store.AccountRepository, store.LedgerRepository, transfers, map[string]uuid.UUID{"EUR": expense.ID})
+	err := interest.SetConfig(ctx, &domain.InterestConfig{
+		AccountID:        saver.ID,
+		AnnualRate:       decimal.RequireFromString("0.1"),
+		DayCount:         domain.DayCountActual365,
+		Compounding:      domain.CompoundingSimple,
+		PostingFrequency: domain.PostingDaily,
+		Enabled:          true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	today := time.Now().UTC()
+	runs, err := interest.CatchUp(ctx, today)
+	if err != nil || len(runs) != 1 || runs[0].Accrued != 1 || runs[0].Posted != 1 {
+		t.Fatalf("expected today to be accrued and posted but got %+v (%v)", runs, err)
+	}
+	if entries := store.AuditRepository.Entries(); len(entries) != 1 || entries[0].Actor != domain.AuditActorInterest || entries[0].Action != domain.AuditTransferCreated || !strings.HasPrefix(entries[0].Resource, "transfers/") {
+		t.Errorf("expected the posting to be audited but got %+v", entries)
+	}
+	if runs, _ := interest.CatchUp(ctx, today); len(runs) != 0 {
+		t.Errorf("expected nothing to catch up but got %+v", runs)
+	}
+	if runs, _ := interest.CatchUp(ctx, today.AddDate(0, 0, 2)); len(runs) != 2 {
+		t.Errorf("expected the two days missed to be run but got %+v", runs)
+	}
+
+	// A posting reserved by a run that stopped before paying it is paid by
+	// the next run, and one whose transfer was made is only completed.
+	unpaid := &domain.InterestPosting{AccountID: saver.ID, PostingDate: today.AddDate(0, 0, 10), PostedAmount: decimal.NewFromInt(5)}
+	paid := &domain.InterestPosting{AccountID: saver.ID, PostingDate: today.AddDate(0, 0, 11), PostedAmount: decimal.NewFromInt(7)}
+	for _, posting := range []*domain.InterestPosting{unpaid, paid} {
+		if _, err := store.InterestRepository.InsertPosting(ctx, posting); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = transfers.TransferTx(ctx, domain.TransferTxParams{
+		TransferID:      paid.ID,
+		SourceAccountID: expense.ID,
+		TargetAccountID: saver.ID,
+		SourceBalance:   domain.NewMoney(decimal.NewFromInt(1000), "EUR"),
+		Amount:          domain.NewMoney(decimal.NewFromInt(7), "EUR"),
+		TargetCurrency:  "EUR",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before, _ := accounts.Get(saver.ID)
+	run, err := interest.Run(ctx, today.AddDate(0, 0, -1))
+	if err != nil || run.Posted != 2 || len(run.Failures) != 0 {
+		t.Errorf("expected the two reserved postings to be completed but got %+v (%v)", run, err)
+	}
+	after, _ := accounts.Get(saver.ID)
+	if !after.Balance.Sub(before.Balance).Equal(decimal.NewFromInt(5)) {
+		t.Errorf("expected only the unpaid posting to be paid but the balance went from %s to %s", before.Balance, after.Balance)
+	}
+	if incomplete, _ := store.InterestRepository.IncompletePostings(ctx); len(incomplete) != 0 {
+		t.Errorf("expected every posting to be complete but got %+v", incomplete)
+	}
+}
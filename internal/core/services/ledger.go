@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/ports"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+type LedgerService struct {
+	repo     ports.LedgerRepository
+	accounts ports.AccountRepository
+}
+
+func NewLedgerService(repo ports.LedgerRepository, accounts ports.AccountRepository) *LedgerService {
+	return &LedgerService{
+		repo,
+		accounts,
+	}
+}
+
+// Statement lists the entries of an account pocket in [from, to) with the
+// balance after each of them. The opening balance is the sum of every earlier
+// entry, so consecutive statements always join up. An empty currency selects
+// the base pocket.
+func (l *LedgerService) Statement(ctx context.Context, accountID uuid.UUID, currency string, from, to time.Time) (*domain.Statement, error) {
+	if !from.Before(to) {
+		return nil, utils.ErrInvalidPeriod
+	}
+
+	account, currency, err := l.pocketOf(accountID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	opening, err := l.repo.BalanceAt(ctx, accountID, currency, from.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := l.repo.GetEntries(ctx, accountID, currency, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &domain.Statement{
+		Account:        *account,
+		Currency:       currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		Lines:          make([]domain.StatementLine, 0, len(entries)),
+	}
+
+	balance := opening
+	for _, entry := range entries {
+		balance = balance.Add(entry.Amount)
+		statement.Lines = append(statement.Lines, domain.StatementLine{Entry: entry, Balance: balance})
+	}
+	statement.ClosingBalance = balance
+
+	return statement, nil
+}
+
+// BalanceAsOf returns the balance of an account pocket including every entry
+// made up to and including the given instant. An empty currency selects the
+// base pocket.
+func (l *LedgerService) BalanceAsOf(ctx context.Context, accountID uuid.UUID, currency string, asOf time.Time) (*domain.Balance, error) {
+	account, currency, err := l.pocketOf(accountID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := l.repo.BalanceAt(ctx, accountID, currency, inclusive(asOf))
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Balance{
+		AccountID: account.ID,
+		Currency:  currency,
+		Balance:   balance,
+		AsOf:      asOf.UTC(),
+	}, nil
+}
+
+// BalancesAsOf returns the balance of every account pocket at the given instant, as
+// needed for month-end reporting.
+func (l *LedgerService) BalancesAsOf(ctx context.Context, asOf time.Time) ([]domain.Balance, error) {
+	balances, err := l.repo.BalancesAt(ctx, inclusive(asOf))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range balances {
+		balances[i].AsOf = asOf.UTC()
+	}
+
+	return balances, nil
+}
+
+// TakeSnapshots stores the balance of every account pocket at the given instant so
+// that later point-in-time queries only sum the entries made after it.
+func (l *LedgerService) TakeSnapshots(ctx context.Context, at time.Time) (int64, error) {
+	return l.repo.TakeSnapshots(ctx, at.UTC())
+}
+
+// pocketOf returns the account and the currency of the requested pocket,
+// falling back to the base pocket when no currency is given.
+func (l *LedgerService) pocketOf(accountID uuid.UUID, currency string) (*domain.Account, string, error) {
+	account, err := l.accounts.Get(accountID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if currency == "" {
+		return account, account.Currency, nil
+	}
+	if _, ok := account.Pocket(currency); !ok {
+		return nil, "", utils.ErrPocketNotFound
+	}
+
+	return account, currency, nil
+}
+
+// inclusive turns an instant into the exclusive upper bound the ledger
+// queries expect. Timestamps are stored with microsecond precision.
+func inclusive(asOf time.Time) time.Time {
+	return asOf.UTC().Truncate(time.Microsecond).Add(time.Microsecond)
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/ports"
+)
+
+// RateLimitService takes tokens from the buckets of the rate limits, kept in
+// memory for a single instance or in the database to be shared by several.
+type RateLimitService struct {
+	repo     ports.RateLimitRepository
+	accounts ports.AccountRepository
+	now      func() time.Time
+}
+
+func NewRateLimitService(repo ports.RateLimitRepository, accounts ports.AccountRepository) *RateLimitService {
+	return &RateLimitService{
+		repo,
+		accounts,
+		time.Now,
+	}
+}
+
+// Take takes a token from the bucket under key, which limit refills.
+func (l *RateLimitService) Take(ctx context.Context, key string, limit domain.RateLimit) (domain.RateDecision, error) {
+	return l.repo.Take(ctx, key, limit, l.now())
+}
+
+// Limit takes a token from the buckets of route that apply to a request of
+// caller from accountID, uuid.Nil when it names no account. Only accounts
+// that exist have a bucket, so that made up ids cannot fill the store. It
+// returns the decision of the bucket with the fewest requests left, nil when
+// no bucket applies, and the errors of the buckets that could not be read,
+// which are skipped.
+func (l *RateLimitService) Limit(ctx context.Context, route domain.RouteLimit, caller string, accountID uuid.UUID) (*domain.RateDecision, error) {
+	var buckets []string
+	var limits []domain.RateLimit
+	if route.Client.Enabled() {
+		buckets = append(buckets, route.Name+":client:"+caller)
+		limits = append(limits, route.Client)
+	}
+	if route.Account.Enabled() && accountID != uuid.Nil {
+		if _, err := l.accounts.Get(accountID); err == nil {
+			buckets = append(buckets, route.Name+":account:"+accountID.String())
+			limits = append(limits, route.Account)
+		}
+	}
+
+	var tightest *domain.RateDecision
+	var errs []error
+	for i, key := range buckets {
+		decision, err := l.Take(ctx, key, limits[i])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rate limit %s: %w", key, err))
+			continue
+		}
+		if tightest == nil || !decision.Allowed || decision.Remaining < tightest.Remaining {
+			tightest = &decision
+		}
+		if !decision.Allowed {
+			break
+		}
+	}
+	return tightest, errors.Join(errs...)
+}
+
+// PurgeBuckets forgets the buckets no request took a token from for idle.
+// Given the longest period of the limits, they are full again, as buckets
+// that are made afresh are.
+func (l *RateLimitService) PurgeBuckets(ctx context.Context, idle time.Duration) error {
+	return l.repo.PurgeBuckets(ctx, l.now().Add(-idle))
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
 	"github.com/petrostrak/agile-transfer/internal/core/domain"
 	"github.com/petrostrak/agile-transfer/internal/core/ports"
 	"github.com/petrostrak/agile-transfer/utils"
@@ -21,6 +23,9 @@ func NewAccountService(repo ports.AccountRepository) *AccountService {
 }
 
 func (a *AccountService) Insert(acc *domain.Account) error {
+	if err := validateCurrency(&acc.Currency, acc.Balance); err != nil {
+		return err
+	}
 	return a.repo.Insert(acc)
 }
 
@@ -29,11 +34,21 @@ func (a *AccountService) Get(id uuid.UUID) (*domain.Account, error) {
 }
 
 func (a *AccountService) Update(account *domain.Account) error {
+	if err := validateCurrency(&account.Currency, account.Balance); err != nil {
+		return err
+	}
 	return a.repo.Update(account)
 }
 
-func (a *AccountService) Delete(id uuid.UUID) error {
-	return a.repo.Delete(id)
+// Delete removes the account, provided it is still at the given version.
+func (a *AccountService) Delete(id uuid.UUID, version int) error {
+	return a.repo.Delete(id, version)
+}
+
+// SetFrozen freezes the account, stopping the transfers from and to it, or
+// unfreezes it.
+func (a *AccountService) SetFrozen(id uuid.UUID, frozen bool) (*domain.Account, error) {
+	return a.repo.SetFrozen(id, frozen)
 }
 
 func (a *AccountService) GetAll(ctx context.Context) ([]domain.Account, error) {
@@ -41,15 +56,31 @@ func (a *AccountService) GetAll(ctx context.Context) ([]domain.Account, error) {
 }
 
 type TransferService struct {
-	repo ports.TransferRepository
+	repo      ports.TransferRepository
+	customers ports.CustomerRepository
+	convert   RateProvider
+	policy    domain.ApprovalPolicy
+	feed      *transferFeed
 }
 
-func NewTransferService(repo ports.TransferRepository) *TransferService {
+func NewTransferService(repo ports.TransferRepository, customers ports.CustomerRepository, convert RateProvider, policy domain.ApprovalPolicy) *TransferService {
 	return &TransferService{
 		repo,
+		customers,
+		convert,
+		policy,
+		newTransferFeed(),
 	}
 }
 
+// Subscribe returns a channel that receives the transfers made from now on,
+// and a function that ends the subscription. The channel is closed when the
+// subscription ends, or when more than buffer transfers are waiting to be
+// received.
+func (t *TransferService) Subscribe(buffer int) (<-chan domain.Transfer, func()) {
+	return t.feed.subscribe(buffer)
+}
+
 func (t *TransferService) Insert(ctx context.Context, tx domain.Transfer) (domain.Transfer, error) {
 	return t.repo.Insert(ctx, tx)
 }
@@ -62,34 +93,218 @@ func (t *TransferService) GetAll() ([]domain.Transfer, error) {
 	return t.repo.GetAll()
 }
 
-func (t *TransferService) ExecTx(ctx context.Context, fn func() error) error {
-	return t.repo.ExecTx(ctx, fn)
-}
+// TransferTx moves arg.Amount out of the source pocket and credits its value
+// in the target currency, at the current rate when the currencies differ.
 func (t *TransferService) TransferTx(ctx context.Context, arg domain.TransferTxParams) (*domain.TransferTxResult, error) {
-	if arg.SourceCurrency != arg.TargetCurrency {
-		convertedAmount, err := utils.CurrencyConvertion(arg.SourceCurrency, arg.TargetCurrency, arg.AmountToTransfer)
+	if err := validateCurrency(&arg.Amount.Currency, arg.Amount.Amount); err != nil {
+		return nil, err
+	}
+	target, err := currency.Lookup(arg.TargetCurrency)
+	if err != nil {
+		return nil, err
+	}
+	arg.TargetCurrency = target.Code
+
+	arg.Credit = arg.Amount
+	if arg.Amount.Currency != arg.TargetCurrency {
+		convertedAmount, err := t.convert(arg.Amount.Currency, arg.TargetCurrency, arg.Amount.Amount)
 		if err != nil {
 			return nil, utils.ErrCurrencyConvertion
 		}
-		arg.AmountToTransfer = convertedAmount
+		arg.Credit = domain.NewMoney(convertedAmount, arg.TargetCurrency)
 	}
-	if arg.SourceBalance.LessThan(arg.AmountToTransfer) {
+
+	insufficient, err := arg.SourceBalance.LessThan(arg.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if insufficient {
 		return nil, utils.ErrInsufficientBalance
 	}
-	return t.repo.TransferTx(ctx, arg)
+
+	if arg.Audit != nil {
+		arg.Audit.Action, arg.Audit.OccurredAt = domain.AuditTransferCreated, time.Now().UTC()
+	}
+
+	result, err := t.repo.TransferTx(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	t.feed.publish(result.Transfer)
+
+	return result, nil
 }
 
-func (t *TransferService) AddAccountBalance(ctx context.Context, id uuid.UUID, amount decimal.Decimal) (domain.Account, error) {
-	return t.repo.AddAccountBalance(ctx, id, amount)
+// Transfer moves amount out of the sourceCurrency pocket of the source
+// account and credits its value to the targetCurrency pocket of the target
+// account. Empty currencies select the base pockets. Transfers the approval
+// policy holds fail with utils.ErrApprovalRequired.
+func (t *TransferService) Transfer(ctx context.Context, sourceAccountID, targetAccountID uuid.UUID, amount decimal.Decimal, sourceCurrency, targetCurrency string) (*domain.TransferTxResult, error) {
+	return t.transfer(ctx, sourceAccountID, targetAccountID, amount, sourceCurrency, targetCurrency, false, nil)
 }
 
-func (t *TransferService) AddMoney(ctx context.Context, sourceAccountID uuid.UUID, sourceAccountAmount decimal.Decimal, targetAccountID uuid.UUID, targetAccountAmount decimal.Decimal) (sourceAccount, targetAccount domain.Account, err error) {
-	return t.repo.AddMoney(ctx, sourceAccountID, sourceAccountAmount, targetAccountID, targetAccountAmount)
+// TransferApproved makes a transfer like Transfer once it has been approved,
+// recording audit with it.
+func (t *TransferService) TransferApproved(ctx context.Context, sourceAccountID, targetAccountID uuid.UUID, amount decimal.Decimal, sourceCurrency, targetCurrency string, audit *domain.AuditEntry) (*domain.TransferTxResult, error) {
+	return t.transfer(ctx, sourceAccountID, targetAccountID, amount, sourceCurrency, targetCurrency, true, audit)
 }
 
+func (t *TransferService) transfer(ctx context.Context, sourceAccountID, targetAccountID uuid.UUID, amount decimal.Decimal, sourceCurrency, targetCurrency string, approved bool, audit *domain.AuditEntry) (*domain.TransferTxResult, error) {
+	accounts, err := t.ValidateAccounts(ctx, sourceAccountID, targetAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceCurrency = currency.Normalize(sourceCurrency)
+	targetCurrency = currency.Normalize(targetCurrency)
+	if sourceCurrency == "" {
+		sourceCurrency = accounts[0].Currency
+	}
+	if targetCurrency == "" {
+		targetCurrency = accounts[1].Currency
+	}
+
+	source, ok := accounts[0].Pocket(sourceCurrency)
+	if !ok {
+		return nil, utils.ErrPocketNotFound
+	}
+	if _, ok := accounts[1].Pocket(targetCurrency); !ok {
+		return nil, utils.ErrPocketNotFound
+	}
+	if !approved && t.policy.TransferNeedsApproval(domain.NewMoney(amount, sourceCurrency)) {
+		return nil, utils.ErrApprovalRequired
+	}
+
+	return t.TransferTx(ctx, domain.TransferTxParams{
+		SourceAccountID: sourceAccountID,
+		TargetAccountID: targetAccountID,
+		SourceBalance:   domain.NewMoney(source.Balance, source.Currency),
+		Amount:          domain.NewMoney(amount, sourceCurrency),
+		TargetCurrency:  targetCurrency,
+		Audit:           audit,
+	})
+}
+
+// Reverse sends the money of the transfer with the given id back: what it
+// credited is taken out of the target pocket and what it debited is put back
+// into the source pocket, whatever the rate is now. The reversal has an id
+// derived from that of the transfer, so that a transfer is reversed at most
+// once; reversing it again fails with repository.ErrTransferExists.
+func (t *TransferService) Reverse(ctx context.Context, id uuid.UUID) (*domain.TransferTxResult, error) {
+	transfer, err := t.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := t.repo.ValidateAccounts(ctx, transfer.TargetAccountID, transfer.SourceAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if accounts[0].Frozen || accounts[1].Frozen {
+		return nil, utils.ErrAccountFrozen
+	}
+	if accounts[0].ID != transfer.TargetAccountID {
+		accounts[0], accounts[1] = accounts[1], accounts[0]
+	}
+
+	source, ok := accounts[0].Pocket(transfer.Credited.Currency)
+	if !ok {
+		return nil, utils.ErrPocketNotFound
+	}
+	if source.Balance.LessThan(transfer.Credited.Amount) {
+		return nil, utils.ErrInsufficientBalance
+	}
+
+	result, err := t.repo.TransferTx(ctx, domain.TransferTxParams{
+		TransferID:      uuid.NewSHA1(transfer.ID, []byte("reversal")),
+		SourceAccountID: transfer.TargetAccountID,
+		TargetAccountID: transfer.SourceAccountID,
+		SourceBalance:   domain.NewMoney(source.Balance, source.Currency),
+		Amount:          transfer.Credited,
+		TargetCurrency:  transfer.Amount.Currency,
+		Credit:          transfer.Amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	t.feed.publish(result.Transfer)
+
+	return result, nil
+}
+
+func (t *TransferService) AddAccountBalance(ctx context.Context, id uuid.UUID, amount decimal.Decimal) (domain.Account, error) {
+	return t.repo.AddAccountBalance(ctx, id, amount)
+}
+
+// ValidateAccounts returns the source and target account, in that order, if
+// money is allowed to move between them: neither is frozen and the customer
+// of the source lets it pay the target.
 func (t *TransferService) ValidateAccounts(ctx context.Context, sourceAccountID, targetAccountID uuid.UUID) ([]domain.Account, error) {
 	if sourceAccountID == targetAccountID {
 		return nil, utils.ErrIdenticalAccount
 	}
-	return t.repo.ValidateAccounts(ctx, sourceAccountID, targetAccountID)
+
+	accounts, err := t.repo.ValidateAccounts(ctx, sourceAccountID, targetAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if accounts[0].ID != sourceAccountID {
+		accounts[0], accounts[1] = accounts[1], accounts[0]
+	}
+	if accounts[0].Frozen || accounts[1].Frozen {
+		return nil, utils.ErrAccountFrozen
+	}
+
+	if err := t.checkTarget(ctx, accounts[0], accounts[1]); err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// checkTarget enforces the transfer restrictions of the customer holding the
+// source account: only accounts of the same customer or its beneficiaries can
+// receive money when RestrictTransfers is set.
+func (t *TransferService) checkTarget(ctx context.Context, source, target domain.Account) error {
+	if source.CustomerID == nil {
+		return nil
+	}
+
+	customer, err := t.customers.Get(ctx, *source.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	if customer.Status != domain.CustomerActive {
+		return utils.ErrCustomerNotActive
+	}
+
+	if !customer.RestrictTransfers {
+		return nil
+	}
+
+	if target.CustomerID != nil && *target.CustomerID == customer.ID {
+		return nil
+	}
+
+	allowed, err := t.customers.IsBeneficiary(ctx, customer.ID, target.ID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return utils.ErrTargetNotAllowed
+	}
+
+	return nil
+}
+
+// validateCurrency normalizes the currency code in place and checks that it
+// is enabled and that the amount fits its minor unit.
+func validateCurrency(code *string, amount decimal.Decimal) error {
+	c, err := currency.Lookup(*code)
+	if err != nil {
+		return err
+	}
+	*code = c.Code
+	return c.Validate(amount)
 }
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/ports"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+// secretPrefix starts every signing secret, so that secrets are easy to spot
+// in logs and by secret scanners.
+const secretPrefix = "ags_"
+
+// SigningService keeps the secrets clients sign their requests with and
+// checks the signatures. Signing is optional: only the clients holding an
+// active secret must sign.
+type SigningService struct {
+	repo ports.SigningRepository
+	now  func() time.Time
+}
+
+func NewSigningService(repo ports.SigningRepository) *SigningService {
+	return &SigningService{
+		repo,
+		time.Now,
+	}
+}
+
+// Issue creates a secret for the client and returns it. It is kept, but
+// cannot be read back through the API.
+func (s *SigningService) Issue(ctx context.Context, secret *domain.SigningSecret) (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	secret.Secret = secretPrefix + base64.RawURLEncoding.EncodeToString(random)
+	secret.ExpiresAt, secret.RevokedAt = nil, nil
+
+	if err := s.repo.Insert(ctx, secret); err != nil {
+		return "", err
+	}
+	return secret.Secret, nil
+}
+
+// Rotate issues a secret for the client of the secret with the given id,
+// which keeps working for overlap so that the client can switch to the new
+// one without downtime. Requests are checked against both meanwhile.
+func (s *SigningService) Rotate(ctx context.Context, id uuid.UUID, overlap time.Duration) (*domain.SigningSecret, string, error) {
+	old, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if !old.Active(s.now()) {
+		return nil, "", utils.ErrSigningInactive
+	}
+
+	secret := &domain.SigningSecret{Client: old.Client}
+	plain, err := s.Issue(ctx, secret)
+	if err != nil {
+		return nil, "", err
+	}
+
+	expires := s.now().Add(overlap)
+	if old.ExpiresAt == nil || expires.Before(*old.ExpiresAt) {
+		if err := s.repo.Expire(ctx, id, expires); err != nil {
+			return nil, "", err
+		}
+	}
+	return secret, plain, nil
+}
+
+// Revoke stops the secret from working at once. Once a client has no active
+// secret its requests no longer need to be signed.
+func (s *SigningService) Revoke(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Revoke(ctx, id, s.now())
+}
+
+func (s *SigningService) Get(ctx context.Context, id uuid.UUID) (*domain.SigningSecret, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *SigningService) GetAll(ctx context.Context) ([]domain.SigningSecret, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// SignedRequest is what a request of a client carries to be checked: its
+// signature headers and what they sign.
+type SignedRequest struct {
+	Client    string
+	Method    string
+	URI       string
+	Body      []byte
+	Signature string
+	Timestamp string
+	Nonce     string
+}
+
+// Verify checks the signature of a request of a client holding an active
+// secret. Requests of other clients need not be signed, but are refused if
+// they are. A signature is good when it matches one of the active secrets of
+// the client, its timestamp is within domain.SignatureTolerance and its
+// nonce has not been seen in that time.
+func (s *SigningService) Verify(ctx context.Context, req SignedRequest) error {
+	secrets, err := s.repo.GetByClient(ctx, req.Client)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+
+	var active []string
+	for i := range secrets {
+		if secrets[i].Active(now) {
+			active = append(active, secrets[i].Secret)
+		}
+	}
+
+	switch {
+	case req.Signature == "" && len(active) == 0:
+		return nil
+	case req.Signature == "":
+		return utils.ErrSignatureRequired
+	case len(active) == 0:
+		return utils.ErrInvalidSignature
+	}
+
+	signature, ok := strings.CutPrefix(req.Signature, "v1=")
+	timestamp, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if !ok || err != nil || req.Nonce == "" || len(req.Nonce) > 128 {
+		return utils.ErrInvalidSignature
+	}
+
+	matched := false
+	for _, secret := range active {
+		expected := domain.Signature(secret, req.Method, req.URI, timestamp, req.Nonce, req.Body)
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			matched = true
+		}
+	}
+	if !matched {
+		return utils.ErrInvalidSignature
+	}
+
+	signedAt := time.Unix(timestamp, 0)
+	if signedAt.Before(now.Add(-domain.SignatureTolerance)) || signedAt.After(now.Add(domain.SignatureTolerance)) {
+		return utils.ErrStaleSignature
+	}
+
+	// A nonce need only be remembered until its timestamp goes stale.
+	fresh, err := s.repo.UseNonce(ctx, req.Client, req.Nonce, now, signedAt.Add(domain.SignatureTolerance))
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return utils.ErrReplayedSignature
+	}
+	return nil
+}
+
+// PurgeNonces forgets the nonces that can no longer be replayed.
+func (s *SigningService) PurgeNonces(ctx context.Context) error {
+	return s.repo.PurgeNonces(ctx, s.now())
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/ports"
+	"github.com/petrostrak/agile-transfer/internal/jwt"
+	"github.com/petrostrak/agile-transfer/utils"
+)
+
+type UserService struct {
+	verifier  *jwt.Verifier
+	customers ports.CustomerRepository
+	roles     ports.RoleRepository
+}
+
+// NewUserService accepts the bearer tokens verifier accepts. With a nil
+// verifier every token is refused.
+func NewUserService(verifier *jwt.Verifier, customers ports.CustomerRepository, roles ports.RoleRepository) *UserService {
+	return &UserService{
+		verifier,
+		customers,
+		roles,
+	}
+}
+
+// Authenticate returns the user a bearer token was issued to, with the
+// customer its subject belongs to and the permissions of the roles in its
+// roles claim. Roles that do not exist are ignored.
+func (u *UserService) Authenticate(ctx context.Context, token string) (*domain.User, error) {
+	if u.verifier == nil {
+		return nil, fmt.Errorf("%w: bearer tokens are not accepted", utils.ErrInvalidToken)
+	}
+
+	claims, err := u.verifier.Verify(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidToken, err)
+	}
+
+	user := &domain.User{Subject: claims.Subject}
+
+	if len(claims.Roles) > 0 {
+		roles, err := u.roles.GetByNames(ctx, claims.Roles)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range roles {
+			user.Roles = append(user.Roles, role.Name)
+			for _, permission := range role.Permissions {
+				if !user.Can(permission) {
+					user.Permissions = append(user.Permissions, permission)
+				}
+			}
+		}
+	}
+
+	customer, err := u.customers.GetBySubject(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if customer != nil {
+		user.CustomerID = &customer.ID
+	}
+	return user, nil
+}
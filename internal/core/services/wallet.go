@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/ports"
+	"github.com/petrostrak/agile-transfer/utils"
+	"github.com/shopspring/decimal"
+)
+
+// RateProvider converts an amount from one currency to another.
+type RateProvider func(from, to string, amount decimal.Decimal) (decimal.Decimal, error)
+
+type WalletService struct {
+	repo     ports.WalletRepository
+	accounts ports.AccountRepository
+	convert  RateProvider
+}
+
+func NewWalletService(repo ports.WalletRepository, accounts ports.AccountRepository, convert RateProvider) *WalletService {
+	return &WalletService{
+		repo,
+		accounts,
+		convert,
+	}
+}
+
+func (w *WalletService) Pockets(ctx context.Context, accountID uuid.UUID) ([]domain.Pocket, error) {
+	if _, err := w.accounts.Get(accountID); err != nil {
+		return nil, err
+	}
+	return w.repo.GetPockets(ctx, accountID)
+}
+
+// OpenPocket adds an empty pocket in the given currency to an account.
+func (w *WalletService) OpenPocket(ctx context.Context, accountID uuid.UUID, code string) (*domain.Pocket, error) {
+	c, err := currency.Lookup(code)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.accounts.Get(accountID); err != nil {
+		return nil, err
+	}
+	return w.repo.OpenPocket(ctx, accountID, c.Code)
+}
+
+// Convert moves conversion.Amount out of the pocket of its currency and
+// credits its value in the currency of conversion.Converted, at the current
+// rate, to the pocket of that currency.
+func (w *WalletService) Convert(ctx context.Context, conversion *domain.Conversion) error {
+	from, err := currency.Lookup(conversion.Amount.Currency)
+	if err != nil {
+		return err
+	}
+	to, err := currency.Lookup(conversion.Converted.Currency)
+	if err != nil {
+		return err
+	}
+	conversion.Amount.Currency, conversion.Converted.Currency = from.Code, to.Code
+
+	if !conversion.Amount.IsPositive() {
+		return utils.ErrInvalidAmount
+	}
+	if err := from.Validate(conversion.Amount.Amount); err != nil {
+		return err
+	}
+	if from.Code == to.Code {
+		return utils.ErrSameCurrency
+	}
+
+	account, err := w.accounts.Get(conversion.AccountID)
+	if err != nil {
+		return err
+	}
+
+	pocket, ok := account.Pocket(from.Code)
+	if !ok {
+		return utils.ErrPocketNotFound
+	}
+	if _, ok := account.Pocket(to.Code); !ok {
+		return utils.ErrPocketNotFound
+	}
+	insufficient, err := domain.NewMoney(pocket.Balance, pocket.Currency).LessThan(conversion.Amount)
+	if err != nil {
+		return err
+	}
+	if insufficient {
+		return utils.ErrInsufficientBalance
+	}
+
+	converted, err := w.convert(from.Code, to.Code, conversion.Amount.Amount)
+	if err != nil {
+		return utils.ErrCurrencyConvertion
+	}
+	conversion.Converted = domain.NewMoney(to.Round(converted), to.Code)
+
+	return w.repo.Convert(ctx, conversion)
+}
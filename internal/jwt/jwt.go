@@ -0,0 +1,230 @@
+// Package jwt verifies the JSON Web Tokens issued by an OpenID Connect
+// identity provider against the provider's key set. Only the asymmetric RS
+// and ES algorithms are accepted.
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformed        = errors.New("malformed token")
+	ErrUnsupportedAlg   = errors.New("unsupported signing algorithm")
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrExpired          = errors.New("token has expired")
+	ErrNotYetValid      = errors.New("token is not valid yet")
+	ErrInvalidIssuer    = errors.New("token has another issuer")
+	ErrInvalidAudience  = errors.New("token is meant for another audience")
+)
+
+// leeway is how far the clocks of the service and the identity provider may
+// drift apart.
+const leeway = time.Minute
+
+// Claims are the claims of a verified token the service relies on.
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	// Roles are read from the roles claim, a list or a single string.
+	Roles []string
+}
+
+// Verifier checks the signature and claims of tokens. An empty issuer or
+// audience is not checked.
+type Verifier struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+func NewVerifier(keys *KeySet, issuer, audience string) *Verifier {
+	return &Verifier{
+		keys,
+		issuer,
+		audience,
+		time.Now,
+	}
+}
+
+// algorithms are the accepted signing algorithms, with their hash.
+var algorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// Verify returns the claims of token if it is signed by a key of the set and
+// is valid now. Tokens must have a subject and an expiry.
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	hash, ok := algorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlg, header.Alg)
+	}
+
+	key, err := v.keys.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if key.alg != "" && key.alg != header.Alg {
+		return nil, fmt.Errorf("%w: key %q is for %s", ErrUnsupportedAlg, header.Kid, key.alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	h := hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	if !verifySignature(key.key, header.Alg, hash, h.Sum(nil), signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	var payload struct {
+		Iss   string       `json:"iss"`
+		Sub   string       `json:"sub"`
+		Aud   stringList   `json:"aud"`
+		Exp   *json.Number `json:"exp"`
+		Nbf   *json.Number `json:"nbf"`
+		Roles stringList   `json:"roles"`
+	}
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return nil, err
+	}
+	if payload.Sub == "" || payload.Exp == nil {
+		return nil, fmt.Errorf("%w: sub and exp are required", ErrMalformed)
+	}
+
+	now := v.now()
+	exp, err := numericDate(*payload.Exp)
+	if err != nil {
+		return nil, err
+	}
+	if !now.Before(exp.Add(leeway)) {
+		return nil, ErrExpired
+	}
+	if payload.Nbf != nil {
+		nbf, err := numericDate(*payload.Nbf)
+		if err != nil {
+			return nil, err
+		}
+		if now.Add(leeway).Before(nbf) {
+			return nil, ErrNotYetValid
+		}
+	}
+
+	if v.issuer != "" && payload.Iss != v.issuer {
+		return nil, ErrInvalidIssuer
+	}
+	if v.audience != "" && !contains(payload.Aud, v.audience) {
+		return nil, ErrInvalidAudience
+	}
+
+	return &Claims{
+		Issuer:    payload.Iss,
+		Subject:   payload.Sub,
+		Audience:  payload.Aud,
+		ExpiresAt: exp,
+		Roles:     payload.Roles,
+	}, nil
+}
+
+func verifySignature(key crypto.PublicKey, alg string, hash crypto.Hash, digest, signature []byte) bool {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") && rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		// ES signatures are r and s, each padded to the size of the curve.
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(alg, "ES") || len(signature) != 2*size || key.Curve.Params().BitSize != esCurveBits[alg] {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, digest, r, s)
+	default:
+		return false
+	}
+}
+
+// esCurveBits is the curve each ES algorithm signs with.
+var esCurveBits = map[string]int{
+	"ES256": 256,
+	"ES384": 384,
+	"ES512": 521,
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformed
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return nil
+}
+
+func numericDate(n json.Number) (time.Time, error) {
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid date %s", ErrMalformed, n)
+	}
+	return time.Unix(int64(f), 0), nil
+}
+
+// stringList is a claim given either as a string or as a list of strings.
+type stringList []string
+
+func (s *stringList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*s = []string{one}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*s = many
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
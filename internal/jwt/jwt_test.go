@@ -0,0 +1,213 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	now        = time.Date(2023, time.June, 30, 12, 0, 0, 0, time.UTC)
+	testECKey  = mustECKey(elliptic.P256())
+	testRSAKey = mustRSAKey()
+)
+
+func mustECKey(curve elliptic.Curve) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func mustRSAKey() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// writeKeySet writes the key set of the given keys to a file and returns its
+// path.
+func writeKeySet(t *testing.T, keys map[string]crypto.PublicKey) string {
+	t.Helper()
+
+	data, err := MarshalKeySet(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestVerifier(t *testing.T) *Verifier {
+	t.Helper()
+
+	keys, err := NewKeySet(writeKeySet(t, map[string]crypto.PublicKey{"ec": &testECKey.PublicKey, "rsa": &testRSAKey.PublicKey}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVerifier(keys, "https://id.example.com", "agile-transfer")
+	v.now = func() time.Time { return now }
+	return v
+}
+
+func sign(t *testing.T, key crypto.Signer, kid string, claims map[string]any) string {
+	t.Helper()
+
+	token, err := Sign(key, kid, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func claims(overrides map[string]any) map[string]any {
+	c := map[string]any{
+		"iss": "https://id.example.com",
+		"sub": "user-1",
+		"aud": "agile-transfer",
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(c, k)
+			continue
+		}
+		c[k] = v
+	}
+	return c
+}
+
+func Test_Verify(t *testing.T) {
+	v := newTestVerifier(t)
+	other := mustECKey(elliptic.P256())
+
+	testCases := []struct {
+		name     string
+		token    string
+		expected error
+	}{
+		{"ES256", sign(t, testECKey, "ec", claims(nil)), nil},
+		{"RS256", sign(t, testRSAKey, "rsa", claims(nil)), nil},
+		{"audience in a list", sign(t, testECKey, "ec", claims(map[string]any{"aud": []string{"other", "agile-transfer"}})), nil},
+		{"expired within leeway", sign(t, testECKey, "ec", claims(map[string]any{"exp": now.Add(-30 * time.Second).Unix()})), nil},
+		{"expired", sign(t, testECKey, "ec", claims(map[string]any{"exp": now.Add(-time.Hour).Unix()})), ErrExpired},
+		{"not yet valid", sign(t, testECKey, "ec", claims(map[string]any{"nbf": now.Add(time.Hour).Unix()})), ErrNotYetValid},
+		{"no expiry", sign(t, testECKey, "ec", claims(map[string]any{"exp": nil})), ErrMalformed},
+		{"no subject", sign(t, testECKey, "ec", claims(map[string]any{"sub": nil})), ErrMalformed},
+		{"other issuer", sign(t, testECKey, "ec", claims(map[string]any{"iss": "https://evil.example.com"})), ErrInvalidIssuer},
+		{"other audience", sign(t, testECKey, "ec", claims(map[string]any{"aud": "other"})), ErrInvalidAudience},
+		{"unknown key", sign(t, testECKey, "unknown", claims(nil)), ErrUnknownKey},
+		{"signed by another key", sign(t, other, "ec", claims(nil)), ErrInvalidSignature},
+		{"key of another type", sign(t, testECKey, "rsa", claims(nil)), ErrInvalidSignature},
+		{"not a token", "token", ErrMalformed},
+	}
+
+	for _, tt := range testCases {
+		_, err := v.Verify(tt.token)
+		if !errors.Is(err, tt.expected) {
+			t.Errorf("%s: expected %v but got %v", tt.name, tt.expected, err)
+		}
+	}
+}
+
+func Test_VerifyClaims(t *testing.T) {
+	v := newTestVerifier(t)
+
+	c, err := v.Verify(sign(t, testECKey, "ec", claims(map[string]any{"roles": []string{"teller", "admin"}})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Subject != "user-1" || !c.ExpiresAt.Equal(now.Add(time.Hour)) || !reflect.DeepEqual(c.Roles, []string{"teller", "admin"}) {
+		t.Errorf("unexpected claims %+v", c)
+	}
+
+	c, err = v.Verify(sign(t, testECKey, "ec", claims(map[string]any{"roles": "admin"})))
+	if err != nil || !reflect.DeepEqual(c.Roles, []string{"admin"}) {
+		t.Errorf("expected a single role to be read as a list but got %+v (%v)", c, err)
+	}
+}
+
+// Test_VerifyRejectsUnsignedTokens checks that tokens cannot choose an
+// algorithm that is not backed by a key of the set.
+func Test_VerifyRejectsUnsignedTokens(t *testing.T) {
+	v := newTestVerifier(t)
+
+	payload := strings.Split(sign(t, testECKey, "ec", claims(nil)), ".")[1]
+	for _, alg := range []string{"none", "HS256"} {
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"` + alg + `","kid":"ec"}`))
+		if _, err := v.Verify(header + "." + payload + "."); !errors.Is(err, ErrUnsupportedAlg) {
+			t.Errorf("%s: expected %v but got %v", alg, ErrUnsupportedAlg, err)
+		}
+	}
+}
+
+func Test_KeySetFromURL(t *testing.T) {
+	keys := map[string]crypto.PublicKey{"ec": &testECKey.PublicKey}
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		data, _ := MarshalKeySet(keys)
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	set, err := NewKeySet(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Now()
+	set.now = func() time.Time { return clock }
+	v := NewVerifier(set, "", "")
+	v.now = func() time.Time { return now }
+
+	if _, err := v.Verify(sign(t, testECKey, "ec", claims(nil))); err != nil {
+		t.Errorf("expected the token to be valid but got %v", err)
+	}
+
+	// A key added by the provider is found once the set may be loaded again.
+	keys["rsa"] = &testRSAKey.PublicKey
+	token := sign(t, testRSAKey, "rsa", claims(nil))
+	if _, err := v.Verify(token); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected the new key to be unknown until the set is refreshed but got %v", err)
+	}
+	clock = clock.Add(refreshInterval)
+	if _, err := v.Verify(token); err != nil {
+		t.Errorf("expected the new key to be found but got %v", err)
+	}
+	if fetches.Load() != 2 {
+		t.Errorf("expected the set to be fetched twice but it was fetched %d times", fetches.Load())
+	}
+}
+
+func Test_NewKeySet(t *testing.T) {
+	if _, err := NewKeySet(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected a missing file to fail")
+	}
+
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewKeySet(writeKeySet(t, map[string]crypto.PublicKey{"weak": &weak.PublicKey})); err == nil {
+		t.Error("expected a 1024-bit RSA key to be refused")
+	}
+}
@@ -0,0 +1,222 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// refreshInterval is how often at most the key set is loaded again when a
+// token names a key it does not hold, so that keys added by the identity
+// provider are picked up without letting bad tokens trigger a load each.
+const refreshInterval = time.Minute
+
+// KeySet is a JSON Web Key Set, read from a file, fetched from a URL or given
+// as is.
+type KeySet struct {
+	source string
+	client *http.Client
+
+	mu     sync.Mutex
+	keys   map[string]jwk
+	loaded time.Time
+	now    func() time.Time
+}
+
+// jwk is a public key of the set, with the algorithm it is restricted to, if
+// any.
+type jwk struct {
+	key crypto.PublicKey
+	alg string
+}
+
+// NewKeySet loads the key set at source, which is either an http(s) URL or
+// the path of a file.
+func NewKeySet(source string) (*KeySet, error) {
+	k := &KeySet{
+		source: source,
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+
+	if err := k.load(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// ParseKeySet returns the key set in data. Unlike the sets of NewKeySet, it is
+// never loaded again.
+func ParseKeySet(data []byte) (*KeySet, error) {
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return nil, err
+	}
+	return &KeySet{keys: keys, now: time.Now}, nil
+}
+
+// key returns the key with the given id, loading the set again if it does not
+// hold it and was not loaded recently.
+func (k *KeySet) key(kid string) (jwk, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if key, ok := k.keys[kid]; ok {
+		return key, nil
+	}
+	if k.source != "" && k.now().Sub(k.loaded) >= refreshInterval {
+		if err := k.load(); err != nil {
+			return jwk{}, err
+		}
+		if key, ok := k.keys[kid]; ok {
+			return key, nil
+		}
+	}
+	return jwk{}, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+func (k *KeySet) load() error {
+	data, err := k.read()
+	if err != nil {
+		return fmt.Errorf("loading key set from %s: %w", k.source, err)
+	}
+
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return fmt.Errorf("loading key set from %s: %w", k.source, err)
+	}
+
+	k.keys = keys
+	k.loaded = k.now()
+	return nil
+}
+
+func (k *KeySet) read() ([]byte, error) {
+	if !strings.HasPrefix(k.source, "http://") && !strings.HasPrefix(k.source, "https://") {
+		return os.ReadFile(k.source)
+	}
+
+	res, err := k.client.Get(k.source)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", res.Status)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, 1<<20))
+}
+
+// parseKeySet reads the signing keys of a JSON Web Key Set, skipping those of
+// a type the service does not verify with.
+func parseKeySet(data []byte) (map[string]jwk, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			Alg string `json:"alg"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]jwk)
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var key crypto.PublicKey
+		var err error
+		switch k.Kty {
+		case "RSA":
+			key, err = rsaKey(k.N, k.E)
+		case "EC":
+			key, err = ecKey(k.Crv, k.X, k.Y)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = jwk{key, k.Alg}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no RSA or EC signing keys found")
+	}
+	return keys, nil
+}
+
+func rsaKey(n, e string) (*rsa.PublicKey, error) {
+	modulus, err := decodeInt(n)
+	if err != nil {
+		return nil, err
+	}
+	exponent, err := decodeInt(e)
+	if err != nil {
+		return nil, err
+	}
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+	if modulus.BitLen() < 2048 {
+		return nil, errors.New("RSA keys must be at least 2048 bits long")
+	}
+	return &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}, nil
+}
+
+func ecKey(crv, x, y string) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", crv)
+	}
+
+	px, err := decodeInt(x)
+	if err != nil {
+		return nil, err
+	}
+	py, err := decodeInt(y)
+	if err != nil {
+		return nil, err
+	}
+	if !curve.IsOnCurve(px, py) {
+		return nil, errors.New("point is not on the curve")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: px, Y: py}, nil
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,124 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Sign issues a token with the given claims, signed with RS256 by an RSA key
+// or with the ES algorithm of the curve of an EC key. The service only
+// verifies tokens; Sign is meant for tests and local key sets.
+func Sign(key crypto.Signer, kid string, claims any) (string, error) {
+	alg, hash, err := signingAlgorithm(key)
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	var signature []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest)
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, digest)
+		if err == nil {
+			size := (key.Curve.Params().BitSize + 7) / 8
+			signature = make([]byte, 2*size)
+			r.FillBytes(signature[:size])
+			s.FillBytes(signature[size:])
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// MarshalKeySet returns the JSON Web Key Set holding the given public keys by
+// their id.
+func MarshalKeySet(keys map[string]crypto.PublicKey) ([]byte, error) {
+	type key struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n,omitempty"`
+		E   string `json:"e,omitempty"`
+		Crv string `json:"crv,omitempty"`
+		X   string `json:"x,omitempty"`
+		Y   string `json:"y,omitempty"`
+	}
+
+	var set struct {
+		Keys []key `json:"keys"`
+	}
+	for kid, k := range keys {
+		switch k := k.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, key{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				N:   encodeInt(k.N, 0),
+				E:   encodeInt(big.NewInt(int64(k.E)), 0),
+			})
+		case *ecdsa.PublicKey:
+			size := (k.Curve.Params().BitSize + 7) / 8
+			set.Keys = append(set.Keys, key{
+				Kty: "EC",
+				Kid: kid,
+				Use: "sig",
+				Crv: k.Curve.Params().Name,
+				X:   encodeInt(k.X, size),
+				Y:   encodeInt(k.Y, size),
+			})
+		default:
+			return nil, fmt.Errorf("unsupported key type %T", k)
+		}
+	}
+	return json.Marshal(set)
+}
+
+func signingAlgorithm(key crypto.Signer) (string, crypto.Hash, error) {
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return "RS256", crypto.SHA256, nil
+	case *ecdsa.PrivateKey:
+		for alg, bits := range esCurveBits {
+			if key.Curve.Params().BitSize == bits {
+				return alg, algorithms[alg], nil
+			}
+		}
+	}
+	return "", 0, errors.New("unsupported key type")
+}
+
+// encodeInt encodes n, left-padded with zeros to size bytes.
+func encodeInt(n *big.Int, size int) string {
+	b := n.Bytes()
+	if len(b) < size {
+		b = append(make([]byte, size-len(b)), b...)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,43 @@
+package tlsconfig
+
+import "crypto/tls"
+
+// ClientSubjects binds API clients to the subjects of the client
+// certificates they connect with under mutual TLS, such as
+// "CN=partner,O=Acme".
+type ClientSubjects struct {
+	clients map[string]string
+	bound   map[string]bool
+}
+
+// NewClientSubjects binds the API client each subject maps to.
+func NewClientSubjects(clients map[string]string) *ClientSubjects {
+	bound := make(map[string]bool)
+	for _, client := range clients {
+		bound[client] = true
+	}
+	return &ClientSubjects{
+		clients,
+		bound,
+	}
+}
+
+// Allows reports whether client may call over the connection of state, nil
+// for a connection without TLS. A certificate mapped to another client is
+// refused, as is a bound client over a connection without one of its
+// certificates; unbound clients over connections with unmapped
+// certificates are allowed.
+func (s *ClientSubjects) Allows(state *tls.ConnectionState, client string) bool {
+	if s == nil || len(s.clients) == 0 {
+		return true
+	}
+
+	mapped, ok := "", false
+	if state != nil && len(state.VerifiedChains) > 0 {
+		mapped, ok = s.clients[state.VerifiedChains[0][0].Subject.String()]
+	}
+	if ok {
+		return mapped == client
+	}
+	return !s.bound[client]
+}
@@ -0,0 +1,238 @@
+// Package tlsconfig builds the TLS configuration of the HTTP server from
+// certificate files, reloading them when they change so that certificates can
+// be renewed without a restart.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options are the files and policies of the server's TLS.
+type Options struct {
+	CertFile string
+	KeyFile  string
+	// MinVersion is the oldest version accepted, tls.VersionTLS12 when zero.
+	MinVersion uint16
+	// CipherSuites are the suites accepted for TLS 1.2, Go's defaults when
+	// nil. The suites of TLS 1.3 are not configurable.
+	CipherSuites []uint16
+	// ClientCAFile, when set, turns on mutual TLS: clients must present a
+	// certificate signed by one of its CAs, unless ClientAuth lets them
+	// connect without one.
+	ClientCAFile string
+	ClientAuth   tls.ClientAuthType
+}
+
+// ParseVersion reads a TLS version written as "1.2" or "1.3".
+func ParseVersion(s string) (uint16, error) {
+	switch s {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("TLS version %q must be 1.2 or 1.3", s)
+	}
+}
+
+// ParseCipherPolicy reads the TLS 1.2 cipher suites of a policy: "default",
+// Go's secure defaults, "strict", only the suites with forward secrecy and
+// authenticated encryption, or a comma-separated list of suite names such as
+// TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256.
+func ParseCipherPolicy(s string) ([]uint16, error) {
+	switch s {
+	case "", "default":
+		return nil, nil
+	case "strict":
+		return []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		}, nil
+	}
+
+	suites := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+
+	var ids []uint16
+	for _, name := range strings.Split(s, ",") {
+		id, ok := suites[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("cipher suite %q is unknown or insecure", strings.TrimSpace(name))
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ParseClientAuth reads whether clients must present a certificate:
+// "require" or "optional", which verifies the certificates presented but lets
+// clients connect without one.
+func ParseClientAuth(s string) (tls.ClientAuthType, error) {
+	switch s {
+	case "", "require":
+		return tls.RequireAndVerifyClientCert, nil
+	case "optional":
+		return tls.VerifyClientCertIfGiven, nil
+	default:
+		return 0, fmt.Errorf("client auth %q must be require or optional", s)
+	}
+}
+
+// Reloader holds the server's certificate and the client CAs, loading them
+// again when their files change.
+type Reloader struct {
+	options Options
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modified  map[string]time.Time
+}
+
+// NewReloader loads the files of options.
+func NewReloader(options Options) (*Reloader, error) {
+	if options.CertFile == "" || options.KeyFile == "" {
+		return nil, errors.New("tls: a certificate and a key file are needed")
+	}
+
+	r := &Reloader{options: options}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Config returns the server configuration, offering the application
+// protocols of nextProtos, such as "h2" for gRPC. Every handshake uses the
+// certificate and client CAs last loaded.
+func (r *Reloader) Config(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: r.minVersion(),
+		NextProtos: nextProtos,
+		// http.Server.ListenAndServeTLS looks for a certificate here.
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.cert, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			config := &tls.Config{
+				MinVersion:   r.minVersion(),
+				CipherSuites: r.options.CipherSuites,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   nextProtos,
+			}
+			if r.clientCAs != nil {
+				config.ClientCAs = r.clientCAs
+				config.ClientAuth = r.options.ClientAuth
+			}
+			return config, nil
+		},
+	}
+}
+
+func (r *Reloader) minVersion() uint16 {
+	if r.options.MinVersion == 0 {
+		return tls.VersionTLS12
+	}
+	return r.options.MinVersion
+}
+
+// Reload loads the files again. When one cannot be loaded, the ones loaded
+// before are kept.
+func (r *Reloader) Reload() error {
+	modified, err := r.modTimes()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.options.CertFile, r.options.KeyFile)
+	if err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.options.ClientCAFile != "" {
+		data, err := os.ReadFile(r.options.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("tls: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("tls: no certificates found in %s", r.options.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.clientCAs, r.modified = &cert, clientCAs, modified
+	return nil
+}
+
+// Changed reports whether any of the files was modified since it was last
+// loaded.
+func (r *Reloader) Changed() bool {
+	modified, err := r.modTimes()
+	if err != nil {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for name, at := range modified {
+		if !at.Equal(r.modified[name]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch reloads the files every interval if they changed, until stop is
+// closed, passing report the error of every reload, nil when it succeeded.
+func (r *Reloader) Watch(interval time.Duration, stop <-chan struct{}, report func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		if r.Changed() {
+			report(r.Reload())
+		}
+	}
+}
+
+func (r *Reloader) modTimes() (map[string]time.Time, error) {
+	modified := make(map[string]time.Time)
+	for _, name := range []string{r.options.CertFile, r.options.KeyFile, r.options.ClientCAFile} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+		modified[name] = info.ModTime()
+	}
+	return modified, nil
+}
@@ -0,0 +1,277 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_ParseVersion(t *testing.T) {
+	if v, err := ParseVersion("1.3"); err != nil || v != tls.VersionTLS13 {
+		t.Errorf("expected TLS 1.3 but got %x (%v)", v, err)
+	}
+	if _, err := ParseVersion("1.1"); err == nil {
+		t.Error("expected TLS 1.1 to be refused")
+	}
+}
+
+func Test_ParseCipherPolicy(t *testing.T) {
+	tests := []struct {
+		policy string
+		count  int
+		valid  bool
+	}{
+		{"default", 0, true},
+		{"strict", 6, true},
+		{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", 2, true},
+		{"TLS_RSA_WITH_RC4_128_SHA", 0, false},
+		{"TLS_MADE_UP", 0, false},
+	}
+	for _, tt := range tests {
+		suites, err := ParseCipherPolicy(tt.policy)
+		if (err == nil) != tt.valid || len(suites) != tt.count {
+			t.Errorf("%s: expected %d suites (valid %t) but got %v (%v)", tt.policy, tt.count, tt.valid, suites, err)
+		}
+	}
+}
+
+func Test_ParseClientAuth(t *testing.T) {
+	if auth, err := ParseClientAuth(""); err != nil || auth != tls.RequireAndVerifyClientCert {
+		t.Errorf("expected client certificates to be required by default but got %v (%v)", auth, err)
+	}
+	if _, err := ParseClientAuth("maybe"); err == nil {
+		t.Error("expected an unknown client auth to be refused")
+	}
+}
+
+// testPKI is a CA with a server certificate and a client certificate it
+// issued, the CA and server ones written to files.
+type testPKI struct {
+	ca, server, client *KeyPair
+	options            Options
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+
+	dir := t.TempDir()
+	p := &testPKI{options: Options{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}}
+
+	var err error
+	if p.ca, err = NewCA("test CA"); err != nil {
+		t.Fatal(err)
+	}
+	if p.client, err = p.ca.Issue(pkix.Name{CommonName: "partner", Organization: []string{"Acme"}}); err != nil {
+		t.Fatal(err)
+	}
+	p.renew(t)
+	if err := os.WriteFile(p.options.ClientCAFile, p.ca.CertPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// renew issues a server certificate and writes it over the files, dating
+// them a second after the last so that the change is seen.
+func (p *testPKI) renew(t *testing.T) {
+	t.Helper()
+
+	var err error
+	if p.server, err = p.ca.Issue(pkix.Name{CommonName: "localhost"}, "127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.server.WriteFiles(p.options.CertFile, p.options.KeyFile); err != nil {
+		t.Fatal(err)
+	}
+	p.touch(t)
+}
+
+func (p *testPKI) touch(t *testing.T) {
+	t.Helper()
+
+	at := time.Now().Add(time.Second)
+	if info, err := os.Stat(p.options.CertFile); err == nil && !info.ModTime().Before(at) {
+		at = info.ModTime().Add(time.Second)
+	}
+	for _, name := range []string{p.options.CertFile, p.options.KeyFile} {
+		if err := os.Chtimes(name, at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// httpClient trusts the CA and presents the client certificate, if with.
+func (p *testPKI) httpClient(with bool) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(p.ca.Cert)
+
+	config := &tls.Config{RootCAs: roots}
+	if with {
+		config.Certificates = []tls.Certificate{{Certificate: [][]byte{p.client.Cert.Raw}, PrivateKey: p.client.Key}}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: config, DisableKeepAlives: true}}
+}
+
+func newTestServer(t *testing.T, reloader *Reloader) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject := ""
+		if len(r.TLS.PeerCertificates) > 0 {
+			subject = r.TLS.PeerCertificates[0].Subject.String()
+		}
+		fmt.Fprint(w, subject)
+	}))
+	server.TLS = reloader.Config()
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// servedSerial is the serial number of the certificate the server presents.
+func servedSerial(t *testing.T, p *testPKI, url string) string {
+	t.Helper()
+
+	resp, err := p.httpClient(true).Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.TLS.PeerCertificates[0].SerialNumber.String()
+}
+
+func Test_MutualTLS(t *testing.T) {
+	p := newTestPKI(t)
+	reloader, err := NewReloader(p.options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer(t, reloader)
+
+	resp, err := p.httpClient(true).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(subject) != "CN=partner,O=Acme" {
+		t.Errorf("expected the server to see the client's certificate but got %q", subject)
+	}
+
+	if _, err := p.httpClient(false).Get(server.URL); err == nil {
+		t.Error("expected a client without a certificate to be refused")
+	}
+
+	other, _ := NewCA("other CA")
+	stranger, _ := other.Issue(pkix.Name{CommonName: "partner"})
+	p.client = stranger
+	if _, err := p.httpClient(true).Get(server.URL); err == nil {
+		t.Error("expected a certificate of another CA to be refused")
+	}
+}
+
+func Test_Reload(t *testing.T) {
+	p := newTestPKI(t)
+	reloader, err := NewReloader(p.options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer(t, reloader)
+
+	if serial := servedSerial(t, p, server.URL); serial != p.server.Cert.SerialNumber.String() {
+		t.Fatalf("expected the server to present its certificate but got serial %s", serial)
+	}
+	if reloader.Changed() {
+		t.Error("expected no change before the files were written")
+	}
+
+	p.renew(t)
+	if !reloader.Changed() {
+		t.Fatal("expected the new files to be seen")
+	}
+	if err := reloader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if serial := servedSerial(t, p, server.URL); serial != p.server.Cert.SerialNumber.String() {
+		t.Errorf("expected the renewed certificate to be presented but got serial %s", serial)
+	}
+
+	// A broken file leaves the last certificate in place.
+	renewed := p.server.Cert.SerialNumber.String()
+	if err := os.WriteFile(p.options.KeyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p.touch(t)
+	if err := reloader.Reload(); err == nil {
+		t.Error("expected a broken key to fail to load")
+	}
+	if serial := servedSerial(t, p, server.URL); serial != renewed {
+		t.Errorf("expected the last good certificate to be kept but got serial %s", serial)
+	}
+}
+
+func Test_Watch(t *testing.T) {
+	p := newTestPKI(t)
+	reloader, err := NewReloader(p.options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer(t, reloader)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	// The files may be caught half written; they are loaded again once whole.
+	go reloader.Watch(10*time.Millisecond, stop, func(error) {})
+
+	p.renew(t)
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		if servedSerial(t, p, server.URL) == p.server.Cert.SerialNumber.String() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the renewed certificate to be picked up without a restart")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func Test_ClientSubjects(t *testing.T) {
+	p := newTestPKI(t)
+	partner := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{p.client.Cert, p.ca.Cert}}}
+	other := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{p.server.Cert, p.ca.Cert}}}
+
+	subjects := NewClientSubjects(map[string]string{"CN=partner,O=Acme": "partner"})
+	tests := []struct {
+		name   string
+		state  *tls.ConnectionState
+		client string
+		allows bool
+	}{
+		{"bound client with its certificate", partner, "partner", true},
+		{"other client with a mapped certificate", partner, "ops", false},
+		{"bound client with an unmapped certificate", other, "partner", false},
+		{"bound client without TLS", nil, "partner", false},
+		{"unbound client with an unmapped certificate", other, "ops", true},
+		{"unbound client without TLS", nil, "ops", true},
+	}
+	for _, tt := range tests {
+		if got := subjects.Allows(tt.state, tt.client); got != tt.allows {
+			t.Errorf("%s: expected %t but got %t", tt.name, tt.allows, got)
+		}
+	}
+
+	if !NewClientSubjects(nil).Allows(nil, "partner") {
+		t.Error("expected every client to be allowed without subjects")
+	}
+}
@@ -0,0 +1,60 @@
+package validator
+
+import "regexp"
+
+// EmailRX is the pattern recommended by the W3C for e-mail input fields.
+var EmailRX = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
+
+// Validator collects the problems found in a request, keyed by the JSON name
+// of the offending field.
+type Validator struct {
+	Errors map[string][]string
+}
+
+func New() *Validator {
+	return &Validator{Errors: make(map[string][]string)}
+}
+
+func (v *Validator) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+func (v *Validator) AddError(key, message string) {
+	for _, existing := range v.Errors[key] {
+		if existing == message {
+			return
+		}
+	}
+	v.Errors[key] = append(v.Errors[key], message)
+}
+
+// Check adds the message under key unless ok holds.
+func (v *Validator) Check(ok bool, key, message string) {
+	if !ok {
+		v.AddError(key, message)
+	}
+}
+
+func In[T comparable](value T, list ...T) bool {
+	for _, item := range list {
+		if value == item {
+			return true
+		}
+	}
+	return false
+}
+
+func Matches(value string, rx *regexp.Regexp) bool {
+	return rx.MatchString(value)
+}
+
+func Unique[T comparable](values []T) bool {
+	seen := make(map[T]bool, len(values))
+	for _, value := range values {
+		if seen[value] {
+			return false
+		}
+		seen[value] = true
+	}
+	return true
+}
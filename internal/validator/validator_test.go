@@ -0,0 +1,52 @@
+package validator
+
+import "testing"
+
+func Test_Validator(t *testing.T) {
+	v := New()
+	if !v.Valid() {
+		t.Fatal("expected a new validator to be valid")
+	}
+
+	v.Check(true, "name", "must be provided")
+	v.Check(false, "amount", "must be greater than zero")
+	v.Check(false, "amount", "must be greater than zero")
+	v.AddError("amount", "must not have more decimal places than the currency allows")
+
+	if v.Valid() {
+		t.Error("expected the validator to hold errors")
+	}
+	if _, ok := v.Errors["name"]; ok {
+		t.Error("did not expect an error for a passing check")
+	}
+	if len(v.Errors["amount"]) != 2 {
+		t.Errorf("expected two distinct amount errors but got %v", v.Errors["amount"])
+	}
+}
+
+func Test_Helpers(t *testing.T) {
+	if !In("daily", "simple", "daily") || In("weekly", "simple", "daily") {
+		t.Error("In gave a wrong answer")
+	}
+
+	testCases := []struct {
+		email    string
+		expected bool
+	}{
+		{"jane@example.com", true},
+		{"jane.doe+bank@mail.example.co.uk", true},
+		{"jane", false},
+		{"jane@", false},
+		{"@example.com", false},
+	}
+
+	for _, tt := range testCases {
+		if Matches(tt.email, EmailRX) != tt.expected {
+			t.Errorf("%q: expected %v", tt.email, tt.expected)
+		}
+	}
+
+	if !Unique([]string{"EUR", "USD"}) || Unique([]string{"EUR", "EUR"}) {
+		t.Error("Unique gave a wrong answer")
+	}
+}
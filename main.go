@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"time"
@@ -10,32 +12,178 @@ import (
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 	_ "github.com/lib/pq"
+	"github.com/petrostrak/agile-transfer/api"
 	"github.com/petrostrak/agile-transfer/internal/adapters/handlers"
 	"github.com/petrostrak/agile-transfer/internal/adapters/repository"
+	"github.com/petrostrak/agile-transfer/internal/adapters/rpc"
+	"github.com/petrostrak/agile-transfer/internal/config"
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/ports"
 	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/jwt"
+	"github.com/petrostrak/agile-transfer/internal/tlsconfig"
+	"github.com/petrostrak/agile-transfer/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 var (
-	accountService  *services.AccountService
-	transferService *services.TransferService
-	accountHandler  *handlers.AccountHandler
-	transferHandler *handlers.TransferHandler
+	accountService     *services.AccountService
+	transferService    *services.TransferService
+	interestService    *services.InterestService
+	ledgerService      *services.LedgerService
+	customerService    *services.CustomerService
+	walletService      *services.WalletService
+	apiKeyService      *services.APIKeyService
+	userService        *services.UserService
+	accessService      *services.AccessService
+	approvalService    *services.ApprovalService
+	auditService       *services.AuditService
+	rateLimitService   *services.RateLimitService
+	signingService     *services.SigningService
+	idempotencyService *services.IdempotencyService
+	accountHandler     *handlers.AccountHandler
+	transferHandler    *handlers.TransferHandler
+	interestHandler    *handlers.InterestHandler
+	ledgerHandler      *handlers.LedgerHandler
+	customerHandler    *handlers.CustomerHandler
+	walletHandler      *handlers.WalletHandler
+	apiKeyHandler      *handlers.APIKeyHandler
+	roleHandler        *handlers.RoleHandler
+	approvalHandler    *handlers.ApprovalHandler
+	auditHandler       *handlers.AuditHandler
+	rateLimiter        *handlers.RateLimiter
+	signingHandler     *handlers.SigningHandler
+	clientCertificates *handlers.ClientCertificates
+	authHandler        *handlers.AuthHandler
+	docsHandler        *handlers.DocsHandler
+	idempotencyKeys    *handlers.IdempotencyKeys
+)
+
+// The rate limits of the routes, as configured. Every request counts towards
+// the api limit of its caller, and transfers towards the transfer limits of
+// their caller and source account too.
+var apiLimit, transferLimit = config.Default().RateLimit.Routes()
+
+// Deprecated versions of the API are served for at least six months after
+// they are deprecated, announcing their sunset in every response. After the
+// sunset they answer 410 Gone.
+var (
+	unversionedDeprecated = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	unversionedSunset     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
 )
 
 func main() {
 	logger := log.New(os.Stdout, "", log.Ldate|log.Ltime)
 
-	store := repository.NewPostgressRepository()
+	fs := config.FlagSet(os.Args[0])
+	check := fs.Bool("check", false, "check the configuration, the database connection and the schema version, then exit")
+	cfg, err := config.Load(fs, os.Args[1:], os.Getenv)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	logger.Printf("configuration:\n%s", cfg)
+
+	disableCurrencies(logger, cfg.Currencies)
+	apiLimit, transferLimit = cfg.RateLimit.Routes()
+
+	if *check {
+		if err := checkStartup(logger, cfg); err != nil {
+			logger.Fatalf("check failed: %v", err)
+		}
+		logger.Print("check passed")
+		return
+	}
+
+	store := connect(logger, cfg.Database)
+	rates := utils.NewCurrencyConverter(cfg.FX.URL, cfg.FX.APIKey, cfg.FX.Timeout)
+	policy := cfg.Approval.Policy()
 	accountService = services.NewAccountService(store.AccountRepository)
-	transferService = services.NewTransferService(store.TransferRepository)
-	accountHandler = handlers.NewAccountHandler(*accountService)
-	transferHandler = handlers.NewTransferHandler(*transferService)
+	transferService = services.NewTransferService(store.TransferRepository, store.CustomerRepository, rates.Convert, policy)
+	interestService = services.NewInterestService(store.InterestRepository, store.AccountRepository, store.LedgerRepository, transferService, cfg.Interest.ExpenseAccounts)
+	ledgerService = services.NewLedgerService(store.LedgerRepository, store.AccountRepository)
+	customerService = services.NewCustomerService(store.CustomerRepository, store.AccountRepository)
+	walletService = services.NewWalletService(store.WalletRepository, store.AccountRepository, rates.Convert)
+	apiKeyService = services.NewAPIKeyService(store.APIKeyRepository)
+	accessService = services.NewAccessService(store.RoleRepository, store.AuditRepository)
+	userService = services.NewUserService(tokenVerifier(logger, cfg.JWT), store.CustomerRepository, store.RoleRepository)
+	auditService = services.NewAuditService(store.AuditRepository)
+	approvalService = services.NewApprovalService(store.ApprovalRepository, policy, accountService, transferService, auditService)
+	rateLimitService = services.NewRateLimitService(rateLimitStore(cfg.RateLimit, store), store.AccountRepository)
+	signingService = services.NewSigningService(store.SigningRepository)
+	idempotencyService = services.NewIdempotencyService(store.IdempotencyRepository, cfg.Handlers.IdempotencyTTL)
+	accountHandler = handlers.NewAccountHandler(*accountService, *approvalService, cfg.Handlers)
+	transferHandler = handlers.NewTransferHandler(*transferService, *accountService, *approvalService, cfg.Handlers)
+	interestHandler = handlers.NewInterestHandler(*interestService)
+	ledgerHandler = handlers.NewLedgerHandler(*ledgerService)
+	customerHandler = handlers.NewCustomerHandler(*customerService)
+	walletHandler = handlers.NewWalletHandler(*walletService)
+	apiKeyHandler = handlers.NewAPIKeyHandler(*apiKeyService)
+	roleHandler = handlers.NewRoleHandler(*accessService)
+	approvalHandler = handlers.NewApprovalHandler(*approvalService, *accessService)
+	auditHandler = handlers.NewAuditHandler(*auditService)
+	rateLimiter = handlers.NewRateLimiter(*rateLimitService)
+	signingHandler = handlers.NewSigningHandler(*signingService)
+	clientCertificates = handlers.NewClientCertificates(cfg.TLS.ClientSubjects)
+	authHandler = handlers.NewAuthHandler(*apiKeyService, *userService, *accessService)
+	docsHandler = handlers.NewDocsHandler(api.OpenAPI)
+	idempotencyKeys = handlers.NewIdempotencyKeys(*idempotencyService)
+
+	bootstrapAPIKey(logger)
+	seedRoles(logger)
+
+	interest := func(ctx context.Context, day time.Time) error {
+		runs, err := interestService.CatchUp(ctx, day)
+		for _, run := range runs {
+			logger.Printf("interest for %s: %d accrued, %d posted, %d failed", run.Date.Format(time.DateOnly), run.Accrued, run.Posted, len(run.Failures))
+		}
+		return err
+	}
+	go func() {
+		// Accrue the days missed while the service was down.
+		if err := interest(context.Background(), time.Now().UTC().AddDate(0, 0, -1)); err != nil {
+			logger.Printf("interest job failed: %v", err)
+		}
+		runDaily(context.Background(), logger, "interest", interest)
+	}()
+
+	go runDaily(context.Background(), logger, "balance snapshot", func(ctx context.Context, day time.Time) error {
+		_, err := ledgerService.TakeSnapshots(ctx, day.AddDate(0, 0, 1))
+		return err
+	})
+
+	go runDaily(context.Background(), logger, "signature nonces", func(ctx context.Context, _ time.Time) error {
+		return signingService.PurgeNonces(ctx)
+	})
+
+	go runDaily(context.Background(), logger, "idempotency keys", func(ctx context.Context, _ time.Time) error {
+		return idempotencyService.PurgeKeys(ctx)
+	})
+
+	go runDaily(context.Background(), logger, "rate limit buckets", func(ctx context.Context, _ time.Time) error {
+		return rateLimitService.PurgeBuckets(ctx, refillPeriod(apiLimit, transferLimit))
+	})
+
+	reloader := serverTLS(logger, cfg.TLS)
+	go serveRPC(logger, cfg.GRPC.Addr, reloader, cfg.TLS.ClientSubjects, cfg.Handlers)
 
 	srv := &http.Server{
-		Addr:        fmt.Sprintf(":%d", 8080),
+		Addr:        cfg.HTTP.Addr,
 		Handler:     Routes(),
-		IdleTimeout: time.Minute,
-		ReadTimeout: 10 * time.Second,
+		IdleTimeout: cfg.HTTP.IdleTimeout,
+		ReadTimeout: cfg.HTTP.ReadTimeout,
+	}
+	if reloader != nil {
+		srv.TLSConfig = reloader.Config()
+	}
+
+	if srv.TLSConfig != nil {
+		logger.Printf("starting server with TLS on %s", srv.Addr)
+		if err := srv.ListenAndServeTLS("", ""); err != nil {
+			logger.Fatal(err)
+		}
+		return
 	}
 
 	logger.Printf("starting development server on %s", srv.Addr)
@@ -50,16 +198,30 @@ func Routes() http.Handler {
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	r.Use(handlers.Timestamps)
+	r.NotFound(utils.NotFoundResponse)
+	r.MethodNotAllowed(utils.MethodNotAllowedResponse)
 
-	r.Route("/accounts", func(r chi.Router) {
-		r.Get("/", accountHandler.GetAllAccounts)
-		r.Post("/", accountHandler.CreateAccount)
-		r.Get("/{id}", accountHandler.GetAccount)
-		r.Patch("/{id}", accountHandler.UpdateAccount)
-		r.Delete("/{id}", accountHandler.DeleteAccount)
+	r.Route(handlers.V1.Prefix(), func(r chi.Router) {
+		r.Use(handlers.UseVersion(handlers.V1))
+		apiRoutes(r)
+		r.Get("/openapi.json", docsHandler.OpenAPI)
+		r.Get("/docs", docsHandler.Docs)
+	})
+	r.Route(handlers.V2.Prefix(), func(r chi.Router) {
+		r.Use(handlers.UseVersion(handlers.V2))
+		apiRoutes(r)
+	})
+
+	// The routes were first served without a version prefix. They are kept
+	// as aliases of /v1 until their sunset.
+	r.Group(func(r chi.Router) {
+		r.Use(handlers.UseVersion(handlers.V1))
+		r.Use(handlers.Deprecate(unversionedDeprecated, unversionedSunset, "", handlers.V1.Prefix()))
+		apiRoutes(r)
+		r.Get("/openapi.json", docsHandler.OpenAPI)
+		r.Get("/docs", docsHandler.Docs)
 	})
-	r.Post("/transfer", transferHandler.CreateTransfer)
-	r.Get("/transactions", transferHandler.GetAllTransfers)
 
 	chi.Walk(r, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
 		fmt.Printf("[%s]: '%s' has %d middlewares\n", method, route, len(middlewares))
@@ -68,3 +230,286 @@ func Routes() http.Handler {
 
 	return r
 }
+
+// serveRPC serves the gRPC API on addr alongside the REST one, over the TLS
+// of reloader unless it is nil. The API keys of the clients subjects binds
+// only work over connections with one of their certificates. Calls are given
+// the time limits of the REST requests.
+func serveRPC(logger *log.Logger, addr string, reloader *tlsconfig.Reloader, subjects map[string]string, timeouts config.Handlers) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	var opts []grpc.ServerOption
+	if reloader != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(reloader.Config("h2"))))
+		logger.Printf("starting gRPC server with TLS on %s", addr)
+	} else {
+		logger.Printf("starting gRPC server on %s", addr)
+	}
+	if err := rpc.NewServer(*accountService, *transferService, *apiKeyService, *approvalService, *auditService, *rateLimitService, apiLimit, transferLimit, *signingService, subjects, timeouts, opts...).Serve(listener); err != nil {
+		logger.Fatal(err)
+	}
+}
+
+// apiRoutes registers the routes every version of the API serves. The
+// handlers tell the versions apart with handlers.APIVersion. Every route needs
+// an API key or bearer token granting the permission it is registered with;
+// the routes registered with an owner permission are also open to customers,
+// for their own accounts. API clients holding a signing secret must sign
+// their requests too, and those bound to client certificates must connect
+// with one of theirs.
+func apiRoutes(r chi.Router) {
+	r = r.With(authHandler.Authenticate, clientCertificates.Middleware, rateLimiter.Limit(apiLimit), signingHandler.Middleware, idempotencyKeys.Middleware, auditHandler.Middleware)
+	can := authHandler.RequirePermission
+	owner := authHandler.RequireOwnerPermission
+
+	r.Route("/accounts", func(r chi.Router) {
+		r.With(owner(domain.PermissionAccountsView)).Get("/", accountHandler.GetAllAccounts)
+		r.With(can(domain.PermissionAccountsManage)).Post("/", accountHandler.CreateAccount)
+		r.With(owner(domain.PermissionAccountsView)).Get("/{id}", accountHandler.GetAccount)
+		r.With(can(domain.PermissionBalancesAdjust)).Patch("/{id}", accountHandler.UpdateAccount)
+		r.With(can(domain.PermissionAccountsManage)).Delete("/{id}", accountHandler.DeleteAccount)
+		r.With(can(domain.PermissionAccountsFreeze)).Post("/{id}/freeze", accountHandler.FreezeAccount)
+		r.With(can(domain.PermissionAccountsFreeze)).Post("/{id}/unfreeze", accountHandler.UnfreezeAccount)
+		r.With(can(domain.PermissionAccountsView)).Get("/{id}/interest", interestHandler.GetInterestConfig)
+		r.With(can(domain.PermissionAccountsManage)).Put("/{id}/interest", interestHandler.SetInterestConfig)
+		r.With(can(domain.PermissionAccountsView)).Get("/{id}/interest/accruals", interestHandler.GetAccruals)
+		r.With(can(domain.PermissionAccountsView)).Get("/{id}/statements", ledgerHandler.GetStatement)
+		r.With(can(domain.PermissionAccountsView)).Get("/{id}/balance", ledgerHandler.GetBalance)
+		r.With(can(domain.PermissionAccountsView)).Get("/{id}/pockets", walletHandler.GetPockets)
+		r.With(can(domain.PermissionAccountsManage)).Post("/{id}/pockets", walletHandler.OpenPocket)
+		r.With(can(domain.PermissionAccountsManage)).Post("/{id}/convert", walletHandler.Convert)
+	})
+	r.Route("/customers", func(r chi.Router) {
+		r.With(can(domain.PermissionCustomersView)).Get("/", customerHandler.GetAllCustomers)
+		r.With(can(domain.PermissionCustomersManage)).Post("/", customerHandler.CreateCustomer)
+		r.With(can(domain.PermissionCustomersView)).Get("/{id}", customerHandler.GetCustomer)
+		r.With(can(domain.PermissionCustomersManage)).Patch("/{id}", customerHandler.UpdateCustomer)
+		r.With(can(domain.PermissionCustomersManage)).Delete("/{id}", customerHandler.DeleteCustomer)
+		r.With(can(domain.PermissionCustomersView)).Get("/{id}/accounts", customerHandler.GetCustomerAccounts)
+		r.With(can(domain.PermissionCustomersView)).Get("/{id}/beneficiaries", customerHandler.GetBeneficiaries)
+		r.With(can(domain.PermissionCustomersManage)).Post("/{id}/beneficiaries", customerHandler.AddBeneficiary)
+		r.With(can(domain.PermissionCustomersManage)).Delete("/{id}/beneficiaries/{accountID}", customerHandler.RemoveBeneficiary)
+	})
+	r.With(owner(domain.PermissionTransfersCreate), rateLimiter.Limit(transferLimit)).Post("/transfer", transferHandler.CreateTransfer)
+	r.With(can(domain.PermissionTransfersView)).Get("/transactions", transferHandler.GetAllTransfers)
+	r.With(can(domain.PermissionTransfersReverse)).Post("/transactions/{id}/reverse", transferHandler.ReverseTransfer)
+	r.With(can(domain.PermissionAccountsView)).Get("/balances", ledgerHandler.GetBalances)
+	r.With(can(domain.PermissionInterestRun)).Post("/interest/run", interestHandler.RunInterest)
+	r.Route("/api-keys", func(r chi.Router) {
+		r.Use(can(domain.PermissionAPIKeysManage))
+		r.Get("/", apiKeyHandler.GetAllKeys)
+		r.Post("/", apiKeyHandler.IssueKey)
+		r.Get("/{id}", apiKeyHandler.GetKey)
+		r.Delete("/{id}", apiKeyHandler.RevokeKey)
+		r.Post("/{id}/rotate", apiKeyHandler.RotateKey)
+	})
+	r.Route("/signing-secrets", func(r chi.Router) {
+		r.Use(can(domain.PermissionAPIKeysManage))
+		r.Get("/", signingHandler.GetAllSecrets)
+		r.Post("/", signingHandler.IssueSecret)
+		r.Get("/{id}", signingHandler.GetSecret)
+		r.Delete("/{id}", signingHandler.RevokeSecret)
+		r.Post("/{id}/rotate", signingHandler.RotateSecret)
+	})
+	r.Route("/roles", func(r chi.Router) {
+		r.With(can(domain.PermissionRolesView)).Get("/", roleHandler.GetAllRoles)
+		r.With(can(domain.PermissionRolesManage)).Post("/", roleHandler.CreateRole)
+		r.With(can(domain.PermissionRolesView)).Get("/{name}", roleHandler.GetRole)
+		r.With(can(domain.PermissionRolesManage)).Patch("/{name}", roleHandler.UpdateRole)
+		r.With(can(domain.PermissionRolesManage)).Delete("/{name}", roleHandler.DeleteRole)
+	})
+	r.With(can(domain.PermissionRolesView)).Get("/permissions", roleHandler.GetPermissions)
+	r.Route("/approvals", func(r chi.Router) {
+		r.With(can(domain.PermissionApprovalsView)).Get("/", approvalHandler.GetAllApprovals)
+		r.With(can(domain.PermissionApprovalsView)).Get("/{id}", approvalHandler.GetApproval)
+		r.With(can(domain.PermissionApprovalsDecide)).Post("/{id}/approve", approvalHandler.ApproveApproval)
+		r.With(can(domain.PermissionApprovalsDecide)).Post("/{id}/reject", approvalHandler.RejectApproval)
+	})
+	r.Route("/audit", func(r chi.Router) {
+		r.Use(can(domain.PermissionAuditView))
+		r.Get("/", auditHandler.GetAuditLog)
+		r.Get("/verify", auditHandler.VerifyAuditLog)
+	})
+}
+
+// connect connects to the database, retrying while it cannot be reached so
+// that the service can start alongside it, and exits when it never can.
+func connect(logger *log.Logger, cfg config.Database) *repository.PostgresRepository {
+	var store *repository.PostgresRepository
+	err := utils.Retry(context.Background(), cfg.ConnectAttempts, cfg.ConnectBackoff, func() (err error) {
+		store, err = repository.NewPostgressRepository(cfg)
+		return err
+	}, func(attempt int, err error, wait time.Duration) {
+		logger.Printf("cannot reach the database (attempt %d of %d), retrying in %s: %v", attempt, cfg.ConnectAttempts, wait, err)
+	})
+	if err != nil {
+		logger.Fatalf("cannot start: the database could not be reached in %d attempts, check database.dsn and that it is running: %v", cfg.ConnectAttempts, err)
+	}
+	return store
+}
+
+// checkStartup checks what the service needs to start, without starting it:
+// the key set and certificates the settings name, which exit when they cannot
+// be loaded, a connection to the database and that it is migrated to
+// SchemaVersion.
+func checkStartup(logger *log.Logger, cfg *config.Config) error {
+	tokenVerifier(logger, cfg.JWT)
+	serverTLS(logger, cfg.TLS)
+	logger.Print("configuration: ok")
+
+	store, err := repository.NewPostgressRepository(cfg.Database)
+	if err != nil {
+		return err
+	}
+	logger.Print("database: reachable")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.ConnectTimeout)
+	defer cancel()
+
+	version, dirty, err := store.SchemaRepository.Version(ctx)
+	switch {
+	case err != nil:
+		return fmt.Errorf("schema: %w", err)
+	case dirty:
+		return fmt.Errorf("schema: migration %d failed part way and must be fixed by hand", version)
+	case version != repository.SchemaVersion:
+		return fmt.Errorf("schema: the database is at migration %d but this build needs %d", version, repository.SchemaVersion)
+	}
+	logger.Printf("schema: at migration %d", version)
+	return nil
+}
+
+// runDaily calls job shortly after every UTC midnight with the day that has
+// just ended.
+func runDaily(ctx context.Context, logger *log.Logger, name string, job func(context.Context, time.Time) error) {
+	for {
+		now := time.Now().UTC()
+		next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 5, 0, 0, time.UTC)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(next.Sub(now)):
+		}
+
+		if err := job(ctx, next.AddDate(0, 0, -1)); err != nil {
+			logger.Printf("%s job failed: %v", name, err)
+		}
+	}
+}
+
+// rateLimitStore is where the buckets of the rate limits are kept: in memory,
+// or in store for the limits to hold across every instance sharing the
+// database.
+func rateLimitStore(cfg config.RateLimit, store *repository.PostgresRepository) ports.RateLimitRepository {
+	if cfg.Store == "postgres" {
+		return store.RateLimitRepository
+	}
+	return repository.NewMemoryRateLimitRepository()
+}
+
+// refillPeriod is the longest period of the limits of routes, after which
+// every bucket left idle is full again.
+func refillPeriod(routes ...domain.RouteLimit) time.Duration {
+	var longest time.Duration
+	for _, route := range routes {
+		for _, limit := range []domain.RateLimit{route.Client, route.Account} {
+			if limit.Period > longest {
+				longest = limit.Period
+			}
+		}
+	}
+	return longest
+}
+
+// tokenVerifier verifies bearer tokens against the key set of cfg. Bearer
+// tokens are refused when it has none.
+func tokenVerifier(logger *log.Logger, cfg config.JWT) *jwt.Verifier {
+	if cfg.JWKS == "" {
+		return nil
+	}
+
+	keys, err := jwt.NewKeySet(cfg.JWKS)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	return jwt.NewVerifier(keys, cfg.Issuer, cfg.Audience)
+}
+
+// serverTLS loads the certificates of the HTTP and gRPC servers, which serve
+// plaintext when cfg has none. The files are loaded again when they change.
+func serverTLS(logger *log.Logger, cfg config.TLS) *tlsconfig.Reloader {
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	options, err := cfg.Options()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	reloader, err := tlsconfig.NewReloader(options)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	go reloader.Watch(cfg.ReloadInterval, nil, func(err error) {
+		if err != nil {
+			logger.Printf("keeping the TLS certificates loaded before: %v", err)
+			return
+		}
+		logger.Print("reloaded the TLS certificates")
+	})
+
+	return reloader
+}
+
+// bootstrapAPIKey issues an admin key when there is none, so that the first
+// keys can be issued through the API. It is only logged once: store it and
+// revoke it when it is no longer needed.
+func bootstrapAPIKey(logger *log.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	keys, err := apiKeyService.GetAll(ctx)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if len(keys) > 0 {
+		return
+	}
+
+	key := &domain.APIKey{Client: "bootstrap", Scopes: []domain.Scope{domain.ScopeAdmin}}
+	plain, err := apiKeyService.Issue(ctx, key)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	logger.Printf("no API keys found, issued admin key %s (id %s); store it now, it will not be shown again", plain, key.ID)
+}
+
+// seedRoles creates the default roles when there are none, so that
+// back-office users can be given roles before any are created through the
+// API.
+func seedRoles(logger *log.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := accessService.SeedRoles(ctx)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if created > 0 {
+		logger.Printf("no roles found, created the %d default roles", created)
+	}
+}
+
+// disableCurrencies turns off the currencies of cfg, so that no new
+// accounts, pockets or transfers use them.
+func disableCurrencies(logger *log.Logger, cfg config.Currencies) {
+	for _, code := range cfg.Disabled {
+		if err := currency.Default.SetEnabled(code, false); err != nil {
+			logger.Printf("ignoring disabled currency: %v", err)
+		}
+	}
+}
@@ -0,0 +1,741 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/petrostrak/agile-transfer/api"
+	"github.com/petrostrak/agile-transfer/internal/adapters/handlers"
+	"github.com/petrostrak/agile-transfer/internal/adapters/repository/repositorytest"
+	"github.com/petrostrak/agile-transfer/internal/config"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/core/services"
+	"github.com/petrostrak/agile-transfer/internal/jwt"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/shopspring/decimal"
+)
+
+type openAPIOperation struct {
+	Responses map[string]struct {
+		Ref     string `json:"$ref"`
+		Content map[string]struct {
+			Schema json.RawMessage `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+type openAPIDocument struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Responses map[string]struct {
+			Content map[string]json.RawMessage `json:"content"`
+		} `json:"responses"`
+	} `json:"components"`
+}
+
+// adminKey is an API key granting every scope, issued by useMemoryStore.
+var adminKey string
+
+// testStore is the repository useMemoryStore wires the services to.
+var testStore *repositorytest.Repository
+
+// tokenKey signs the bearer tokens of the tests; useMemoryStore makes the
+// service trust it.
+var tokenKey *ecdsa.PrivateKey
+
+// signToken issues a bearer token for subject with the given roles.
+func signToken(t *testing.T, subject string, roles ...string) string {
+	t.Helper()
+
+	token, err := jwt.Sign(tokenKey, "test", map[string]any{
+		"sub":   subject,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": roles,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// useMemoryStore wires the services and handlers Routes uses to an in-memory
+// repository, converting currencies at a fixed rate of 1.1, issues adminKey,
+// creates the default roles and trusts the bearer tokens signed with
+// tokenKey.
+func useMemoryStore() {
+	store := repositorytest.NewRepository()
+	testStore = store
+	accountService = services.NewAccountService(store.AccountRepository)
+	ledgerService = services.NewLedgerService(store.LedgerRepository, store.AccountRepository)
+	customerService = services.NewCustomerService(store.CustomerRepository, store.AccountRepository)
+	walletService = services.NewWalletService(store.WalletRepository, store.AccountRepository, fixedRate)
+	apiKeyService = services.NewAPIKeyService(store.APIKeyRepository)
+	accessService = services.NewAccessService(store.RoleRepository, store.AuditRepository)
+	userService = services.NewUserService(testVerifier(), store.CustomerRepository, store.RoleRepository)
+	auditService = services.NewAuditService(store.AuditRepository)
+	rateLimitService = services.NewRateLimitService(store.RateLimitRepository, store.AccountRepository)
+	signingService = services.NewSigningService(store.SigningRepository)
+	useApprovalPolicy(domain.ApprovalPolicy{})
+	ledgerHandler = handlers.NewLedgerHandler(*ledgerService)
+	customerHandler = handlers.NewCustomerHandler(*customerService)
+	walletHandler = handlers.NewWalletHandler(*walletService)
+	apiKeyHandler = handlers.NewAPIKeyHandler(*apiKeyService)
+	roleHandler = handlers.NewRoleHandler(*accessService)
+	authHandler = handlers.NewAuthHandler(*apiKeyService, *userService, *accessService)
+	auditHandler = handlers.NewAuditHandler(*auditService)
+	rateLimiter = handlers.NewRateLimiter(*rateLimitService)
+	signingHandler = handlers.NewSigningHandler(*signingService)
+	clientCertificates = handlers.NewClientCertificates(nil)
+	docsHandler = handlers.NewDocsHandler(api.OpenAPI)
+	idempotencyKeys = handlers.NewIdempotencyKeys(*services.NewIdempotencyService(testStore.IdempotencyRepository, time.Hour))
+
+	var err error
+	adminKey, err = apiKeyService.Issue(context.Background(), &domain.APIKey{Client: "test", Scopes: []domain.Scope{domain.ScopeAdmin}})
+	if err != nil {
+		panic(err)
+	}
+	if _, err = accessService.SeedRoles(context.Background()); err != nil {
+		panic(err)
+	}
+}
+
+func fixedRate(from, to string, amount decimal.Decimal) (decimal.Decimal, error) {
+	return amount.Mul(decimal.RequireFromString("1.1")), nil
+}
+
+// useApprovalPolicy makes the services hold the actions policy says need
+// approval. useMemoryStore starts with none needing it.
+func useApprovalPolicy(policy domain.ApprovalPolicy) {
+	transferService = services.NewTransferService(testStore.TransferRepository, testStore.CustomerRepository, fixedRate, policy)
+	interestService = services.NewInterestService(testStore.InterestRepository, testStore.AccountRepository, testStore.LedgerRepository, transferService, nil)
+	interestHandler = handlers.NewInterestHandler(*interestService)
+	approvalService = services.NewApprovalService(testStore.ApprovalRepository, policy, accountService, transferService, auditService)
+	accountHandler = handlers.NewAccountHandler(*accountService, *approvalService, config.Default().Handlers)
+	transferHandler = handlers.NewTransferHandler(*transferService, *accountService, *approvalService, config.Default().Handlers)
+	approvalHandler = handlers.NewApprovalHandler(*approvalService, *accessService)
+}
+
+// testVerifier makes a new tokenKey and returns a verifier trusting it.
+func testVerifier() *jwt.Verifier {
+	var err error
+	tokenKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	data, err := jwt.MarshalKeySet(map[string]crypto.PublicKey{"test": &tokenKey.PublicKey})
+	if err != nil {
+		panic(err)
+	}
+	keys, err := jwt.ParseKeySet(data)
+	if err != nil {
+		panic(err)
+	}
+	return jwt.NewVerifier(keys, "", "")
+}
+
+func loadOpenAPI(t *testing.T) openAPIDocument {
+	var doc openAPIDocument
+	if err := json.Unmarshal(api.OpenAPI, &doc); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	return doc
+}
+
+// specPath turns a chi route into the path it is documented under. The
+// document describes version 1, so only its routes are documented.
+func specPath(route string) (string, bool) {
+	route, ok := strings.CutPrefix(route, handlers.V1.Prefix())
+	if route != "/" {
+		route = strings.TrimSuffix(route, "/")
+	}
+	return route, ok
+}
+
+func TestOpenAPIDocumentsRoutes(t *testing.T) {
+	useMemoryStore()
+	doc := loadOpenAPI(t)
+
+	registered := make(map[string]bool)
+	err := chi.Walk(Routes().(chi.Routes), func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		path, ok := specPath(route)
+		if !ok {
+			return nil
+		}
+		registered[strings.ToLower(method)+" "+path] = true
+
+		if _, ok := doc.Paths[path][strings.ToLower(method)]; !ok {
+			t.Errorf("%s %s is not described in openapi.json", method, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, item := range doc.Paths {
+		for method := range item {
+			if method == "parameters" {
+				continue
+			}
+			if !registered[method+" "+path] {
+				t.Errorf("%s %s is described in openapi.json but not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+// contract sends requests to the routes and checks every response against
+// the schema openapi.json gives for its operation, status and content type.
+type contract struct {
+	t         *testing.T
+	doc       openAPIDocument
+	compiler  *jsonschema.Compiler
+	server    *httptest.Server
+	exercised map[string]bool
+}
+
+func newContract(t *testing.T) *contract {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.AssertFormat = true
+	if err := compiler.AddResource("openapi.json", bytes.NewReader(api.OpenAPI)); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(Routes())
+	t.Cleanup(server.Close)
+
+	return &contract{
+		t:         t,
+		doc:       loadOpenAPI(t),
+		compiler:  compiler,
+		server:    server,
+		exercised: make(map[string]bool),
+	}
+}
+
+// route finds the documented path a concrete request path belongs to,
+// preferring the one with the most literal segments.
+func (c *contract) route(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(path, "/")
+
+	route, best := "", -1
+	for documented := range c.doc.Paths {
+		parts := strings.Split(documented, "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+		literals := 0
+		for i, part := range parts {
+			if strings.HasPrefix(part, "{") {
+				continue
+			}
+			if part != segments[i] {
+				literals = -1
+				break
+			}
+			literals++
+		}
+		if literals > best {
+			route, best = documented, literals
+		}
+	}
+	return route
+}
+
+// do sends the request, checks the response against the document and
+// returns the decoded JSON body.
+func (c *contract) do(method, path, body string, expectedStatus int) map[string]any {
+	c.t.Helper()
+	return c.doWithKey(method, path, body, "", expectedStatus)
+}
+
+// doWithKey is do with an Idempotency-Key header, unless key is empty.
+func (c *contract) doWithKey(method, path, body, key string, expectedStatus int) map[string]any {
+	c.t.Helper()
+
+	header := make(http.Header)
+	if key != "" {
+		header.Set("Idempotency-Key", key)
+	}
+	return c.doWithHeader(method, path, body, header, expectedStatus)
+}
+
+// doWithIfMatch is do with an If-Match header, unless etag is empty.
+func (c *contract) doWithIfMatch(method, path, body, etag string, expectedStatus int) map[string]any {
+	c.t.Helper()
+
+	header := make(http.Header)
+	if etag != "" {
+		header.Set("If-Match", etag)
+	}
+	return c.doWithHeader(method, path, body, header, expectedStatus)
+}
+
+// doWithHeader is do with the given request headers. Requests are made with
+// adminKey unless the headers set X-API-Key, if only to "".
+func (c *contract) doWithHeader(method, path, body string, header http.Header, expectedStatus int) map[string]any {
+	c.t.Helper()
+
+	req, _ := http.NewRequest(method, c.server.URL+handlers.V1.Prefix()+path, strings.NewReader(body))
+	req.Header.Set("X-API-Key", adminKey)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := c.server.Client().Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+
+	name := fmt.Sprintf("%s %s", method, path)
+	if resp.StatusCode != expectedStatus {
+		c.t.Errorf("%s: expected status %d but got %d: %s", name, expectedStatus, resp.StatusCode, data)
+	}
+
+	route := c.route(path)
+	lower := strings.ToLower(method)
+	raw, ok := c.doc.Paths[route][lower]
+	if !ok {
+		c.t.Errorf("%s: no operation for %s %s", name, method, route)
+		return nil
+	}
+	c.exercised[lower+" "+route] = true
+
+	var operation openAPIOperation
+	if err := json.Unmarshal(raw, &operation); err != nil {
+		c.t.Fatal(err)
+	}
+
+	status := fmt.Sprint(resp.StatusCode)
+	response, ok := operation.Responses[status]
+	if !ok {
+		c.t.Errorf("%s: status %s is not documented", name, status)
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	pointer := fmt.Sprintf("openapi.json#/paths/%s/%s/responses/%s/content/%s/schema", escape(route), lower, status, escape(mediaType))
+	if response.Ref != "" {
+		component := strings.TrimPrefix(response.Ref, "#/components/responses/")
+		if _, ok := c.doc.Components.Responses[component].Content[mediaType]; !ok {
+			c.t.Errorf("%s: content type %q is not documented for %s", name, mediaType, status)
+			return nil
+		}
+		pointer = fmt.Sprintf("openapi.json#/components/responses/%s/content/%s/schema", component, escape(mediaType))
+	} else if _, ok := response.Content[mediaType]; !ok {
+		c.t.Errorf("%s: content type %q is not documented for %s", name, mediaType, status)
+		return nil
+	}
+
+	if !strings.HasSuffix(mediaType, "json") {
+		return nil
+	}
+
+	schema, err := c.compiler.Compile(pointer)
+	if err != nil {
+		c.t.Fatalf("%s: compiling %s: %v", name, pointer, err)
+	}
+
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		c.t.Errorf("%s: response is not JSON: %v", name, err)
+		return nil
+	}
+	if err := schema.Validate(decoded); err != nil {
+		c.t.Errorf("%s: response does not match the schema: %#v\n%s", name, err, data)
+	}
+
+	object, _ := decoded.(map[string]any)
+	return object
+}
+
+// escape encodes a JSON pointer token, as in RFC 6901.
+func escape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func field(object map[string]any, keys ...string) string {
+	var value any = object
+	for _, key := range keys {
+		m, _ := value.(map[string]any)
+		value = m[key]
+	}
+	s, _ := value.(string)
+	return s
+}
+
+func TestOpenAPIResponses(t *testing.T) {
+	useMemoryStore()
+	useApprovalPolicy(domain.ApprovalPolicy{
+		TransferThresholds: map[string]decimal.Decimal{"EUR": decimal.NewFromInt(500)},
+		Adjustments:        true,
+		Closures:           true,
+		TTL:                time.Hour,
+	})
+	c := newContract(t)
+
+	c.doWithHeader("GET", "/openapi.json", "", http.Header{"X-Api-Key": {""}}, http.StatusOK)
+	c.doWithHeader("GET", "/docs", "", http.Header{"X-Api-Key": {""}}, http.StatusOK)
+
+	issued := c.do("POST", "/api-keys", `{"client": "reporting", "scopes": ["accounts:read"]}`, http.StatusCreated)
+	keyID, readKey := field(issued, "api_key", "id"), field(issued, "key")
+	c.do("POST", "/api-keys", `{"client": "reporting", "scopes": ["accounts:delete"]}`, http.StatusUnprocessableEntity)
+	c.doWithHeader("GET", "/accounts", "", http.Header{"X-Api-Key": {""}}, http.StatusUnauthorized)
+	c.doWithHeader("GET", "/accounts", "", http.Header{"X-Api-Key": {"agt_0000_unknown"}}, http.StatusUnauthorized)
+	c.doWithHeader("GET", "/accounts", "", http.Header{"X-Api-Key": {readKey}}, http.StatusOK)
+	c.doWithHeader("POST", "/accounts", `{"balance": 1, "currency": "EUR"}`, http.Header{"X-Api-Key": {readKey}}, http.StatusForbidden)
+	c.doWithHeader("GET", "/api-keys", "", http.Header{"X-Api-Key": {readKey}}, http.StatusForbidden)
+	c.do("GET", "/api-keys", "", http.StatusOK)
+	if used := c.do("GET", "/api-keys/"+keyID, "", http.StatusOK); field(used, "api_key", "last_used_at") == "" {
+		t.Errorf("expected the last use of the key to be recorded")
+	}
+	rotated := c.do("POST", "/api-keys/"+keyID+"/rotate", `{"overlap": "1h"}`, http.StatusCreated)
+	c.doWithHeader("GET", "/accounts", "", http.Header{"X-Api-Key": {readKey}}, http.StatusOK)
+	c.doWithHeader("GET", "/accounts", "", http.Header{"X-Api-Key": {field(rotated, "key")}}, http.StatusOK)
+	c.do("POST", "/api-keys/"+keyID+"/rotate", `{"overlap": "1y"}`, http.StatusUnprocessableEntity)
+	c.do("DELETE", "/api-keys/"+keyID, "", http.StatusOK)
+	c.doWithHeader("GET", "/accounts", "", http.Header{"X-Api-Key": {readKey}}, http.StatusUnauthorized)
+	c.do("POST", "/api-keys/"+keyID+"/rotate", "", http.StatusConflict)
+	c.do("DELETE", "/api-keys/121f03cd-ce8c-447d-8747-fb8cb7aa3a52", "", http.StatusNotFound)
+
+	customer := c.do("POST", "/customers", `{"name": "Jane Doe", "email": "jane@example.com", "type": "individual", "subject": "jane"}`, http.StatusCreated)
+	customerID := field(customer, "customer", "id")
+	c.do("POST", "/customers", `{"name": "Jane Doe", "email": "jane@example.com", "type": "individual"}`, http.StatusConflict)
+	c.do("POST", "/customers", `{"name": "John Doe", "email": "john@example.com", "type": "individual", "subject": "jane"}`, http.StatusConflict)
+	c.do("POST", "/customers", `{"name": "", "email": "jane", "type": "partnership"}`, http.StatusUnprocessableEntity)
+	c.do("GET", "/customers", "", http.StatusOK)
+	c.do("GET", "/customers/"+customerID, "", http.StatusOK)
+	c.do("PATCH", "/customers/"+customerID, `{"restrict_transfers": true}`, http.StatusOK)
+
+	source := c.do("POST", "/accounts", `{"customer_id": "`+customerID+`", "balance": 1000, "currency": "EUR"}`, http.StatusCreated)
+	sourceID := field(source, "account", "id")
+	target := c.do("POST", "/accounts", `{"balance": "0", "currency": "EUR"}`, http.StatusCreated)
+	targetID := field(target, "account", "id")
+	c.do("POST", "/accounts", `{"balance": -1, "currency": "EUR"}`, http.StatusUnprocessableEntity)
+	c.do("POST", "/accounts", `{"balance": 1, "currency": "EUR", "owner": "jane"}`, http.StatusBadRequest)
+	c.do("POST", "/accounts", ``, http.StatusBadRequest)
+	c.do("GET", "/accounts", "", http.StatusOK)
+	c.do("GET", "/accounts?page=2&page_size=1", "", http.StatusOK)
+	c.do("GET", "/accounts?page_size=1000", "", http.StatusUnprocessableEntity)
+	c.do("GET", "/accounts/"+sourceID, "", http.StatusOK)
+	c.do("GET", "/accounts/121f03cd-ce8c-447d-8747-fb8cb7aa3a52", "", http.StatusNotFound)
+	c.do("PATCH", "/accounts/"+targetID, `{"balance": 10}`, http.StatusPreconditionRequired)
+
+	// Balance adjustments wait for someone other than who asked for them to
+	// approve them.
+	adjustment := c.doWithIfMatch("PATCH", "/accounts/"+targetID, `{"balance": 10}`, `"1"`, http.StatusAccepted)
+	adjustmentID := field(adjustment, "approval", "id")
+	c.do("GET", "/approvals/"+adjustmentID, "", http.StatusOK)
+	c.do("GET", "/approvals/121f03cd-ce8c-447d-8747-fb8cb7aa3a52", "", http.StatusNotFound)
+	c.do("GET", "/approvals?status=pending", "", http.StatusOK)
+	c.do("GET", "/approvals?status=stuck", "", http.StatusUnprocessableEntity)
+	c.do("POST", "/approvals/"+adjustmentID+"/approve", "", http.StatusForbidden)
+	checker := http.Header{"X-Api-Key": {""}, "Authorization": {"Bearer " + signToken(t, "fiona", "finance")}}
+	if approved := c.doWithHeader("POST", "/approvals/"+adjustmentID+"/approve", "", checker, http.StatusOK); field(approved, "approval", "status") != "approved" {
+		t.Errorf("expected the adjustment to be approved but got %v", approved)
+	}
+	if account := c.do("GET", "/accounts/"+targetID, "", http.StatusOK); field(account, "account", "balance") != "10.00" {
+		t.Errorf("expected the approved adjustment to be made but got %v", account)
+	}
+	adjusted := c.do("GET", "/audit?action=account.updated&resource=accounts/"+targetID, "", http.StatusOK)
+	if entries, _ := adjusted["entries"].([]any); len(entries) != 1 || field(entries[0].(map[string]any), "actor") != "key:test" || !strings.Contains(field(entries[0].(map[string]any), "detail"), "user:fiona") {
+		t.Errorf("expected the adjustment to be recorded as made by key:test on the approval of fiona but got %v", adjusted["entries"])
+	}
+	c.doWithHeader("POST", "/approvals/"+adjustmentID+"/reject", "", checker, http.StatusConflict)
+	c.doWithIfMatch("PATCH", "/accounts/"+targetID, `{"balance": 20}`, `"1"`, http.StatusPreconditionFailed)
+
+	c.do("POST", "/accounts/"+sourceID+"/pockets", `{"currency": "USD"}`, http.StatusCreated)
+	c.do("POST", "/accounts/"+sourceID+"/pockets", `{"currency": "USD"}`, http.StatusConflict)
+	c.do("GET", "/accounts/"+sourceID+"/pockets", "", http.StatusOK)
+	c.do("POST", "/accounts/"+sourceID+"/convert", `{"from_currency": "EUR", "to_currency": "USD", "amount": 100}`, http.StatusOK)
+	c.do("POST", "/accounts/"+sourceID+"/convert", `{"from_currency": "EUR", "to_currency": "GBP", "amount": 100}`, http.StatusUnprocessableEntity)
+
+	transfer := `{"source_account_id": "` + sourceID + `", "target_account_id": "` + targetID + `", "amount": %s}`
+	c.do("POST", "/transfer", fmt.Sprintf(transfer, "10"), http.StatusUnprocessableEntity)
+	c.do("POST", "/customers/"+customerID+"/beneficiaries", `{"account_id": "`+targetID+`"}`, http.StatusCreated)
+	c.do("GET", "/customers/"+customerID+"/beneficiaries", "", http.StatusOK)
+	first := c.doWithKey("POST", "/transfer", fmt.Sprintf(transfer, "10"), "transfer-1", http.StatusCreated)
+	replayed := c.doWithKey("POST", "/transfer", fmt.Sprintf(transfer, "10"), "transfer-1", http.StatusCreated)
+	if id := field(first, "transaction", "transfer", "id"); id == "" || id != field(replayed, "transaction", "transfer", "id") {
+		t.Errorf("retrying a transfer with the same idempotency key made a new transfer")
+	}
+	c.doWithKey("POST", "/transfer", fmt.Sprintf(transfer, "20"), "transfer-1", http.StatusUnprocessableEntity)
+
+	// Clients holding a signing secret must sign their requests, with a
+	// recent timestamp and a nonce of their own.
+	partner := c.do("POST", "/api-keys", `{"client": "partner", "scopes": ["accounts:read", "transfers:create"]}`, http.StatusCreated)
+	partnerKey := field(partner, "key")
+	c.do("POST", "/signing-secrets", `{"client": ""}`, http.StatusUnprocessableEntity)
+	issuedSecret := c.do("POST", "/signing-secrets", `{"client": "partner"}`, http.StatusCreated)
+	secretID, secret := field(issuedSecret, "signing_secret", "id"), field(issuedSecret, "secret")
+	c.do("GET", "/signing-secrets", "", http.StatusOK)
+	c.do("GET", "/signing-secrets/"+secretID, "", http.StatusOK)
+	c.do("GET", "/signing-secrets/121f03cd-ce8c-447d-8747-fb8cb7aa3a52", "", http.StatusNotFound)
+	if refused := c.doWithHeader("GET", "/accounts", "", http.Header{"X-Api-Key": {partnerKey}}, http.StatusUnauthorized); field(refused, "code") != "signature_required" {
+		t.Errorf("expected an unsigned request to be refused with signature_required but got %v", refused)
+	}
+	now := time.Now()
+	c.doWithHeader("POST", "/transfer", fmt.Sprintf(transfer, "1"), signedBy(partnerKey, secret, "POST", "/transfer", fmt.Sprintf(transfer, "1"), now, "transfer-n-1"), http.StatusCreated)
+	c.doWithHeader("POST", "/transfer", fmt.Sprintf(transfer, "100"), signedBy(partnerKey, secret, "POST", "/transfer", fmt.Sprintf(transfer, "1"), now, "transfer-n-2"), http.StatusUnauthorized)
+	signed := signedBy(partnerKey, secret, "GET", "/accounts?page_size=1", "", now, "n-1")
+	c.doWithHeader("GET", "/accounts?page_size=1", "", signed, http.StatusOK)
+	for code, header := range map[string]http.Header{
+		"replayed_signature": signed,
+		"stale_signature":    signedBy(partnerKey, secret, "GET", "/accounts?page_size=1", "", now.Add(-10*time.Minute), "n-2"),
+		"invalid_signature":  signedBy(partnerKey, "ags_wrong", "GET", "/accounts?page_size=1", "", now, "n-3"),
+	} {
+		if refused := c.doWithHeader("GET", "/accounts?page_size=1", "", header, http.StatusUnauthorized); field(refused, "code") != code {
+			t.Errorf("expected %s but got %v", code, refused)
+		}
+	}
+	rotatedSecret := c.do("POST", "/signing-secrets/"+secretID+"/rotate", `{"overlap": "1h"}`, http.StatusCreated)
+	c.doWithHeader("GET", "/accounts", "", signedBy(partnerKey, secret, "GET", "/accounts", "", now, "n-4"), http.StatusOK)
+	c.doWithHeader("GET", "/accounts", "", signedBy(partnerKey, field(rotatedSecret, "secret"), "GET", "/accounts", "", now, "n-5"), http.StatusOK)
+	c.do("DELETE", "/signing-secrets/"+secretID, "", http.StatusOK)
+	c.doWithHeader("GET", "/accounts", "", signedBy(partnerKey, secret, "GET", "/accounts", "", now, "n-6"), http.StatusUnauthorized)
+	c.do("POST", "/signing-secrets/"+secretID+"/rotate", "", http.StatusConflict)
+	c.do("DELETE", "/signing-secrets/"+field(rotatedSecret, "signing_secret", "id"), "", http.StatusOK)
+	c.doWithHeader("GET", "/accounts", "", http.Header{"X-Api-Key": {partnerKey}}, http.StatusOK)
+
+	// Customers signed in with a bearer token only see and send from their
+	// own accounts; back-office users see them all.
+	jane := http.Header{"X-Api-Key": {""}, "Authorization": {"Bearer " + signToken(t, "jane")}}
+	if owned := c.doWithHeader("GET", "/accounts", "", jane, http.StatusOK); len(owned["accounts"].([]any)) != 1 {
+		t.Errorf("expected jane to see her one account but got %v", owned["accounts"])
+	}
+	c.doWithHeader("GET", "/accounts/"+sourceID, "", jane, http.StatusOK)
+	c.doWithHeader("GET", "/accounts/"+targetID, "", jane, http.StatusForbidden)
+	c.doWithHeader("GET", "/customers", "", jane, http.StatusForbidden)
+	c.doWithHeader("POST", "/transfer", fmt.Sprintf(transfer, "10"), jane, http.StatusCreated)
+	reversed := `{"source_account_id": "` + targetID + `", "target_account_id": "` + sourceID + `", "amount": 1}`
+	c.doWithHeader("POST", "/transfer", reversed, jane, http.StatusForbidden)
+	backOffice := http.Header{"X-Api-Key": {""}, "Authorization": {"Bearer " + signToken(t, "teller", "admin")}}
+	c.doWithHeader("GET", "/accounts/"+targetID, "", backOffice, http.StatusOK)
+	c.doWithHeader("GET", "/customers", "", backOffice, http.StatusOK)
+	c.doWithHeader("GET", "/accounts", "", http.Header{"X-Api-Key": {""}, "Authorization": {"Bearer not-a-token"}}, http.StatusUnauthorized)
+
+	// Back-office users have the permissions of their roles, and the
+	// requests they are refused are recorded in the audit trail.
+	c.do("GET", "/permissions", "", http.StatusOK)
+	c.do("GET", "/roles", "", http.StatusOK)
+	c.do("GET", "/roles/support", "", http.StatusOK)
+	c.do("GET", "/roles/auditor", "", http.StatusNotFound)
+	c.do("POST", "/roles", `{"name": "auditor", "description": "Reads the transfers.", "permissions": ["transfers:view"]}`, http.StatusCreated)
+	c.do("POST", "/roles", `{"name": "auditor", "permissions": []}`, http.StatusConflict)
+	c.do("POST", "/roles", `{"name": "Auditor", "permissions": ["accounts:destroy"]}`, http.StatusUnprocessableEntity)
+	c.do("PATCH", "/roles/auditor", `{"permissions": ["transfers:view", "accounts:view"]}`, http.StatusOK)
+	auditor := http.Header{"X-Api-Key": {""}, "Authorization": {"Bearer " + signToken(t, "ann", "auditor")}}
+	c.doWithHeader("GET", "/transactions", "", auditor, http.StatusOK)
+	support := http.Header{"X-Api-Key": {""}, "Authorization": {"Bearer " + signToken(t, "sam", "support")}}
+	c.doWithHeader("GET", "/customers", "", support, http.StatusOK)
+	c.doWithHeader("GET", "/accounts/"+targetID, "", support, http.StatusForbidden)
+	c.doWithHeader("PATCH", "/customers/"+customerID, `{"status": "suspended"}`, support, http.StatusForbidden)
+	c.doWithHeader("GET", "/roles", "", support, http.StatusForbidden)
+	if entries := testStore.AuditRepository.Entries(); len(entries) == 0 || entries[len(entries)-1].Actor != "user:sam" || entries[len(entries)-1].Resource != "GET /v1/roles" {
+		t.Errorf("expected the refused request of sam to be audited but got %+v", entries)
+	}
+	c.do("DELETE", "/roles/auditor", "", http.StatusOK)
+	c.doWithHeader("GET", "/transactions", "", auditor, http.StatusForbidden)
+
+	c.do("POST", "/transfer", fmt.Sprintf(transfer, "0"), http.StatusUnprocessableEntity)
+	c.do("POST", "/transfer", `{"source_account_id": "`+sourceID+`", "target_account_id": "`+targetID+`", "amount": 1, "source_currency": "GBP"}`, http.StatusUnprocessableEntity)
+	c.do("POST", "/transfer", `{"source_account_id": "`+targetID+`", "target_account_id": "`+sourceID+`", "amount": 1, "currency": "USD"}`, http.StatusUnprocessableEntity)
+
+	// Frozen accounts neither send nor receive money until they are
+	// unfrozen, and a transfer can be reversed once.
+	firstID := field(first, "transaction", "transfer", "id")
+	c.doWithHeader("POST", "/accounts/"+targetID+"/freeze", "", support, http.StatusForbidden)
+	if frozen := c.doWithHeader("POST", "/accounts/"+targetID+"/freeze", "", checker, http.StatusOK); frozen["account"].(map[string]any)["frozen"] != true {
+		t.Errorf("expected the account to be frozen but got %v", frozen)
+	}
+	if refused := c.do("POST", "/transfer", `{"source_account_id": "`+targetID+`", "target_account_id": "`+sourceID+`", "amount": 1}`, http.StatusUnprocessableEntity); field(refused, "code") != "account_frozen" {
+		t.Errorf("expected a transfer from a frozen account to be refused with account_frozen but got %v", refused)
+	}
+	c.do("POST", "/transactions/"+firstID+"/reverse", "", http.StatusUnprocessableEntity)
+	c.do("POST", "/accounts/121f03cd-ce8c-447d-8747-fb8cb7aa3a52/freeze", "", http.StatusNotFound)
+	if unfrozen := c.doWithHeader("POST", "/accounts/"+targetID+"/unfreeze", "", checker, http.StatusOK); unfrozen["account"].(map[string]any)["frozen"] != false {
+		t.Errorf("expected the account to be unfrozen but got %v", unfrozen)
+	}
+	c.doWithHeader("POST", "/transactions/"+firstID+"/reverse", "", support, http.StatusForbidden)
+	reversal := c.doWithHeader("POST", "/transactions/"+firstID+"/reverse", "", checker, http.StatusCreated)
+	if field(reversal, "transaction", "transfer", "source_account_id") != targetID || field(reversal, "transaction", "transfer", "amount", "amount") != "10.00" {
+		t.Errorf("expected the transfer to be sent back but got %v", reversal)
+	}
+	c.doWithHeader("POST", "/transactions/"+firstID+"/reverse", "", checker, http.StatusConflict)
+	c.do("POST", "/transactions/121f03cd-ce8c-447d-8747-fb8cb7aa3a52/reverse", "", http.StatusNotFound)
+	c.do("POST", "/transactions/not-a-uuid/reverse", "", http.StatusBadRequest)
+
+	// Currencies the policy has no threshold for need approval whatever the
+	// amount.
+	unlisted := c.do("POST", "/transfer", `{"source_account_id": "`+sourceID+`", "target_account_id": "`+targetID+`", "amount": 1, "source_currency": "USD"}`, http.StatusAccepted)
+	c.do("POST", "/approvals/"+field(unlisted, "approval", "id")+"/reject", "", http.StatusOK)
+
+	// Transfers above the threshold wait for approval too, and fail when
+	// they are approved if they can no longer be made.
+	held := c.do("POST", "/transfer", fmt.Sprintf(transfer, "600"), http.StatusAccepted)
+	c.doWithHeader("POST", "/approvals/"+field(held, "approval", "id")+"/reject", `{"reason": "not today"}`, checker, http.StatusOK)
+	held = c.do("POST", "/transfer", fmt.Sprintf(transfer, "600"), http.StatusAccepted)
+	c.do("POST", "/transfer", fmt.Sprintf(transfer, "300"), http.StatusCreated)
+	c.doWithHeader("POST", "/approvals/"+field(held, "approval", "id")+"/approve", "", checker, http.StatusUnprocessableEntity)
+	if failed := c.do("GET", "/approvals/"+field(held, "approval", "id"), "", http.StatusOK); field(failed, "approval", "status") != "failed" {
+		t.Errorf("expected the approval to have failed but got %v", failed)
+	}
+	c.do("DELETE", "/customers/"+customerID+"/beneficiaries/"+targetID, "", http.StatusOK)
+	c.do("GET", "/transactions", "", http.StatusOK)
+	c.do("GET", "/transactions?timestamps=human", "", http.StatusOK)
+	c.do("GET", "/customers/"+customerID+"?timestamps=human", "", http.StatusOK)
+	c.do("GET", "/accounts/"+sourceID+"?timestamps=rfc3339", "", http.StatusOK)
+
+	c.do("GET", "/accounts/"+sourceID+"/statements?from=2020-01-01&to=2100-01-01", "", http.StatusOK)
+	c.do("GET", "/accounts/"+sourceID+"/statements?format=csv", "", http.StatusOK)
+	c.do("GET", "/accounts/"+sourceID+"/statements?format=text&currency=USD", "", http.StatusOK)
+	c.do("GET", "/accounts/"+sourceID+"/statements?format=xml", "", http.StatusBadRequest)
+	c.do("GET", "/accounts/"+sourceID+"/balance", "", http.StatusOK)
+	c.do("GET", "/accounts/not-a-uuid", "", http.StatusBadRequest)
+	c.do("GET", "/customers/not-a-uuid/beneficiaries", "", http.StatusBadRequest)
+	c.do("GET", "/accounts/not-a-uuid/pockets", "", http.StatusBadRequest)
+	c.do("GET", "/accounts/"+sourceID+"/balance?currency=GBP", "", http.StatusUnprocessableEntity)
+	c.do("GET", "/balances?as_of=2100-01-01T00:00:00Z", "", http.StatusOK)
+
+	c.do("GET", "/accounts/"+sourceID+"/interest", "", http.StatusNotFound)
+	c.do("PUT", "/accounts/"+sourceID+"/interest", `{"annual_rate": 0.025, "compounding": "daily"}`, http.StatusOK)
+	c.do("GET", "/accounts/"+sourceID+"/interest", "", http.StatusOK)
+	c.do("POST", "/interest/run?date=2100-01-01", "", http.StatusOK)
+	c.do("GET", "/accounts/"+sourceID+"/interest/accruals", "", http.StatusOK)
+
+	c.do("GET", "/customers/"+customerID+"/accounts", "", http.StatusOK)
+	c.do("DELETE", "/customers/"+customerID, "", http.StatusConflict)
+	closure := c.doWithIfMatch("DELETE", "/accounts/"+sourceID, "", "*", http.StatusAccepted)
+	c.doWithHeader("POST", "/approvals/"+field(closure, "approval", "id")+"/approve", "", support, http.StatusForbidden)
+	c.doWithHeader("POST", "/approvals/"+field(closure, "approval", "id")+"/approve", "", backOffice, http.StatusOK)
+	c.do("DELETE", "/customers/"+customerID, "", http.StatusOK)
+
+	// Every change is in the audit log, once, and the log is chained so that
+	// changing an entry is detected.
+	created := c.do("GET", "/audit?action=account.created&resource=accounts/"+sourceID, "", http.StatusOK)
+	if entries, _ := created["entries"].([]any); len(entries) != 1 || field(entries[0].(map[string]any), "actor") != "key:test" {
+		t.Errorf("expected the account to be recorded as created by key:test but got %v", created["entries"])
+	}
+	transferred := c.do("GET", "/audit?action=transfer.created&resource=transfers/"+firstID, "", http.StatusOK)
+	if entries, _ := transferred["entries"].([]any); len(entries) != 1 {
+		t.Errorf("expected the transfer retried with the same idempotency key to be recorded once but got %v", transferred["entries"])
+	}
+	reversals := c.do("GET", "/audit?action=transfer.reversed&resource=transfers/"+firstID, "", http.StatusOK)
+	if entries, _ := reversals["entries"].([]any); len(entries) != 1 || field(entries[0].(map[string]any), "actor") != "user:fiona" {
+		t.Errorf("expected the reversal to be recorded as made by fiona but got %v", reversals["entries"])
+	}
+	c.do("GET", "/audit?actor=user:fiona&page_size=1", "", http.StatusOK)
+	c.do("GET", "/audit?from=2100-01-01T00:00:00Z&to=2000-01-01T00:00:00Z", "", http.StatusBadRequest)
+	c.do("GET", "/audit?from=yesterday", "", http.StatusBadRequest)
+	c.doWithHeader("GET", "/audit", "", support, http.StatusForbidden)
+	if verified := c.do("GET", "/audit/verify", "", http.StatusOK); verified["verification"].(map[string]any)["broken_at"] != nil {
+		t.Errorf("expected the audit log to be intact but got %v", verified)
+	}
+	testStore.AuditRepository.Tamper(2, func(e *domain.AuditEntry) { e.Actor = "key:someone-else" })
+	if verified := c.do("GET", "/audit/verify", "", http.StatusOK); verified["verification"].(map[string]any)["broken_at"] != float64(2) {
+		t.Errorf("expected the changed entry to break the audit log but got %v", verified)
+	}
+
+	// Requests whose change cannot be recorded fail.
+	testStore.AuditRepository.FailWith(errors.New("disk full"))
+	c.do("POST", "/customers", `{"name": "Max Mustermann", "email": "max@example.com", "type": "individual"}`, http.StatusInternalServerError)
+	testStore.AuditRepository.FailWith(nil)
+
+	for path, item := range c.doc.Paths {
+		for method := range item {
+			if method != "parameters" && !c.exercised[method+" "+path] {
+				t.Errorf("%s %s is not exercised by the contract test", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+// signedBy authenticates a request to the /v1 path with key and signs it with
+// secret, as a client holding a signing secret would.
+func signedBy(key, secret, method, path, body string, at time.Time, nonce string) http.Header {
+	signature := domain.Signature(secret, method, handlers.V1.Prefix()+path, at.Unix(), nonce, []byte(body))
+	return http.Header{
+		"X-Api-Key":                     {key},
+		domain.SignatureHeader:          {"v1=" + signature},
+		domain.SignatureTimestampHeader: {strconv.FormatInt(at.Unix(), 10)},
+		domain.SignatureNonceHeader:     {nonce},
+	}
+}
+
+func TestRateLimits(t *testing.T) {
+	useMemoryStore()
+	defer func(api, transfer domain.RouteLimit) { apiLimit, transferLimit = api, transfer }(apiLimit, transferLimit)
+	apiLimit.Client = domain.RateLimit{Limit: 100, Period: time.Hour}
+	transferLimit.Client = domain.RateLimit{Limit: 5, Period: time.Hour}
+	transferLimit.Account = domain.RateLimit{Limit: 2, Period: time.Hour}
+	c := newContract(t)
+
+	source := c.do("POST", "/accounts", `{"balance": 100, "currency": "EUR"}`, http.StatusCreated)
+	other := c.do("POST", "/accounts", `{"balance": 100, "currency": "EUR"}`, http.StatusCreated)
+	target := c.do("POST", "/accounts", `{"balance": 0, "currency": "EUR"}`, http.StatusCreated)
+	transfer := `{"source_account_id": "%s", "target_account_id": "` + field(target, "account", "id") + `", "amount": 1}`
+	from := func(account map[string]any) string { return fmt.Sprintf(transfer, field(account, "account", "id")) }
+
+	// Each source account may send two transfers an hour...
+	c.do("POST", "/transfer", from(source), http.StatusCreated)
+	c.do("POST", "/transfer", from(source), http.StatusCreated)
+	req, _ := http.NewRequest("POST", c.server.URL+handlers.V1.Prefix()+"/transfer", strings.NewReader(from(source)))
+	req.Header.Set("X-API-Key", adminKey)
+	resp, err := c.server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected the third transfer from the account to be refused but got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") != "1800" || resp.Header.Get("RateLimit-Remaining") != "0" || resp.Header.Get("RateLimit-Policy") != "2;w=3600" {
+		t.Errorf("unexpected rate limit headers %v", resp.Header)
+	}
+
+	// ...and each caller five, whatever the account.
+	c.do("POST", "/transfer", from(other), http.StatusCreated)
+	c.do("POST", "/transfer", from(other), http.StatusCreated)
+	refused := c.doWithHeader("POST", "/transfer", from(target), nil, http.StatusTooManyRequests)
+	if field(refused, "code") != "rate_limited" {
+		t.Errorf("expected rate_limited but got %v", refused)
+	}
+
+	// Other routes only count towards the api limit of the caller.
+	req, _ = http.NewRequest("GET", c.server.URL+handlers.V1.Prefix()+"/accounts", nil)
+	req.Header.Set("X-API-Key", adminKey)
+	resp, err = c.server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("RateLimit-Limit") != "100" || resp.Header.Get("RateLimit-Remaining") != "90" {
+		t.Errorf("expected the api limit to have 90 requests left but got %d %v", resp.StatusCode, resp.Header)
+	}
+
+	// Buckets left idle are forgotten, which leaves them full.
+	if err := rateLimitService.PurgeBuckets(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	c.do("POST", "/transfer", from(source), http.StatusCreated)
+}
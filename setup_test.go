@@ -23,16 +23,71 @@ func TestRoutes(t *testing.T) {
 		{"/accounts/{id}", "GET"},
 		{"/accounts/{id}", "PATCH"},
 		{"/accounts/{id}", "DELETE"},
+		{"/accounts/{id}/freeze", "POST"},
+		{"/accounts/{id}/unfreeze", "POST"},
+		{"/accounts/{id}/interest", "GET"},
+		{"/accounts/{id}/interest", "PUT"},
+		{"/accounts/{id}/interest/accruals", "GET"},
+		{"/accounts/{id}/statements", "GET"},
+		{"/accounts/{id}/balance", "GET"},
+		{"/accounts/{id}/pockets", "GET"},
+		{"/accounts/{id}/pockets", "POST"},
+		{"/accounts/{id}/convert", "POST"},
+		{"/customers/", "GET"},
+		{"/customers/", "POST"},
+		{"/customers/{id}", "GET"},
+		{"/customers/{id}", "PATCH"},
+		{"/customers/{id}", "DELETE"},
+		{"/customers/{id}/accounts", "GET"},
+		{"/customers/{id}/beneficiaries", "GET"},
+		{"/customers/{id}/beneficiaries", "POST"},
+		{"/customers/{id}/beneficiaries/{accountID}", "DELETE"},
 		{"/transfer", "POST"},
 		{"/transactions", "GET"},
+		{"/transactions/{id}/reverse", "POST"},
+		{"/balances", "GET"},
+		{"/interest/run", "POST"},
+		{"/api-keys/", "GET"},
+		{"/api-keys/", "POST"},
+		{"/api-keys/{id}", "GET"},
+		{"/api-keys/{id}", "DELETE"},
+		{"/api-keys/{id}/rotate", "POST"},
+		{"/signing-secrets/", "GET"},
+		{"/signing-secrets/", "POST"},
+		{"/signing-secrets/{id}", "GET"},
+		{"/signing-secrets/{id}", "DELETE"},
+		{"/signing-secrets/{id}/rotate", "POST"},
+		{"/roles/", "GET"},
+		{"/roles/", "POST"},
+		{"/roles/{name}", "GET"},
+		{"/roles/{name}", "PATCH"},
+		{"/roles/{name}", "DELETE"},
+		{"/permissions", "GET"},
+		{"/approvals/", "GET"},
+		{"/approvals/{id}", "GET"},
+		{"/approvals/{id}/approve", "POST"},
+		{"/approvals/{id}/reject", "POST"},
+		{"/audit/", "GET"},
+		{"/audit/verify", "GET"},
 	}
 
 	mux := Routes()
 	chiRoutes := mux.(chi.Routes)
 
-	for _, route := range registered {
-		if !routeExists(route.route, route.method, chiRoutes) {
-			t.Errorf("route %s is not registered", route.route)
+	// Every version serves the same routes, and the unversioned paths are
+	// aliases of /v1.
+	for _, prefix := range []string{"", "/v1", "/v2"} {
+		for _, route := range registered {
+			if !routeExists(prefix+route.route, route.method, chiRoutes) {
+				t.Errorf("route %s is not registered", prefix+route.route)
+			}
+		}
+	}
+	for _, prefix := range []string{"", "/v1"} {
+		for _, route := range []string{"/openapi.json", "/docs"} {
+			if !routeExists(prefix+route, "GET", chiRoutes) {
+				t.Errorf("route %s is not registered", prefix+route)
+			}
 		}
 	}
 }
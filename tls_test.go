@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/petrostrak/agile-transfer/internal/adapters/handlers"
+	"github.com/petrostrak/agile-transfer/internal/core/domain"
+	"github.com/petrostrak/agile-transfer/internal/tlsconfig"
+)
+
+func TestMutualTLS(t *testing.T) {
+	useMemoryStore()
+	clientCertificates = handlers.NewClientCertificates(map[string]string{"CN=partner,O=Acme": "partner"})
+	defer func() { clientCertificates = handlers.NewClientCertificates(nil) }()
+
+	partnerKey, err := apiKeyService.Issue(context.Background(), &domain.APIKey{Client: "partner", Scopes: []domain.Scope{domain.ScopeAccountsRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca, err := tlsconfig.NewCA("test CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCert, _ := ca.Issue(pkix.Name{CommonName: "localhost"}, "127.0.0.1")
+	partnerCert, _ := ca.Issue(pkix.Name{CommonName: "partner", Organization: []string{"Acme"}})
+	otherCert, _ := ca.Issue(pkix.Name{CommonName: "reporting"})
+
+	dir := t.TempDir()
+	options := tlsconfig.Options{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}
+	if err := serverCert.WriteFiles(options.CertFile, options.KeyFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := ca.WriteFiles(options.ClientCAFile, filepath.Join(dir, "ca.key")); err != nil {
+		t.Fatal(err)
+	}
+	reloader, err := tlsconfig.NewReloader(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(Routes())
+	server.TLS = reloader.Config()
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+
+	tests := []struct {
+		name     string
+		cert     *tlsconfig.KeyPair
+		key      string
+		expected int
+	}{
+		{"partner with its certificate", partnerCert, partnerKey, http.StatusOK},
+		{"partner without a certificate", nil, partnerKey, http.StatusUnauthorized},
+		{"partner with another certificate", otherCert, partnerKey, http.StatusUnauthorized},
+		{"other client with the partner's certificate", partnerCert, adminKey, http.StatusUnauthorized},
+		{"other client without a certificate", nil, adminKey, http.StatusOK},
+		{"other client with an unmapped certificate", otherCert, adminKey, http.StatusOK},
+	}
+	for _, tt := range tests {
+		config := &tls.Config{RootCAs: roots}
+		if tt.cert != nil {
+			config.Certificates = []tls.Certificate{{Certificate: [][]byte{tt.cert.Cert.Raw}, PrivateKey: tt.cert.Key}}
+		}
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+
+		req, _ := http.NewRequest("GET", server.URL+handlers.V1.Prefix()+"/accounts", nil)
+		req.Header.Set("X-API-Key", tt.key)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		var problem struct {
+			Code string `json:"code"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&problem)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s: expected %d but got %d", tt.name, tt.expected, resp.StatusCode)
+		}
+		if tt.expected == http.StatusUnauthorized && problem.Code != "certificate_mismatch" {
+			t.Errorf("%s: expected certificate_mismatch but got %q", tt.name, problem.Code)
+		}
+	}
+}
@@ -4,14 +4,45 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
+	"github.com/petrostrak/agile-transfer/internal/core/currency"
 	"github.com/shopspring/decimal"
 )
 
-func CurrencyConvertion(from, to string, amount decimal.Decimal) (decimal.Decimal, error) {
-	resp, err := http.Get(fmt.Sprintf("https://api.freecurrencyapi.com/v1/latest?apikey=QAVbfQcb3HY3YDFtDWdIm7yXzGUMymbipsxXYOj6&currencies=%s&base_currency=%s", to, from))
+// CurrencyConverter converts amounts at the latest rates of an exchange rate
+// service that answers like freecurrencyapi.com.
+type CurrencyConverter struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func NewCurrencyConverter(url, apiKey string, timeout time.Duration) *CurrencyConverter {
+	return &CurrencyConverter{
+		url,
+		apiKey,
+		&http.Client{Timeout: timeout},
+	}
+}
+
+// Convert returns amount, in from, converted to to and rounded to the minor
+// unit of to.
+func (c *CurrencyConverter) Convert(from, to string, amount decimal.Decimal) (decimal.Decimal, error) {
+	query := url.Values{
+		"apikey":        {c.apiKey},
+		"currencies":    {to},
+		"base_currency": {from},
+	}
+	resp, err := c.client.Get(c.url + "?" + query.Encode())
 	if err != nil {
-		return decimal.Decimal{}, nil
+		return decimal.Decimal{}, fmt.Errorf("%w: %v", ErrCurrencyConvertion, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return decimal.Decimal{}, fmt.Errorf("%w: rate service answered %s", ErrCurrencyConvertion, resp.Status)
 	}
 
 	var convert struct {
@@ -20,7 +51,7 @@ func CurrencyConvertion(from, to string, amount decimal.Decimal) (decimal.Decima
 
 	err = json.NewDecoder(resp.Body).Decode(&convert)
 	if err != nil {
-		return decimal.Decimal{}, err
+		return decimal.Decimal{}, fmt.Errorf("%w: %v", ErrCurrencyConvertion, err)
 	}
 
 	unit, ok := convert.Data[to]
@@ -28,10 +59,5 @@ func CurrencyConvertion(from, to string, amount decimal.Decimal) (decimal.Decima
 		return decimal.Decimal{}, ErrCurrencyConvertion
 	}
 
-	multiplier := decimal.NewFromFloat(unit)
-	if err != nil {
-		return decimal.Decimal{}, nil
-	}
-
-	return amount.Mul(multiplier).RoundBank(2), nil
+	return currency.Round(to, amount.Mul(decimal.NewFromFloat(unit)))
 }
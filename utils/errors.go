@@ -2,9 +2,12 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+
+	"github.com/go-chi/chi/middleware"
 )
 
 var (
@@ -15,6 +18,46 @@ var (
 	ErrEmptyBody           = errors.New("body must not be empty")
 	ErrBadJSON             = errors.New("body contains badly-formed JSON")
 	ErrSingleJSON          = errors.New("body must only contain a single JSON value")
+	ErrBodyTooLarge        = errors.New("body is too large")
+	ErrInvalidInterest     = errors.New("invalid interest configuration")
+	ErrNoExpenseAccount    = errors.New("no interest expense account configured for currency")
+	ErrInvalidDateParam    = errors.New("invalid date parameter")
+	ErrInvalidTimeParam    = errors.New("invalid timestamp parameter, expected RFC 3339")
+	ErrInvalidPeriod       = errors.New("period must end after it starts")
+	ErrInvalidFormat       = errors.New("format must be one of json, csv or text")
+	ErrInvalidCustomer     = errors.New("customer needs a name, an email, a type of individual or business and a valid status")
+	ErrCustomerHasAccounts = errors.New("customer still holds accounts")
+	ErrCustomerNotActive   = errors.New("customer of the source account is not active")
+	ErrAccountFrozen       = errors.New("source or target account is frozen")
+	ErrTargetNotAllowed    = errors.New("target account is not an allowed beneficiary")
+	ErrPocketNotFound      = errors.New("account has no pocket in that currency")
+	ErrSameCurrency        = errors.New("cannot convert a currency into itself")
+	ErrInvalidAmount       = errors.New("amount must be positive")
+	ErrInvalidIdempotency  = errors.New("idempotency key must be between 1 and 255 characters")
+	ErrIdempotencyInUse    = errors.New("a request with this idempotency key is still being processed")
+	ErrIdempotencyReused   = errors.New("idempotency key was already used for a different request")
+	ErrInvalidTimestamps   = errors.New("timestamps must be rfc3339 or human")
+	ErrVersionSunset       = errors.New("this version of the API is no longer available")
+	ErrPreconditionMissing = errors.New("request must carry an If-Match header with the ETag of the resource")
+	ErrPreconditionFailed  = errors.New("resource does not match the If-Match header")
+	ErrMissingAPIKey       = errors.New("request must carry an API key in the X-API-Key header")
+	ErrMissingCredentials  = errors.New("request must carry an API key in the X-API-Key header or a bearer token")
+	ErrInvalidAPIKey       = errors.New("API key is unknown, revoked or expired")
+	ErrInsufficientScope   = errors.New("credentials do not grant the scope this request needs")
+	ErrAPIKeyInactive      = errors.New("API key is revoked or expired")
+	ErrInvalidToken        = errors.New("bearer token is invalid")
+	ErrAccountNotOwned     = errors.New("account belongs to another customer")
+	ErrPermissionDenied    = errors.New("your roles do not grant the permission this request needs")
+	ErrApprovalRequired    = errors.New("this action needs a second person's approval, which can only be asked for over the REST API")
+	ErrSelfApproval        = errors.New("an action cannot be approved by who asked for it")
+	ErrApprovalNotPending  = errors.New("approval was already decided or has expired")
+	ErrRateLimited         = errors.New("too many requests, retry after the number of seconds in the Retry-After header")
+	ErrSignatureRequired   = errors.New("this client must sign its requests")
+	ErrInvalidSignature    = errors.New("request signature is missing, malformed or does not match")
+	ErrStaleSignature      = errors.New("request signature timestamp is too far from the current time")
+	ErrReplayedSignature   = errors.New("request signature nonce was already used")
+	ErrSigningInactive     = errors.New("signing secret is revoked or expired")
+	ErrCertificateMismatch = errors.New("the client certificate of the connection is not one of the API client's")
 )
 
 func LogError(err error) {
@@ -22,10 +65,37 @@ func LogError(err error) {
 	logger.Println(err)
 }
 
-func ErrorResponse(w http.ResponseWriter, r *http.Request, status int, message any) {
-	env := Envelope{"error": message}
+// ProblemTypeBase is where the error codes are documented. The type of every
+// problem is this URL followed by its code.
+const ProblemTypeBase = "https://github.com/petrostrak/agile-transfer/blob/main/docs/errors.md#"
+
+// Problem is an RFC 7807 problem details body. Code is a stable,
+// machine-readable name for the problem that clients can branch on.
+type Problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Code     string              `json:"code"`
+	Errors   map[string][]string `json:"errors,omitempty"`
+}
+
+// ErrorResponse sends an application/problem+json response. The instance is
+// the id of the request, so that a problem can be traced in the logs.
+func ErrorResponse(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	writeProblem(w, r, Problem{Status: status, Code: code, Detail: detail})
+}
 
-	err := WriteJSON(w, status, env, nil)
+func writeProblem(w http.ResponseWriter, r *http.Request, problem Problem) {
+	problem.Type = ProblemTypeBase + problem.Code
+	problem.Title = http.StatusText(problem.Status)
+	problem.Instance = middleware.GetReqID(r.Context())
+
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/problem+json")
+
+	err := WriteJSON(w, problem.Status, problem, headers)
 	if err != nil {
 		LogError(err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -35,14 +105,26 @@ func ErrorResponse(w http.ResponseWriter, r *http.Request, status int, message a
 func ServerErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
 	LogError(err)
 	message := "the server encountered a problem and could not process your request"
-	ErrorResponse(w, r, http.StatusInternalServerError, message)
+	ErrorResponse(w, r, http.StatusInternalServerError, "internal_error", message)
 }
 
 func NotFoundResponse(w http.ResponseWriter, r *http.Request) {
 	message := "the requested resource could not be found"
-	ErrorResponse(w, r, http.StatusMethodNotAllowed, message)
+	ErrorResponse(w, r, http.StatusNotFound, "not_found", message)
+}
+
+func MethodNotAllowedResponse(w http.ResponseWriter, r *http.Request) {
+	message := fmt.Sprintf("the %s method is not supported for this resource", r.Method)
+	ErrorResponse(w, r, http.StatusMethodNotAllowed, "method_not_allowed", message)
 }
 
-func BadRequestResponse(w http.ResponseWriter, r *http.Request, err error) {
-	ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+// FailedValidationResponse sends the problems found in the request, keyed by
+// field.
+func FailedValidationResponse(w http.ResponseWriter, r *http.Request, errors map[string][]string) {
+	writeProblem(w, r, Problem{
+		Status: http.StatusUnprocessableEntity,
+		Code:   "validation_failed",
+		Detail: "the request has invalid fields",
+		Errors: errors,
+	})
 }
@@ -1,11 +1,18 @@
 package utils
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/go-chi/chi/middleware"
 )
 
 func Test_LogError(t *testing.T) {
@@ -24,3 +31,42 @@ func Test_LogError(t *testing.T) {
 		t.Errorf("incorrect log error: expected %s but got %s", expected, string(result))
 	}
 }
+
+func Test_ErrorResponse(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/accounts/1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.RequestIDKey, "host/abc-000001"))
+	rr := httptest.NewRecorder()
+
+	ErrorResponse(rr, req, http.StatusUnprocessableEntity, "insufficient_balance", ErrInsufficientBalance.Error())
+
+	var problem Problem
+	if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Problem{
+		Type:     ProblemTypeBase + "insufficient_balance",
+		Title:    "Unprocessable Entity",
+		Status:   http.StatusUnprocessableEntity,
+		Detail:   "insufficient balance",
+		Instance: "host/abc-000001",
+		Code:     "insufficient_balance",
+	}
+	if !reflect.DeepEqual(problem, expected) {
+		t.Errorf("expected %+v but got %+v", expected, problem)
+	}
+	if rr.Code != http.StatusUnprocessableEntity || rr.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("wrong status %d or content type %q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+}
+
+func Test_NotFoundResponse(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/", nil)
+	rr := httptest.NewRecorder()
+
+	NotFoundResponse(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected %d but got %d", http.StatusNotFound, rr.Code)
+	}
+}
@@ -3,8 +3,10 @@ package utils
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -13,9 +15,13 @@ import (
 
 type Envelope map[string]any
 
-func ReadIDParam(r *http.Request) uuid.UUID {
-	id := chi.URLParamFromCtx(r.Context(), "id")
-	return uuid.MustParse(id)
+// ReadUUIDParam reads a named URL parameter that must hold a UUID.
+func ReadUUIDParam(r *http.Request, key string) (uuid.UUID, error) {
+	id, err := uuid.Parse(chi.URLParamFromCtx(r.Context(), key))
+	if err != nil {
+		return uuid.UUID{}, ErrInvalidIDParam
+	}
+	return id, nil
 }
 
 func WriteJSON(w http.ResponseWriter, status int, data any, headers ...http.Header) error {
@@ -30,7 +36,9 @@ func WriteJSON(w http.ResponseWriter, status int, data any, headers ...http.Head
 		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
 	w.WriteHeader(status)
 	_, err = w.Write(out)
 	if err != nil {
@@ -40,25 +48,98 @@ func WriteJSON(w http.ResponseWriter, status int, data any, headers ...http.Head
 	return nil
 }
 
+// ReadJSON decodes a single JSON value from the request body into data. It
+// rejects unknown fields and bodies over one megabyte, and wraps ErrBadJSON,
+// ErrEmptyBody, ErrSingleJSON or ErrBodyTooLarge with the details of what is
+// wrong, so that they can be shown to the client.
 func ReadJSON(w http.ResponseWriter, r *http.Request, data any) error {
 	maxBytes := 1048576 // one megabyte
 
 	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
 
 	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
 	err := dec.Decode(data)
 	if err != nil {
-		return err
+		var syntaxError *json.SyntaxError
+		var unmarshalTypeError *json.UnmarshalTypeError
+		var maxBytesError *http.MaxBytesError
+		var invalidUnmarshalError *json.InvalidUnmarshalError
+
+		switch {
+		case errors.As(err, &syntaxError):
+			return fmt.Errorf("%w (at character %d)", ErrBadJSON, syntaxError.Offset)
+
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return ErrBadJSON
+
+		case errors.As(err, &unmarshalTypeError):
+			if unmarshalTypeError.Field != "" {
+				return fmt.Errorf("%w: incorrect JSON type for field %q", ErrBadJSON, unmarshalTypeError.Field)
+			}
+			return fmt.Errorf("%w: incorrect JSON type (at character %d)", ErrBadJSON, unmarshalTypeError.Offset)
+
+		case errors.Is(err, io.EOF):
+			return ErrEmptyBody
+
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
+			return fmt.Errorf("%w: unknown field %s", ErrBadJSON, fieldName)
+
+		case errors.As(err, &maxBytesError):
+			return fmt.Errorf("%w: must not be larger than %d bytes", ErrBodyTooLarge, maxBytesError.Limit)
+
+		case errors.As(err, &invalidUnmarshalError):
+			panic(err)
+
+		default:
+			// Values with their own decoder, such as decimals and money,
+			// report their own errors.
+			return fmt.Errorf("%w: %v", ErrBadJSON, err)
+		}
 	}
 
 	err = dec.Decode(&struct{}{})
 	if err != io.EOF {
-		return errors.New("body must have only a single json value")
+		return ErrSingleJSON
 	}
 
 	return nil
 }
 
+// ReadDateQuery parses the query parameter key as a YYYY-MM-DD date, falling
+// back to the given value when the parameter is absent.
+func ReadDateQuery(r *http.Request, key string, fallback time.Time) (time.Time, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, ErrInvalidDateParam
+	}
+
+	return date, nil
+}
+
+// ReadTimeQuery parses the query parameter key as an RFC 3339 timestamp,
+// falling back to the given value when the parameter is absent.
+func ReadTimeQuery(r *http.Request, key string, fallback time.Time) (time.Time, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, ErrInvalidTimeParam
+	}
+
+	return t, nil
+}
+
 func HumanDate(t time.Time) string {
 	return t.Format("02 Jan 2006 at 15:04")
 }
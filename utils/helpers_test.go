@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,14 +15,16 @@ import (
 	"github.com/google/uuid"
 )
 
-func Test_ReadIDParams(t *testing.T) {
+func Test_ReadUUIDParam(t *testing.T) {
 	testCases := []struct {
 		ID       string
 		expected uuid.UUID
+		err      error
 	}{
-		{"121f03cd-ce8c-447d-8747-fb8cb7aa3a52", uuid.MustParse("121f03cd-ce8c-447d-8747-fb8cb7aa3a52")},
-		{"2f0141f8-f325-4b15-9973-e7b34852e298", uuid.MustParse("2f0141f8-f325-4b15-9973-e7b34852e298")},
-		{"3659fbd7-7ba2-4151-95a2-b977ebf79307", uuid.MustParse("3659fbd7-7ba2-4151-95a2-b977ebf79307")},
+		{"121f03cd-ce8c-447d-8747-fb8cb7aa3a52", uuid.MustParse("121f03cd-ce8c-447d-8747-fb8cb7aa3a52"), nil},
+		{"2f0141f8-f325-4b15-9973-e7b34852e298", uuid.MustParse("2f0141f8-f325-4b15-9973-e7b34852e298"), nil},
+		{"not-a-uuid", uuid.UUID{}, ErrInvalidIDParam},
+		{"", uuid.UUID{}, ErrInvalidIDParam},
 	}
 
 	for _, tt := range testCases {
@@ -34,10 +38,10 @@ func Test_ReadIDParams(t *testing.T) {
 		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
 		handler.ServeHTTP(rr, req)
 
-		result := ReadIDParam(req)
+		result, err := ReadUUIDParam(req, "id")
 
-		if result != tt.expected {
-			t.Errorf("Expected %v but got %v", tt.expected, result)
+		if result != tt.expected || err != tt.err {
+			t.Errorf("Expected %v (%v) but got %v (%v)", tt.expected, tt.err, result, err)
 		}
 	}
 }
@@ -115,3 +119,92 @@ func Test_ReadJSON(t *testing.T) {
 		t.Error("did not get an error with bad json")
 	}
 }
+
+func Test_ReadJSONErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		expected error
+	}{
+		{"syntax", `{"foo": "bar",}`, ErrBadJSON},
+		{"truncated", `{"foo": "bar"`, ErrBadJSON},
+		{"type", `{"foo": 1}`, ErrBadJSON},
+		{"unknown field", `{"foo": "bar", "baz": 1}`, ErrBadJSON},
+		{"empty", ``, ErrEmptyBody},
+		{"multiple values", `{"foo": "bar"} {"foo": "baz"}`, ErrSingleJSON},
+		{"too large", `{"foo": "` + strings.Repeat("a", 1048576) + `"}`, ErrBodyTooLarge},
+	}
+
+	for _, tt := range testCases {
+		var decodedJSON struct {
+			Foo string `json:"foo"`
+		}
+
+		req, _ := http.NewRequest("POST", "/", strings.NewReader(tt.body))
+		err := ReadJSON(httptest.NewRecorder(), req, &decodedJSON)
+		if !errors.Is(err, tt.expected) {
+			t.Errorf("%s: expected %v but got %v", tt.name, tt.expected, err)
+		}
+	}
+}
+
+func Test_ReadDateQuery(t *testing.T) {
+	fallback := time.Date(2023, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		query       string
+		expected    time.Time
+		expectError bool
+	}{
+		{"", fallback, false},
+		{"?date=2023-03-31", time.Date(2023, time.March, 31, 0, 0, 0, 0, time.UTC), false},
+		{"?date=31-03-2023", time.Time{}, true},
+	}
+
+	for _, tt := range testCases {
+		req, _ := http.NewRequest("GET", "/"+tt.query, nil)
+
+		result, err := ReadDateQuery(req, "date", fallback)
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("%q: expected an error but got none", tt.query)
+			}
+			continue
+		}
+
+		if err != nil || !result.Equal(tt.expected) {
+			t.Errorf("%q: expected %v but got %v (%v)", tt.query, tt.expected, result, err)
+		}
+	}
+}
+
+func Test_ReadTimeQuery(t *testing.T) {
+	fallback := time.Date(2023, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		query       string
+		expected    time.Time
+		expectError bool
+	}{
+		{"", fallback, false},
+		{"?as_of=2023-03-31T23:59:00Z", time.Date(2023, time.March, 31, 23, 59, 0, 0, time.UTC), false},
+		{"?as_of=2023-04-01T02:59:00%2B03:00", time.Date(2023, time.March, 31, 23, 59, 0, 0, time.UTC), false},
+		{"?as_of=2023-03-31", time.Time{}, true},
+	}
+
+	for _, tt := range testCases {
+		req, _ := http.NewRequest("GET", "/"+tt.query, nil)
+
+		result, err := ReadTimeQuery(req, "as_of", fallback)
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("%q: expected an error but got none", tt.query)
+			}
+			continue
+		}
+
+		if err != nil || !result.Equal(tt.expected) {
+			t.Errorf("%q: expected %v but got %v (%v)", tt.query, tt.expected, result, err)
+		}
+	}
+}
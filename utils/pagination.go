@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/petrostrak/agile-transfer/internal/validator"
+)
+
+// MaxPageSize is the largest page a list endpoint returns, and the size of
+// the page it returns when none is asked for.
+const MaxPageSize = 100
+
+// Page selects one page of a list, counting pages from 1.
+type Page struct {
+	Number int
+	Size   int
+}
+
+// Metadata describes the page of a list that was returned. It is empty when
+// the list is.
+type Metadata struct {
+	CurrentPage  int `json:"current_page,omitempty"`
+	PageSize     int `json:"page_size,omitempty"`
+	FirstPage    int `json:"first_page,omitempty"`
+	LastPage     int `json:"last_page,omitempty"`
+	TotalRecords int `json:"total_records,omitempty"`
+}
+
+// ReadPage reads the page and page_size query parameters, recording in v
+// whatever is wrong with them.
+func ReadPage(r *http.Request, v *validator.Validator) Page {
+	page := Page{
+		Number: readIntQuery(r, "page", 1, v),
+		Size:   readIntQuery(r, "page_size", MaxPageSize, v),
+	}
+
+	v.Check(page.Number > 0, "page", "must be greater than zero")
+	v.Check(page.Number <= 10_000_000, "page", "must be a maximum of 10 million")
+	v.Check(page.Size > 0, "page_size", "must be greater than zero")
+	v.Check(page.Size <= MaxPageSize, "page_size", "must be a maximum of 100")
+
+	return page
+}
+
+func readIntQuery(r *http.Request, key string, fallback int, v *validator.Validator) int {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return fallback
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		v.AddError(key, "must be an integer value")
+		return fallback
+	}
+
+	return i
+}
+
+// Paginate returns the items on the page, along with the metadata that
+// describes it.
+func Paginate[T any](items []T, page Page) ([]T, Metadata) {
+	if len(items) == 0 {
+		return items, Metadata{}
+	}
+
+	metadata := Metadata{
+		CurrentPage:  page.Number,
+		PageSize:     page.Size,
+		FirstPage:    1,
+		LastPage:     int(math.Ceil(float64(len(items)) / float64(page.Size))),
+		TotalRecords: len(items),
+	}
+
+	start := (page.Number - 1) * page.Size
+	if start >= len(items) {
+		return items[:0], metadata
+	}
+	end := start + page.Size
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return items[start:end], metadata
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/petrostrak/agile-transfer/internal/validator"
+)
+
+func Test_ReadPage(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected Page
+		errors   []string
+	}{
+		{"", Page{1, MaxPageSize}, nil},
+		{"?page=3&page_size=20", Page{3, 20}, nil},
+		{"?page=0&page_size=101", Page{0, 101}, []string{"page", "page_size"}},
+		{"?page=two", Page{1, MaxPageSize}, []string{"page"}},
+	}
+
+	for _, tt := range testCases {
+		req, _ := http.NewRequest("GET", "/accounts"+tt.query, nil)
+		v := validator.New()
+
+		page := ReadPage(req, v)
+		if page != tt.expected {
+			t.Errorf("%q: expected %+v but got %+v", tt.query, tt.expected, page)
+		}
+		for _, key := range tt.errors {
+			if len(v.Errors[key]) == 0 {
+				t.Errorf("%q: expected an error for %s", tt.query, key)
+			}
+		}
+		if len(v.Errors) != len(tt.errors) {
+			t.Errorf("%q: expected %d errors but got %v", tt.query, len(tt.errors), v.Errors)
+		}
+	}
+}
+
+func Test_Paginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	testCases := []struct {
+		page     Page
+		expected []int
+		metadata Metadata
+	}{
+		{Page{1, 2}, []int{1, 2}, Metadata{1, 2, 1, 3, 5}},
+		{Page{3, 2}, []int{5}, Metadata{3, 2, 1, 3, 5}},
+		{Page{4, 2}, []int{}, Metadata{4, 2, 1, 3, 5}},
+		{Page{1, 100}, []int{1, 2, 3, 4, 5}, Metadata{1, 100, 1, 1, 5}},
+	}
+
+	for _, tt := range testCases {
+		result, metadata := Paginate(items, tt.page)
+		if !reflect.DeepEqual(result, tt.expected) || metadata != tt.metadata {
+			t.Errorf("%+v: expected %v %+v but got %v %+v", tt.page, tt.expected, tt.metadata, result, metadata)
+		}
+	}
+
+	if result, metadata := Paginate([]int(nil), Page{1, 10}); result != nil || metadata != (Metadata{}) {
+		t.Errorf("expected no items and empty metadata but got %v %+v", result, metadata)
+	}
+}
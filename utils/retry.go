@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"context"
+	"time"
+)
+
+// maxBackoff caps the wait between two attempts of Retry.
+const maxBackoff = time.Minute
+
+// Retry calls fn until it succeeds, at most attempts times, waiting backoff
+// after the first failure and twice as long after each one after it, up to a
+// minute. Before each wait it passes report the failed attempt, counted from
+// 1, its error and the wait. It returns the error of the last attempt, or
+// that of ctx when ctx is done first.
+func Retry(ctx context.Context, attempts int, backoff time.Duration, fn func() error, report func(attempt int, err error, wait time.Duration)) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || attempt >= attempts {
+			return err
+		}
+
+		report(attempt, err, backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
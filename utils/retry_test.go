@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_Retry(t *testing.T) {
+	failure := errors.New("connection refused")
+
+	var waits []time.Duration
+	calls := 0
+	err := Retry(context.Background(), 4, time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return failure
+		}
+		return nil
+	}, func(attempt int, err error, wait time.Duration) {
+		waits = append(waits, wait)
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("expected the third attempt to succeed but got %d calls (%v)", calls, err)
+	}
+	if len(waits) != 2 || waits[0] != time.Millisecond || waits[1] != 2*time.Millisecond {
+		t.Errorf("expected the wait to double but got %v", waits)
+	}
+
+	calls = 0
+	err = Retry(context.Background(), 3, time.Millisecond, func() error {
+		calls++
+		return failure
+	}, func(int, error, time.Duration) {})
+	if !errors.Is(err, failure) || calls != 3 {
+		t.Errorf("expected the last error after 3 calls but got %d calls (%v)", calls, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls = 0
+	err = Retry(ctx, 3, time.Hour, func() error {
+		calls++
+		return failure
+	}, func(int, error, time.Duration) {})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("expected to stop waiting when the context is done but got %d calls (%v)", calls, err)
+	}
+}
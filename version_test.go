@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serve(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-API-Key", adminKey)
+	rec := httptest.NewRecorder()
+	Routes().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUnversionedAliases(t *testing.T) {
+	useMemoryStore()
+
+	rec := serve(t, "GET", "/accounts", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the alias to be served but got %d", rec.Code)
+	}
+	if rec.Header().Get("Deprecation") != "@1792368000" {
+		t.Errorf("unexpected Deprecation header %q", rec.Header().Get("Deprecation"))
+	}
+	if rec.Header().Get("Sunset") != "Fri, 30 Apr 2027 00:00:00 GMT" {
+		t.Errorf("unexpected Sunset header %q", rec.Header().Get("Sunset"))
+	}
+	if rec.Header().Get("Link") != `</v1/accounts>; rel="successor-version"` {
+		t.Errorf("unexpected Link header %q", rec.Header().Get("Link"))
+	}
+
+	rec = serve(t, "GET", "/v1/accounts", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Deprecation") != "" || rec.Header().Get("Sunset") != "" {
+		t.Errorf("expected /v1 to be served without deprecation but got %d %v", rec.Code, rec.Header())
+	}
+
+	sunset := unversionedSunset
+	unversionedSunset = time.Now().Add(-time.Minute)
+	defer func() { unversionedSunset = sunset }()
+
+	rec = serve(t, "GET", "/accounts", "")
+	if rec.Code != http.StatusGone || !strings.Contains(rec.Body.String(), `"api_version_sunset"`) {
+		t.Errorf("expected the alias to be gone after its sunset but got %d: %s", rec.Code, rec.Body)
+	}
+	if rec = serve(t, "GET", "/v1/accounts", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected /v1 to outlive the aliases but got %d", rec.Code)
+	}
+}
+
+func TestVersionedRepresentations(t *testing.T) {
+	useMemoryStore()
+
+	rec := serve(t, "POST", "/v1/accounts", `{"balance": 100.5, "currency": "EUR"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("could not create account: %d %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		Account struct {
+			ID string `json:"id"`
+		} `json:"account"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if location := rec.Header().Get("Location"); location != "/v1/accounts/"+created.Account.ID {
+		t.Errorf("unexpected Location %q", location)
+	}
+
+	var tests = []struct {
+		path   string
+		accept string
+		human  bool
+	}{
+		{"/v1/accounts/" + created.Account.ID, "", true},
+		{"/v1/accounts/" + created.Account.ID + "?timestamps=rfc3339", "", false},
+		{"/v1/accounts/" + created.Account.ID, "application/json; timestamps=rfc3339", false},
+		{"/v2/accounts/" + created.Account.ID, "", false},
+		{"/v2/accounts/" + created.Account.ID + "?timestamps=human", "", true},
+		{"/v2/accounts/" + created.Account.ID, "text/html, application/json; timestamps=human", true},
+	}
+
+	for _, e := range tests {
+		req := httptest.NewRequest("GET", e.path, nil)
+		req.Header.Set("X-API-Key", adminKey)
+		if e.accept != "" {
+			req.Header.Set("Accept", e.accept)
+		}
+		rec := httptest.NewRecorder()
+		Routes().ServeHTTP(rec, req)
+
+		var account struct {
+			Account struct {
+				Balance   string `json:"balance"`
+				CreatedAt string `json:"created_at"`
+			} `json:"account"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &account)
+
+		layout := time.RFC3339
+		if e.human {
+			layout = "02 Jan 2006 at 15:04"
+		}
+		if _, err := time.Parse(layout, account.Account.CreatedAt); err != nil || account.Account.Balance != "100.50" {
+			t.Errorf("%s %s: unexpected account %s", e.path, e.accept, rec.Body)
+		}
+	}
+
+	if rec = serve(t, "GET", "/v1/accounts?timestamps=unix", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected an unknown timestamps format to be refused but got %d", rec.Code)
+	}
+}